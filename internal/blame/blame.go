@@ -0,0 +1,107 @@
+// Package blame resolves the commit and author that introduced lines of a file in a git repository.
+package blame
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrLineOutOfRange is returned when the requested lines do not exist in the blamed revision of a file.
+var ErrLineOutOfRange = errors.New("line range is outside of the blamed file")
+
+// Info describes the commit that last modified a range of lines.
+type Info struct {
+	CommitSHA   string    `json:"commitSha"`
+	AuthorEmail string    `json:"authorEmail"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type fileBlame struct {
+	result *gogit.BlameResult
+	err    error
+}
+
+// Blamer blames files at the HEAD commit of a repository.
+// Results are cached per file, so a file is blamed at most once.
+type Blamer struct {
+	head  *object.Commit
+	mu    sync.Mutex
+	cache map[string]*fileBlame
+}
+
+// NewBlamer opens the git repository at repoDir and prepares blaming at its HEAD commit.
+func NewBlamer(repoDir string) (*Blamer, error) {
+	repo, err := gogit.PlainOpenWithOptions(repoDir, &gogit.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", repoDir, err)
+	}
+
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	head, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit %s: %w", ref.Hash(), err)
+	}
+
+	return &Blamer{
+		head:  head,
+		cache: map[string]*fileBlame{},
+	}, nil
+}
+
+// BlameLines returns the most recent commit that modified any line between fromLine
+// and toLine (1-based, inclusive) of the file at filePath, relative to the repository root.
+func (b *Blamer) BlameLines(filePath string, fromLine, toLine int) (*Info, error) {
+	result, err := b.blameFile(path.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	if toLine < fromLine {
+		toLine = fromLine
+	}
+	if fromLine < 1 || toLine > len(result.Lines) {
+		return nil, fmt.Errorf("%w: lines %d-%d of %s", ErrLineOutOfRange, fromLine, toLine, filePath)
+	}
+
+	var latest *gogit.Line
+	for _, line := range result.Lines[fromLine-1 : toLine] {
+		if latest == nil || line.Date.After(latest.Date) {
+			latest = line
+		}
+	}
+
+	return &Info{
+		CommitSHA:   latest.Hash.String(),
+		AuthorEmail: latest.Author,
+		Timestamp:   latest.Date.UTC(),
+	}, nil
+}
+
+func (b *Blamer) blameFile(filePath string) (*gogit.BlameResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cached, ok := b.cache[filePath]; ok {
+		return cached.result, cached.err
+	}
+
+	result, err := gogit.Blame(b.head, filePath)
+	if err != nil {
+		err = fmt.Errorf("failed to blame %s: %w", filePath, err)
+	}
+	b.cache[filePath] = &fileBlame{result: result, err: err}
+
+	return result, err
+}
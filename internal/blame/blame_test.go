@@ -0,0 +1,99 @@
+package blame
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlamer_BlameLines(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	firstHash := commitFile(t, repo, dir, "config/app.env", "USER=admin\nTOKEN=old\nDEBUG=true\n", "alice@example.com", first)
+	secondHash := commitFile(t, repo, dir, "config/app.env", "USER=admin\nTOKEN=new\nDEBUG=true\n", "bob@example.com", second)
+
+	blamer, err := NewBlamer(filepath.Join(dir, "config"))
+	require.NoError(t, err)
+
+	t.Run("single unchanged line", func(t *testing.T) {
+		info, err := blamer.BlameLines("config/app.env", 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, firstHash, info.CommitSHA)
+		assert.Equal(t, "alice@example.com", info.AuthorEmail)
+		assert.Equal(t, first, info.Timestamp)
+	})
+
+	t.Run("range returns the most recent change", func(t *testing.T) {
+		info, err := blamer.BlameLines("config/app.env", 1, 3)
+		require.NoError(t, err)
+		assert.Equal(t, secondHash, info.CommitSHA)
+		assert.Equal(t, "bob@example.com", info.AuthorEmail)
+		assert.Equal(t, second, info.Timestamp)
+	})
+
+	t.Run("to line before from line blames a single line", func(t *testing.T) {
+		info, err := blamer.BlameLines("./config/app.env", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, secondHash, info.CommitSHA)
+	})
+
+	t.Run("line out of range", func(t *testing.T) {
+		_, err := blamer.BlameLines("config/app.env", 3, 10)
+		assert.ErrorIs(t, err, ErrLineOutOfRange)
+	})
+
+	t.Run("untracked file is cached as an error", func(t *testing.T) {
+		_, err := blamer.BlameLines("missing.txt", 1, 1)
+		require.Error(t, err)
+		_, err = blamer.BlameLines("missing.txt", 1, 1)
+		require.Error(t, err)
+		assert.Len(t, blamer.cache, 2)
+	})
+}
+
+func TestNewBlamer_Errors(t *testing.T) {
+	t.Run("not a git repository", func(t *testing.T) {
+		_, err := NewBlamer(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("repository without commits", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := gogit.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		_, err = NewBlamer(dir)
+		assert.Error(t, err)
+	})
+}
+
+func commitFile(t *testing.T, repo *gogit.Repository, dir, name, content, email string, when time.Time) string {
+	t.Helper()
+
+	fullPath := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o600))
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(name)
+	require.NoError(t, err)
+
+	hash, err := wt.Commit("update "+name, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: email, When: when},
+	})
+	require.NoError(t, err)
+
+	return hash.String()
+}
@@ -0,0 +1,99 @@
+// Package bundle writes and reads scan bundles: the files to scan for secrets, along with the context needed to
+// test them on another host, in a zstd-compressed tar archive described by a manifest.
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FormatVersion is the version of the bundle format written by this package.
+const FormatVersion = 1
+
+// Names of the archive entries.
+const (
+	manifestName  = "manifest.json"
+	signatureName = "manifest.sig"
+	filesDir      = "files/"
+)
+
+// Errors returned while reading a bundle.
+var (
+	ErrInvalidBundle     = errors.New("invalid bundle")
+	ErrUnsupportedFormat = errors.New("unsupported bundle format")
+	ErrHashMismatch      = errors.New("bundle file content does not match its hash")
+	ErrNotSigned         = errors.New("bundle is not signed")
+	ErrInvalidSignature  = errors.New("bundle signature is invalid")
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Context is what the bundle was created for, left to the writer to define.
+	Context json.RawMessage `json:"context,omitempty"`
+	Files   []File          `json:"files"`
+}
+
+// File is a file in a bundle.
+type File struct {
+	// Path is the slash-separated path of the file relative to the scanned directory.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// LoadSigningKey reads an ed25519 private key from a PEM encoded PKCS #8 file.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	signingKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an ed25519 key", path)
+	}
+	return signingKey, nil
+}
+
+// LoadVerifyKey reads an ed25519 public key from a PEM encoded PKIX file.
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse verification key %s: %w", path, err)
+	}
+	verifyKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("verification key %s is not an ed25519 key", path)
+	}
+	return verifyKey, nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded %s", path, blockType)
+	}
+	return block.Bytes, nil
+}
@@ -0,0 +1,195 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBundle bundles files, a map of slash-separated paths to contents, and returns the path of the bundle.
+func writeBundle(t *testing.T, files map[string]string, signingKey ed25519.PrivateKey) string {
+	t.Helper()
+
+	src := t.TempDir()
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+	w, err := Create(bundlePath, signingKey)
+	require.NoError(t, err)
+
+	for name, content := range files {
+		filePath := filepath.Join(src, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+		require.NoError(t, w.AddFile(name, filePath))
+	}
+
+	_, err = w.Close(json.RawMessage(`{"branch":"main"}`))
+	require.NoError(t, err)
+	return bundlePath
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	bundlePath := writeBundle(t, map[string]string{"config.env": "TOKEN=abc", "src/app.js": "const a = 1"}, nil)
+
+	dir := t.TempDir()
+	manifest, err := Extract(bundlePath, dir, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatVersion, manifest.Version)
+	assert.JSONEq(t, `{"branch":"main"}`, string(manifest.Context))
+	require.Len(t, manifest.Files, 2)
+	for _, file := range manifest.Files {
+		assert.Len(t, file.SHA256, 64)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "src", "app.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "const a = 1", string(content))
+}
+
+func TestBundle_Signature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signed := writeBundle(t, map[string]string{"a.txt": "a"}, privateKey)
+	unsigned := writeBundle(t, map[string]string{"a.txt": "a"}, nil)
+
+	_, err = Extract(signed, t.TempDir(), publicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	_, err = Extract(signed, dir, otherKey)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no file is extracted before the signature is verified")
+
+	_, err = Extract(unsigned, t.TempDir(), publicKey)
+	require.ErrorIs(t, err, ErrNotSigned)
+}
+
+func TestBundle_Create_NothingWrittenOnAbort(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+	w, err := Create(bundlePath, nil)
+	require.NoError(t, err)
+
+	w.Abort()
+
+	entries, err := os.ReadDir(filepath.Dir(bundlePath))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestBundle_AddFile_RejectsNonLocalNames(t *testing.T) {
+	w, err := Create(filepath.Join(t.TempDir(), "scan.tar.zst"), nil)
+	require.NoError(t, err)
+	defer w.Abort()
+
+	for _, name := range []string{"", "../secret", "/etc/passwd", "a/../../b"} {
+		assert.ErrorIs(t, w.AddFile(name, "unused"), ErrInvalidBundle, name)
+	}
+}
+
+// writeRawBundle writes the given tar entries into a bundle, to craft bundles the Writer would not write.
+func writeRawBundle(t *testing.T, entries map[string]string) string {
+	t.Helper()
+
+	bundlePath := filepath.Join(t.TempDir(), "crafted.tar.zst")
+	f, err := os.Create(bundlePath)
+	require.NoError(t, err)
+	zw, err := zstd.NewWriter(f)
+	require.NoError(t, err)
+	tw := tar.NewWriter(zw)
+	for name, content := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+	return bundlePath
+}
+
+func TestExtract_Invalid(t *testing.T) {
+	const manifest = `{"version":1,"files":[{"path":"a.txt","size":1,` +
+		`"sha256":"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"}]}`
+
+	testCases := []struct {
+		entries map[string]string
+		err     error
+		desc    string
+	}{
+		{entries: map[string]string{manifestName: manifest, "files/a.txt": "a"}, err: nil, desc: "valid"},
+		{entries: map[string]string{manifestName: manifest, "files/a.txt": "b"}, err: ErrHashMismatch, desc: "tampered file"},
+		{entries: map[string]string{manifestName: manifest}, err: ErrInvalidBundle, desc: "missing file"},
+		{entries: map[string]string{"files/a.txt": "a"}, err: ErrInvalidBundle, desc: "missing manifest"},
+		{entries: map[string]string{manifestName: manifest, "files/a.txt": "a", "files/b.txt": "b"}, err: ErrInvalidBundle, desc: "unlisted file"},
+		{entries: map[string]string{manifestName: manifest, "files/../a.txt": "a"}, err: ErrInvalidBundle, desc: "path traversal"},
+		{entries: map[string]string{manifestName: `{"version":2,"files":[]}`}, err: ErrUnsupportedFormat, desc: "newer format"},
+		{
+			entries: map[string]string{manifestName: `{"version":1,"files":[{"path":"../a.txt","size":1,"sha256":""}]}`},
+			err:     ErrInvalidBundle,
+			desc:    "path traversal in manifest",
+		},
+		{
+			entries: map[string]string{manifestName: `{"version":1,"files":[{"path":"a.txt","size":1,"sha256":""},{"path":"a.txt","size":1,"sha256":""}]}`},
+			err:     ErrInvalidBundle,
+			desc:    "file listed twice",
+		},
+		{
+			entries: map[string]string{manifestName: `{"version":1,"files":[{"path":"a.txt","size":5368709120,"sha256":""}]}`},
+			err:     ErrInvalidBundle,
+			desc:    "files too large",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := Extract(writeRawBundle(t, tc.entries), t.TempDir(), nil)
+			if tc.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	privatePath := filepath.Join(dir, "signing.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, "verify.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+
+	signingKey, err := LoadSigningKey(privatePath)
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, signingKey)
+
+	verifyKey, err := LoadVerifyKey(publicPath)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, verifyKey)
+
+	_, err = LoadVerifyKey(privatePath)
+	assert.Error(t, err)
+}
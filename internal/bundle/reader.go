@@ -0,0 +1,220 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxManifestSize bounds the manifest and signature entries read into memory.
+const maxManifestSize = 64 << 20
+
+// maxExtractedSize bounds the total size of the files extracted from a bundle.
+const maxExtractedSize = 4 << 30
+
+// Extract verifies the bundle at bundlePath and extracts its files into dir, which must exist.
+// If verifyKey is set, the bundle must be signed with the matching signing key.
+// The manifest is verified before any file is extracted, and only the files it lists are extracted.
+// The manifest is only returned once every file matches its hash.
+func Extract(bundlePath, dir string, verifyKey ed25519.PublicKey) (*Manifest, error) {
+	manifest, err := readManifest(bundlePath, verifyKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := extractFiles(bundlePath, dir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// walkEntries calls fn with every entry of the bundle at bundlePath, in the order of the archive.
+func walkEntries(bundlePath string, fn func(header *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// readManifest reads and verifies the manifest of the bundle at bundlePath, skipping the content of its files.
+func readManifest(bundlePath string, verifyKey ed25519.PublicKey) (*Manifest, error) {
+	var manifestData, signature []byte
+	err := walkEntries(bundlePath, func(header *tar.Header, r io.Reader) error {
+		var err error
+		switch {
+		case header.Name == manifestName:
+			manifestData, err = readEntry(r)
+		case header.Name == signatureName:
+			signature, err = readEntry(r)
+		case strings.HasPrefix(header.Name, filesDir) && header.Typeflag == tar.TypeReg:
+			// files are only extracted once the manifest is verified
+		default:
+			err = fmt.Errorf("%w: unexpected entry %q", ErrInvalidBundle, header.Name)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("%w: no manifest", ErrInvalidBundle)
+	}
+	if verifyKey != nil {
+		if signature == nil {
+			return nil, ErrNotSigned
+		}
+		if !ed25519.Verify(verifyKey, manifestData, signature) {
+			return nil, ErrInvalidSignature
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: malformed manifest: %w", ErrInvalidBundle, err)
+	}
+	if manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrUnsupportedFormat, manifest.Version)
+	}
+	if err := checkManifestFiles(&manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// checkManifestFiles checks that the files of the manifest can be extracted, before any of them is.
+func checkManifestFiles(manifest *Manifest) error {
+	var total int64
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		if !isLocalName(file.Path) {
+			return fmt.Errorf("%w: file %q is outside of the bundle", ErrInvalidBundle, file.Path)
+		}
+		if listed[file.Path] {
+			return fmt.Errorf("%w: %s is listed more than once", ErrInvalidBundle, file.Path)
+		}
+		listed[file.Path] = true
+
+		if file.Size < 0 || file.Size > maxExtractedSize-total {
+			return fmt.Errorf("%w: files larger than %d bytes in total", ErrInvalidBundle, int64(maxExtractedSize))
+		}
+		total += file.Size
+	}
+	return nil
+}
+
+// extractFiles extracts the files of the bundle at bundlePath listed in its verified manifest into dir.
+func extractFiles(bundlePath, dir string, manifest *Manifest) error {
+	listed := make(map[string]File, len(manifest.Files))
+	for _, file := range manifest.Files {
+		listed[file.Path] = file
+	}
+
+	extracted := map[string]bool{}
+	err := walkEntries(bundlePath, func(header *tar.Header, r io.Reader) error {
+		if !strings.HasPrefix(header.Name, filesDir) {
+			return nil
+		}
+
+		name := strings.TrimPrefix(header.Name, filesDir)
+		want, ok := listed[name]
+		if !ok {
+			return fmt.Errorf("%w: %s is not listed in the manifest", ErrInvalidBundle, name)
+		}
+		if extracted[name] {
+			return fmt.Errorf("%w: %s is in the bundle more than once", ErrInvalidBundle, name)
+		}
+		if header.Size != want.Size {
+			return fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+		extracted[name] = true
+
+		got, err := extractFile(r, name, dir)
+		if err != nil {
+			return err
+		}
+		if got.Size != want.Size || got.SHA256 != want.SHA256 {
+			return fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range manifest.Files {
+		if !extracted[file.Path] {
+			return fmt.Errorf("%w: %s is missing", ErrInvalidBundle, file.Path)
+		}
+	}
+	return nil
+}
+
+func readEntry(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("%w: manifest too large", ErrInvalidBundle)
+	}
+	return data, nil
+}
+
+// extractFile writes the file name of the bundle into dir, hashing its content.
+func extractFile(r io.Reader, name, dir string) (File, error) {
+	if !isLocalName(name) {
+		return File{}, fmt.Errorf("%w: file %q is outside of the bundle", ErrInvalidBundle, name)
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return File{}, fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return File{}, fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, hash), r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+
+	return File{Path: name, Size: n, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
@@ -0,0 +1,156 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Writer writes a bundle. The bundle only appears at its path once it is closed successfully.
+type Writer struct {
+	path       string
+	tmp        *os.File
+	zw         *zstd.Encoder
+	tw         *tar.Writer
+	signingKey ed25519.PrivateKey
+	files      []File
+	now        func() time.Time
+}
+
+// Create starts writing a bundle to path. If signingKey is set, the manifest of the bundle is signed with it.
+func Create(path string, signingKey ed25519.PrivateKey) (*Writer, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	zw, err := zstd.NewWriter(tmp)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	return &Writer{
+		path:       path,
+		tmp:        tmp,
+		zw:         zw,
+		tw:         tar.NewWriter(zw),
+		signingKey: signingKey,
+		now:        time.Now,
+	}, nil
+}
+
+// AddFile adds the file at filePath to the bundle as name, a slash-separated path relative to the scanned directory.
+func (w *Writer) AddFile(name, filePath string) error {
+	if !isLocalName(name) {
+		return fmt.Errorf("%w: %q is not a relative path", ErrInvalidBundle, name)
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+
+	header := &tar.Header{
+		Name:    filesDir + name,
+		Mode:    0o644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		Format:  tar.FormatPAX,
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w.tw, hash), f)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+
+	w.files = append(w.files, File{Path: name, Size: n, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	return nil
+}
+
+// Close writes the manifest, describing the bundle with bundleContext, and moves the bundle to its path.
+// It returns the manifest written.
+func (w *Writer) Close(bundleContext json.RawMessage) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:   FormatVersion,
+		CreatedAt: w.now().UTC(),
+		Context:   bundleContext,
+		Files:     w.files,
+	}
+	if manifest.Files == nil {
+		manifest.Files = []File{}
+	}
+
+	if err := w.finish(manifest); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	if err := os.Rename(w.tmp.Name(), w.path); err != nil {
+		os.Remove(w.tmp.Name())
+		return nil, fmt.Errorf("failed to move bundle to %s: %w", w.path, err)
+	}
+	return manifest, nil
+}
+
+func (w *Writer) finish(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %w", err)
+	}
+	if err := w.writeEntry(manifestName, data); err != nil {
+		return err
+	}
+	if w.signingKey != nil {
+		if err := w.writeEntry(signatureName, ed25519.Sign(w.signingKey, data)); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(w.tw.Close(), w.zw.Close(), w.tmp.Close())
+}
+
+func (w *Writer) writeEntry(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: w.now(), Format: tar.FormatPAX}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Abort discards the bundle, leaving nothing at its path.
+func (w *Writer) Abort() {
+	w.tw.Close()
+	w.zw.Close()
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+}
+
+// isLocalName reports whether name is a slash-separated path staying within the directory it is relative to.
+func isLocalName(name string) bool {
+	return name != "" && path.Clean(name) == name && filepath.IsLocal(filepath.FromSlash(name))
+}
@@ -0,0 +1,218 @@
+// Package ci detects the CI provider a command runs on and the SCM metadata it exposes.
+package ci
+
+import (
+	"strings"
+)
+
+// Provider identifies a supported CI system.
+type Provider string
+
+// Supported CI providers.
+const (
+	GitHubActions  Provider = "github-actions"
+	GitLabCI       Provider = "gitlab-ci"
+	Jenkins        Provider = "jenkins"
+	CircleCI       Provider = "circleci"
+	AzurePipelines Provider = "azure-pipelines"
+	Bitbucket      Provider = "bitbucket-pipelines"
+	Buildkite      Provider = "buildkite"
+)
+
+const (
+	refsHeadsPrefix = "refs/heads/"
+	refsPullPrefix  = "refs/pull/"
+)
+
+// Environment holds the SCM metadata a CI provider exposes for the current build.
+// Fields are empty when the provider does not expose the value.
+type Environment struct {
+	Provider   Provider
+	Branch     string
+	CommitSHA  string
+	RepoURL    string
+	PRNumber   string
+	BaseBranch string
+}
+
+// IsPullRequest reports whether the build was triggered for a pull or merge request.
+func (e *Environment) IsPullRequest() bool {
+	return e != nil && e.PRNumber != ""
+}
+
+type detector struct {
+	provider Provider
+	detect   func(getenv func(string) string) bool
+	resolve  func(getenv func(string) string) Environment
+}
+
+// detectors are evaluated in order, the first match wins.
+var detectors = []detector{
+	{GitHubActions, isTrue("GITHUB_ACTIONS"), resolveGitHubActions},
+	{GitLabCI, isTrue("GITLAB_CI"), resolveGitLabCI},
+	{AzurePipelines, isTrue("TF_BUILD"), resolveAzurePipelines},
+	{CircleCI, isTrue("CIRCLECI"), resolveCircleCI},
+	{Buildkite, isTrue("BUILDKITE"), resolveBuildkite},
+	{Bitbucket, isSet("BITBUCKET_BUILD_NUMBER"), resolveBitbucket},
+	{Jenkins, isSet("JENKINS_URL"), resolveJenkins},
+}
+
+// Detect returns the CI environment described by the given environment lookup,
+// or nil if no supported CI provider is detected.
+func Detect(getenv func(string) string) *Environment {
+	for _, d := range detectors {
+		if !d.detect(getenv) {
+			continue
+		}
+		env := d.resolve(getenv)
+		env.Provider = d.provider
+		return &env
+	}
+	return nil
+}
+
+// https://docs.github.com/en/actions/reference/variables-reference
+func resolveGitHubActions(getenv func(string) string) Environment {
+	env := Environment{
+		CommitSHA:  getenv("GITHUB_SHA"),
+		BaseBranch: getenv("GITHUB_BASE_REF"),
+	}
+
+	// GITHUB_HEAD_REF is only set for pull_request events
+	env.Branch = getenv("GITHUB_HEAD_REF")
+	if env.Branch == "" && getenv("GITHUB_REF_TYPE") != "tag" {
+		env.Branch = getenv("GITHUB_REF_NAME")
+	}
+
+	if server, repo := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+		env.RepoURL = strings.TrimSuffix(server, "/") + "/" + repo
+	}
+
+	// pull request refs have the form refs/pull/<number>/merge
+	if ref := getenv("GITHUB_REF"); strings.HasPrefix(ref, refsPullPrefix) {
+		env.PRNumber, _, _ = strings.Cut(strings.TrimPrefix(ref, refsPullPrefix), "/")
+	}
+
+	return env
+}
+
+// https://docs.gitlab.com/ci/variables/predefined_variables/
+func resolveGitLabCI(getenv func(string) string) Environment {
+	return Environment{
+		Branch:     firstNonEmpty(getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_NAME"), getenv("CI_COMMIT_BRANCH")),
+		CommitSHA:  getenv("CI_COMMIT_SHA"),
+		RepoURL:    getenv("CI_PROJECT_URL"),
+		PRNumber:   getenv("CI_MERGE_REQUEST_IID"),
+		BaseBranch: getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME"),
+	}
+}
+
+// https://learn.microsoft.com/en-us/azure/devops/pipelines/build/variables
+func resolveAzurePipelines(getenv func(string) string) Environment {
+	env := Environment{
+		Branch:     trimRefsHeads(firstNonEmpty(getenv("SYSTEM_PULLREQUEST_SOURCEBRANCH"), getenv("BUILD_SOURCEBRANCH"))),
+		CommitSHA:  getenv("BUILD_SOURCEVERSION"),
+		RepoURL:    getenv("BUILD_REPOSITORY_URI"),
+		PRNumber:   firstNonEmpty(getenv("SYSTEM_PULLREQUEST_PULLREQUESTNUMBER"), getenv("SYSTEM_PULLREQUEST_PULLREQUESTID")),
+		BaseBranch: trimRefsHeads(getenv("SYSTEM_PULLREQUEST_TARGETBRANCH")),
+	}
+
+	// BUILD_SOURCEBRANCH points to refs/pull/<id>/merge or refs/tags/<tag> when not building a branch
+	if strings.HasPrefix(env.Branch, "refs/") {
+		env.Branch = ""
+	}
+
+	return env
+}
+
+// https://circleci.com/docs/variables/
+func resolveCircleCI(getenv func(string) string) Environment {
+	env := Environment{
+		Branch:    getenv("CIRCLE_BRANCH"),
+		CommitSHA: getenv("CIRCLE_SHA1"),
+		RepoURL:   getenv("CIRCLE_REPOSITORY_URL"),
+		PRNumber:  getenv("CIRCLE_PR_NUMBER"),
+	}
+
+	// CIRCLE_PR_NUMBER is only set for forked PRs, otherwise derive it from the PR URL
+	if pr := getenv("CIRCLE_PULL_REQUEST"); env.PRNumber == "" && pr != "" {
+		env.PRNumber = pr[strings.LastIndex(pr, "/")+1:]
+	}
+
+	return env
+}
+
+// https://buildkite.com/docs/pipelines/configure/environment-variables
+func resolveBuildkite(getenv func(string) string) Environment {
+	env := Environment{
+		Branch:     getenv("BUILDKITE_BRANCH"),
+		CommitSHA:  getenv("BUILDKITE_COMMIT"),
+		RepoURL:    getenv("BUILDKITE_REPO"),
+		BaseBranch: getenv("BUILDKITE_PULL_REQUEST_BASE_BRANCH"),
+	}
+
+	// BUILDKITE_PULL_REQUEST is "false" for non-PR builds
+	if pr := getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+		env.PRNumber = pr
+	}
+
+	// BUILDKITE_COMMIT may be the symbolic "HEAD" for builds triggered without a commit
+	if env.CommitSHA == "HEAD" {
+		env.CommitSHA = ""
+	}
+
+	return env
+}
+
+// https://support.atlassian.com/bitbucket-cloud/docs/variables-and-secrets/
+func resolveBitbucket(getenv func(string) string) Environment {
+	return Environment{
+		Branch:     getenv("BITBUCKET_BRANCH"),
+		CommitSHA:  getenv("BITBUCKET_COMMIT"),
+		RepoURL:    getenv("BITBUCKET_GIT_HTTP_ORIGIN"),
+		PRNumber:   getenv("BITBUCKET_PR_ID"),
+		BaseBranch: getenv("BITBUCKET_PR_DESTINATION_BRANCH"),
+	}
+}
+
+// https://www.jenkins.io/doc/book/pipeline/jenkinsfile/#using-environment-variables
+func resolveJenkins(getenv func(string) string) Environment {
+	branch := firstNonEmpty(getenv("CHANGE_BRANCH"), getenv("BRANCH_NAME"), getenv("GIT_LOCAL_BRANCH"))
+	if branch == "" {
+		// GIT_BRANCH is set by the git plugin and usually includes the remote name
+		branch = strings.TrimPrefix(getenv("GIT_BRANCH"), "origin/")
+	}
+
+	return Environment{
+		Branch:     branch,
+		CommitSHA:  getenv("GIT_COMMIT"),
+		RepoURL:    getenv("GIT_URL"),
+		PRNumber:   getenv("CHANGE_ID"),
+		BaseBranch: getenv("CHANGE_TARGET"),
+	}
+}
+
+func isTrue(key string) func(func(string) string) bool {
+	return func(getenv func(string) string) bool {
+		return strings.EqualFold(getenv(key), "true")
+	}
+}
+
+func isSet(key string) func(func(string) string) bool {
+	return func(getenv func(string) string) bool {
+		return getenv(key) != ""
+	}
+}
+
+func trimRefsHeads(ref string) string {
+	return strings.TrimPrefix(ref, refsHeadsPrefix)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
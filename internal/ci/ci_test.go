@@ -0,0 +1,264 @@
+package ci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envFrom(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		vars     map[string]string
+		expected *Environment
+	}{
+		{
+			name:     "no CI environment",
+			vars:     map[string]string{"HOME": "/home/user"},
+			expected: nil,
+		},
+		{
+			name: "github actions push",
+			vars: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REF":        "refs/heads/main",
+				"GITHUB_REF_NAME":   "main",
+				"GITHUB_REF_TYPE":   "branch",
+				"GITHUB_SHA":        "0123456789abcdef",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "snyk/cli-extension-secrets",
+			},
+			expected: &Environment{
+				Provider:  GitHubActions,
+				Branch:    "main",
+				CommitSHA: "0123456789abcdef",
+				RepoURL:   "https://github.com/snyk/cli-extension-secrets",
+			},
+		},
+		{
+			name: "github actions pull request",
+			vars: map[string]string{
+				"GITHUB_ACTIONS":    "true",
+				"GITHUB_REF":        "refs/pull/42/merge",
+				"GITHUB_REF_NAME":   "42/merge",
+				"GITHUB_HEAD_REF":   "feat/ci-detection",
+				"GITHUB_BASE_REF":   "main",
+				"GITHUB_SHA":        "0123456789abcdef",
+				"GITHUB_SERVER_URL": "https://github.com",
+				"GITHUB_REPOSITORY": "snyk/cli-extension-secrets",
+			},
+			expected: &Environment{
+				Provider:   GitHubActions,
+				Branch:     "feat/ci-detection",
+				CommitSHA:  "0123456789abcdef",
+				RepoURL:    "https://github.com/snyk/cli-extension-secrets",
+				PRNumber:   "42",
+				BaseBranch: "main",
+			},
+		},
+		{
+			name: "github actions tag",
+			vars: map[string]string{
+				"GITHUB_ACTIONS":  "true",
+				"GITHUB_REF":      "refs/tags/v1.0.0",
+				"GITHUB_REF_NAME": "v1.0.0",
+				"GITHUB_REF_TYPE": "tag",
+				"GITHUB_SHA":      "0123456789abcdef",
+			},
+			expected: &Environment{
+				Provider:  GitHubActions,
+				CommitSHA: "0123456789abcdef",
+			},
+		},
+		{
+			name: "gitlab merge request",
+			vars: map[string]string{
+				"GITLAB_CI":                           "true",
+				"CI_COMMIT_SHA":                       "0123456789abcdef",
+				"CI_PROJECT_URL":                      "https://gitlab.com/snyk/repo",
+				"CI_MERGE_REQUEST_IID":                "7",
+				"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME": "feature",
+				"CI_MERGE_REQUEST_TARGET_BRANCH_NAME": "main",
+			},
+			expected: &Environment{
+				Provider:   GitLabCI,
+				Branch:     "feature",
+				CommitSHA:  "0123456789abcdef",
+				RepoURL:    "https://gitlab.com/snyk/repo",
+				PRNumber:   "7",
+				BaseBranch: "main",
+			},
+		},
+		{
+			name: "gitlab branch pipeline",
+			vars: map[string]string{
+				"GITLAB_CI":        "true",
+				"CI_COMMIT_BRANCH": "main",
+				"CI_COMMIT_SHA":    "0123456789abcdef",
+			},
+			expected: &Environment{
+				Provider:  GitLabCI,
+				Branch:    "main",
+				CommitSHA: "0123456789abcdef",
+			},
+		},
+		{
+			name: "jenkins multibranch pull request",
+			vars: map[string]string{
+				"JENKINS_URL":   "https://jenkins.example.com/",
+				"BRANCH_NAME":   "PR-12",
+				"CHANGE_ID":     "12",
+				"CHANGE_BRANCH": "feature",
+				"CHANGE_TARGET": "main",
+				"GIT_COMMIT":    "0123456789abcdef",
+				"GIT_URL":       "https://github.com/snyk/repo.git",
+			},
+			expected: &Environment{
+				Provider:   Jenkins,
+				Branch:     "feature",
+				CommitSHA:  "0123456789abcdef",
+				RepoURL:    "https://github.com/snyk/repo.git",
+				PRNumber:   "12",
+				BaseBranch: "main",
+			},
+		},
+		{
+			name: "jenkins freestyle strips remote from branch",
+			vars: map[string]string{
+				"JENKINS_URL": "https://jenkins.example.com/",
+				"GIT_BRANCH":  "origin/release/1.x",
+			},
+			expected: &Environment{
+				Provider: Jenkins,
+				Branch:   "release/1.x",
+			},
+		},
+		{
+			name: "circleci pull request",
+			vars: map[string]string{
+				"CIRCLECI":              "true",
+				"CIRCLE_BRANCH":         "feature",
+				"CIRCLE_SHA1":           "0123456789abcdef",
+				"CIRCLE_REPOSITORY_URL": "git@github.com:snyk/repo.git",
+				"CIRCLE_PULL_REQUEST":   "https://github.com/snyk/repo/pull/99",
+			},
+			expected: &Environment{
+				Provider:  CircleCI,
+				Branch:    "feature",
+				CommitSHA: "0123456789abcdef",
+				RepoURL:   "git@github.com:snyk/repo.git",
+				PRNumber:  "99",
+			},
+		},
+		{
+			name: "azure pipelines pull request",
+			vars: map[string]string{
+				"TF_BUILD":                             "True",
+				"BUILD_SOURCEBRANCH":                   "refs/pull/5/merge",
+				"BUILD_SOURCEVERSION":                  "0123456789abcdef",
+				"BUILD_REPOSITORY_URI":                 "https://dev.azure.com/snyk/project/_git/repo",
+				"SYSTEM_PULLREQUEST_PULLREQUESTID":     "5",
+				"SYSTEM_PULLREQUEST_SOURCEBRANCH":      "refs/heads/feature",
+				"SYSTEM_PULLREQUEST_TARGETBRANCH":      "refs/heads/main",
+				"SYSTEM_PULLREQUEST_PULLREQUESTNUMBER": "",
+			},
+			expected: &Environment{
+				Provider:   AzurePipelines,
+				Branch:     "feature",
+				CommitSHA:  "0123456789abcdef",
+				RepoURL:    "https://dev.azure.com/snyk/project/_git/repo",
+				PRNumber:   "5",
+				BaseBranch: "main",
+			},
+		},
+		{
+			name: "azure pipelines tag build has no branch",
+			vars: map[string]string{
+				"TF_BUILD":            "True",
+				"BUILD_SOURCEBRANCH":  "refs/tags/v1.0.0",
+				"BUILD_SOURCEVERSION": "0123456789abcdef",
+			},
+			expected: &Environment{
+				Provider:  AzurePipelines,
+				CommitSHA: "0123456789abcdef",
+			},
+		},
+		{
+			name: "bitbucket pipelines pull request",
+			vars: map[string]string{
+				"BITBUCKET_BUILD_NUMBER":          "3",
+				"BITBUCKET_BRANCH":                "feature",
+				"BITBUCKET_COMMIT":                "0123456789abcdef",
+				"BITBUCKET_GIT_HTTP_ORIGIN":       "http://bitbucket.org/snyk/repo",
+				"BITBUCKET_PR_ID":                 "8",
+				"BITBUCKET_PR_DESTINATION_BRANCH": "main",
+			},
+			expected: &Environment{
+				Provider:   Bitbucket,
+				Branch:     "feature",
+				CommitSHA:  "0123456789abcdef",
+				RepoURL:    "http://bitbucket.org/snyk/repo",
+				PRNumber:   "8",
+				BaseBranch: "main",
+			},
+		},
+		{
+			name: "buildkite branch build",
+			vars: map[string]string{
+				"BUILDKITE":              "true",
+				"BUILDKITE_BRANCH":       "main",
+				"BUILDKITE_COMMIT":       "HEAD",
+				"BUILDKITE_REPO":         "git@github.com:snyk/repo.git",
+				"BUILDKITE_PULL_REQUEST": "false",
+			},
+			expected: &Environment{
+				Provider: Buildkite,
+				Branch:   "main",
+				RepoURL:  "git@github.com:snyk/repo.git",
+			},
+		},
+		{
+			name: "buildkite pull request",
+			vars: map[string]string{
+				"BUILDKITE":                          "true",
+				"BUILDKITE_BRANCH":                   "feature",
+				"BUILDKITE_COMMIT":                   "0123456789abcdef",
+				"BUILDKITE_PULL_REQUEST":             "15",
+				"BUILDKITE_PULL_REQUEST_BASE_BRANCH": "main",
+			},
+			expected: &Environment{
+				Provider:   Buildkite,
+				Branch:     "feature",
+				CommitSHA:  "0123456789abcdef",
+				PRNumber:   "15",
+				BaseBranch: "main",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			env := Detect(envFrom(tc.vars))
+			if tc.expected == nil {
+				assert.Nil(t, env)
+				return
+			}
+			require.NotNil(t, env)
+			assert.Equal(t, *tc.expected, *env)
+		})
+	}
+}
+
+func TestEnvironment_IsPullRequest(t *testing.T) {
+	var nilEnv *Environment
+	assert.False(t, nilEnv.IsPullRequest())
+	assert.False(t, (&Environment{Provider: GitLabCI}).IsPullRequest())
+	assert.True(t, (&Environment{Provider: GitLabCI, PRNumber: "1"}).IsPullRequest())
+}
@@ -0,0 +1,113 @@
+package orgs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cacheFileName is the name of the cache file in the cache directory of the CLI.
+const cacheFileName = "secrets-org-slugs.json"
+
+// CacheTTL is how long a resolved slug is used before it is resolved again, as slugs can be renamed.
+const CacheTTL = 24 * time.Hour
+
+// cacheEntry is an organization ID resolved from a slug.
+type cacheEntry struct {
+	OrgID      uuid.UUID `json:"orgId"`
+	ResolvedAt time.Time `json:"resolvedAt"`
+}
+
+// Cache stores resolved organization slugs per API URL in a JSON file.
+type Cache struct {
+	path string
+	now  func() time.Time
+	mu   sync.Mutex
+}
+
+// NewCache returns a cache in dir. An empty dir disables caching.
+func NewCache(dir string) *Cache {
+	c := &Cache{now: time.Now}
+	if dir != "" {
+		c.path = filepath.Join(dir, cacheFileName)
+	}
+	return c
+}
+
+// Get returns the organization ID cached for slug on the API at apiURL, unless it expired.
+func (c *Cache) Get(apiURL, slug string) (uuid.UUID, bool) {
+	if c.path == "" {
+		return uuid.Nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.load()[apiURL][slug]
+	if !ok || c.now().Sub(entry.ResolvedAt) > CacheTTL {
+		return uuid.Nil, false
+	}
+	return entry.OrgID, true
+}
+
+// Put caches orgID as the organization ID of slug on the API at apiURL.
+func (c *Cache) Put(apiURL, slug string, orgID uuid.UUID) error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.load()
+	if entries[apiURL] == nil {
+		entries[apiURL] = map[string]cacheEntry{}
+	}
+	entries[apiURL][slug] = cacheEntry{OrgID: orgID, ResolvedAt: c.now().UTC()}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal organization cache: %w", err)
+	}
+	return writeFileAtomic(c.path, data)
+}
+
+// load reads the cached entries, treating a missing or corrupt cache file as empty.
+func (c *Cache) load() map[string]map[string]cacheEntry {
+	entries := map[string]map[string]cacheEntry{}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return map[string]map[string]cacheEntry{}
+	}
+	return entries
+}
+
+// writeFileAtomic replaces the file at path with data, so that concurrent runs never read a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create organization cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write organization cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write organization cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace organization cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package orgs provides a client resolving Snyk organization slugs to organization IDs.
+package orgs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+)
+
+// orgsAPIVersion is the version of the REST API listing organizations.
+const orgsAPIVersion = "2024-03-12"
+
+// Errors returned when a slug does not resolve to a single organization.
+var (
+	ErrOrgNotFound  = errors.New("no organization with this slug is accessible")
+	ErrOrgAmbiguous = errors.New("more than one organization has this slug")
+)
+
+// Client resolves organization slugs through the REST API, caching the IDs it resolved.
+type Client struct {
+	httpClient *http.Client
+	apiURL     string
+	cache      *Cache
+	logger     *zerolog.Logger
+}
+
+// NewClient creates a new Client from the given invocation context, caching into the cache directory of the CLI.
+func NewClient(ictx workflow.InvocationContext, retryConfig retry.Config) *Client {
+	config := ictx.GetConfiguration()
+
+	return &Client{
+		httpClient: retry.WrapClient(ictx.GetNetworkAccess().GetHttpClient(), retryConfig),
+		apiURL:     config.GetString(configuration.API_URL),
+		cache:      NewCache(config.GetString(configuration.CACHE_PATH)),
+		logger:     ictx.GetEnhancedLogger(),
+	}
+}
+
+// ResolveSlug returns the ID of the organization with slug, from the cache if it was resolved recently.
+func (c *Client) ResolveSlug(ctx context.Context, slug string) (uuid.UUID, error) {
+	if id, ok := c.cache.Get(c.apiURL, slug); ok {
+		c.logger.Debug().Str("slug", slug).Str("orgID", id.String()).Msg("Resolved organization slug from cache")
+		return id, nil
+	}
+
+	id, err := c.fetchOrgID(ctx, slug)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := c.cache.Put(c.apiURL, slug, id); err != nil {
+		c.logger.Debug().Err(err).Msg("Failed to cache organization slug")
+	}
+	return id, nil
+}
+
+// orgsResponse is the part of the list organizations response needed to match slugs.
+type orgsResponse struct {
+	Data []struct {
+		ID         uuid.UUID `json:"id"`
+		Attributes struct {
+			Slug string `json:"slug"`
+		} `json:"attributes"`
+	} `json:"data"`
+}
+
+func (c *Client) fetchOrgID(ctx context.Context, slug string) (uuid.UUID, error) {
+	endpoint, err := url.JoinPath(c.apiURL, "rest", "orgs")
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid API URL %q: %w", c.apiURL, err)
+	}
+	query := url.Values{"version": {orgsAPIVersion}, "slug": {slug}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create list organizations request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("list organizations request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return uuid.Nil, fmt.Errorf("unexpected response status %d listing organizations", resp.StatusCode)
+	}
+
+	var body orgsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to decode list organizations response: %w", err)
+	}
+
+	// the API may match slugs loosely, only exact matches identify the organization
+	var ids []uuid.UUID
+	for _, org := range body.Data {
+		if org.Attributes.Slug == slug && !slices.Contains(ids, org.ID) {
+			ids = append(ids, org.ID)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return uuid.Nil, fmt.Errorf("%w: %s", ErrOrgNotFound, slug)
+	case 1:
+		return ids[0], nil
+	default:
+		return uuid.Nil, fmt.Errorf("%w: %s", ErrOrgAmbiguous, slug)
+	}
+}
@@ -0,0 +1,120 @@
+package orgs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubAPI serves the list organizations endpoint with orgs, a map of org IDs to slugs, counting the requests.
+// Like the API, it may return organizations whose slug does not match the requested one exactly.
+func newStubAPI(t *testing.T, orgs map[uuid.UUID]string) (string, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/rest/orgs", r.URL.Path)
+		assert.Equal(t, orgsAPIVersion, r.URL.Query().Get("version"))
+		assert.NotEmpty(t, r.URL.Query().Get("slug"))
+
+		body := `{"data":[`
+		sep := ""
+		for id, orgSlug := range orgs {
+			body += fmt.Sprintf(`%s{"id":%q,"type":"org","attributes":{"slug":%q,"name":"Org"}}`, sep, id, orgSlug)
+			sep = ","
+		}
+		body += `]}`
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, &requests
+}
+
+func newTestClient(apiURL, cacheDir string) *Client {
+	logger := zerolog.Nop()
+	return &Client{httpClient: http.DefaultClient, apiURL: apiURL, cache: NewCache(cacheDir), logger: &logger}
+}
+
+func TestClient_ResolveSlug(t *testing.T) {
+	orgID := uuid.New()
+	apiURL, requests := newStubAPI(t, map[uuid.UUID]string{orgID: "my-org", uuid.New(): "my-org-2"})
+	cacheDir := t.TempDir()
+
+	id, err := newTestClient(apiURL, cacheDir).ResolveSlug(t.Context(), "my-org")
+	require.NoError(t, err)
+	assert.Equal(t, orgID, id)
+
+	// a later run resolves the slug from the cache
+	id, err = newTestClient(apiURL, cacheDir).ResolveSlug(t.Context(), "my-org")
+	require.NoError(t, err)
+	assert.Equal(t, orgID, id)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_ResolveSlug_Errors(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		apiURL, _ := newStubAPI(t, map[uuid.UUID]string{uuid.New(): "other-org"})
+
+		_, err := newTestClient(apiURL, t.TempDir()).ResolveSlug(t.Context(), "my-org")
+		assert.ErrorIs(t, err, ErrOrgNotFound)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		apiURL, _ := newStubAPI(t, map[uuid.UUID]string{uuid.New(): "my-org", uuid.New(): "my-org"})
+
+		_, err := newTestClient(apiURL, t.TempDir()).ResolveSlug(t.Context(), "my-org")
+		assert.ErrorIs(t, err, ErrOrgAmbiguous)
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		t.Cleanup(server.Close)
+
+		_, err := newTestClient(server.URL, t.TempDir()).ResolveSlug(t.Context(), "my-org")
+		assert.ErrorContains(t, err, "unexpected response status 401")
+	})
+}
+
+func TestCache(t *testing.T) {
+	const apiURL = "https://api.snyk.io"
+	orgID := uuid.New()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache := NewCache(t.TempDir())
+	cache.now = func() time.Time { return now }
+
+	_, ok := cache.Get(apiURL, "my-org")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Put(apiURL, "my-org", orgID))
+	id, ok := cache.Get(apiURL, "my-org")
+	assert.True(t, ok)
+	assert.Equal(t, orgID, id)
+
+	_, ok = cache.Get("https://api.eu.snyk.io", "my-org")
+	assert.False(t, ok, "slugs are cached per API")
+
+	now = now.Add(CacheTTL + time.Minute)
+	_, ok = cache.Get(apiURL, "my-org")
+	assert.False(t, ok, "expired entries are resolved again")
+}
+
+func TestCache_Disabled(t *testing.T) {
+	cache := NewCache("")
+
+	require.NoError(t, cache.Put("https://api.snyk.io", "my-org", uuid.New()))
+	_, ok := cache.Get("https://api.snyk.io", "my-org")
+	assert.False(t, ok)
+}
@@ -0,0 +1,246 @@
+// Package retry provides an HTTP transport that retries transient failures of idempotent requests.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Default retry limits.
+const (
+	DefaultMaxRetries    = 3
+	DefaultBaseDelay     = 500 * time.Millisecond
+	DefaultMaxDelay      = 10 * time.Second
+	DefaultMaxRetryAfter = time.Minute
+)
+
+// maxDrainBytes bounds how much of a failed response is read to reuse its connection.
+const maxDrainBytes = 64 << 10
+
+// Config holds the retry limits of a Transport.
+type Config struct {
+	// MaxRetries is the number of times a request is sent again after its first attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubling with every further retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts; the backoff does not grow beyond BaseDelay without it.
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest Retry-After the transport waits for; longer ones end the retries.
+	MaxRetryAfter time.Duration
+	// Idempotent reports whether a request may be sent more than once. Defaults to IdempotentMethod.
+	Idempotent func(*http.Request) bool
+	// Counter counts the retries made, if set.
+	Counter *Counter
+}
+
+// DefaultConfig returns the retry limits used by the secrets clients.
+func DefaultConfig() Config {
+	return Config{
+		MaxRetries:    DefaultMaxRetries,
+		BaseDelay:     DefaultBaseDelay,
+		MaxDelay:      DefaultMaxDelay,
+		MaxRetryAfter: DefaultMaxRetryAfter,
+	}
+}
+
+// Counter counts retries across all transports sharing it.
+type Counter struct {
+	n atomic.Int64
+}
+
+// Load returns the number of retries made.
+func (c *Counter) Load() int {
+	if c == nil {
+		return 0
+	}
+	return int(c.n.Load())
+}
+
+func (c *Counter) add() {
+	if c != nil {
+		c.n.Add(1)
+	}
+}
+
+// IdempotentMethod reports whether the request method is idempotent as defined by RFC 9110.
+func IdempotentMethod(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// jitter randomizes a backoff to between half of it and all of it, spreading out concurrent retries.
+var jitter = func(d time.Duration) time.Duration {
+	half := d / 2
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need a secure random source
+}
+
+// Transport is an http.RoundTripper that retries idempotent requests failing with a
+// connection error, 429 Too Many Requests or a 5xx status.
+type Transport struct {
+	next http.RoundTripper
+	cfg  Config
+}
+
+// NewTransport wraps next, or http.DefaultTransport if nil, with retries.
+func NewTransport(next http.RoundTripper, cfg Config) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if cfg.Idempotent == nil {
+		cfg.Idempotent = IdempotentMethod
+	}
+	return &Transport{next: next, cfg: cfg}
+}
+
+// WrapClient returns a copy of c whose transport retries according to cfg.
+func WrapClient(c *http.Client, cfg Config) *http.Client {
+	wrapped := *c
+	wrapped.Transport = NewTransport(c.Transport, cfg)
+	return &wrapped
+}
+
+// RoundTrip sends req, sending it again after a backoff while it fails transiently.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.retryable(req) {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // transparent transport
+	}
+
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(attemptReq)
+
+		delay, retry := t.retryDelay(ctx, attempt, resp, err)
+		if !retry {
+			return resp, err //nolint:wrapcheck // transparent transport
+		}
+		if resp != nil {
+			_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+			_ = resp.Body.Close()
+		}
+		t.cfg.Counter.add()
+
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+
+		attemptReq, err = rewind(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether req may be sent more than once.
+func (t *Transport) retryable(req *http.Request) bool {
+	if t.cfg.MaxRetries <= 0 || !t.cfg.Idempotent(req) {
+		return false
+	}
+	// a body that cannot be replayed was consumed by the first attempt
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryDelay returns how long to wait before the next attempt, and false if the outcome is final.
+func (t *Transport) retryDelay(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= t.cfg.MaxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return t.backoff(attempt), isTransient(err)
+	}
+
+	if !retryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+	if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return retryAfter, retryAfter <= t.cfg.MaxRetryAfter
+	}
+	return t.backoff(attempt), true
+}
+
+func (t *Transport) backoff(attempt int) time.Duration {
+	if t.cfg.MaxDelay <= 0 {
+		return jitter(t.cfg.BaseDelay)
+	}
+
+	delay := t.cfg.BaseDelay
+	for i := 0; i < attempt && delay < t.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	return jitter(min(delay, t.cfg.MaxDelay))
+}
+
+func retryableStatus(status int) bool {
+	if status == http.StatusTooManyRequests {
+		return true
+	}
+	return status >= http.StatusInternalServerError &&
+		status != http.StatusNotImplemented && status != http.StatusHTTPVersionNotSupported
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// ParseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func ParseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("canceled while waiting to retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewind returns a copy of req with a fresh body for the next attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, nil
+}
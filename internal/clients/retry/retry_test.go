@@ -0,0 +1,213 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(counter *Counter) Config {
+	return Config{
+		MaxRetries:    3,
+		BaseDelay:     time.Millisecond,
+		MaxDelay:      5 * time.Millisecond,
+		MaxRetryAfter: time.Second,
+		Counter:       counter,
+	}
+}
+
+// flakyServer fails the first failures requests with failure and answers 200 afterwards.
+func flakyServer(t *testing.T, failures int32, failure http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= failures {
+			failure(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &hits
+}
+
+func withStatus(status int, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func resetConnection(w http.ResponseWriter, _ *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func TestTransport_RetriesTransientFailures(t *testing.T) {
+	testCases := []struct {
+		failure http.HandlerFunc
+		desc    string
+	}{
+		{failure: withStatus(http.StatusServiceUnavailable), desc: "5xx"},
+		{failure: withStatus(http.StatusTooManyRequests), desc: "429"},
+		{failure: withStatus(http.StatusTooManyRequests, "Retry-After", "0"), desc: "429 with Retry-After"},
+		{failure: resetConnection, desc: "connection reset"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			server, hits := flakyServer(t, 2, tc.failure)
+			counter := &Counter{}
+			client := WrapClient(server.Client(), testConfig(counter))
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, int32(3), hits.Load())
+			assert.Equal(t, 2, counter.Load())
+		})
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	server, hits := flakyServer(t, 10, withStatus(http.StatusBadGateway))
+	counter := &Counter{}
+	client := WrapClient(server.Client(), testConfig(counter))
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(4), hits.Load())
+	assert.Equal(t, 3, counter.Load())
+}
+
+func TestTransport_DoesNotRetry(t *testing.T) {
+	testCases := []struct {
+		method  string
+		failure http.HandlerFunc
+		desc    string
+	}{
+		{method: http.MethodPost, failure: withStatus(http.StatusServiceUnavailable), desc: "non-idempotent method"},
+		{method: http.MethodGet, failure: withStatus(http.StatusBadRequest), desc: "client error"},
+		{method: http.MethodGet, failure: withStatus(http.StatusNotImplemented), desc: "not implemented"},
+		{method: http.MethodGet, failure: withStatus(http.StatusTooManyRequests, "Retry-After", "3600"), desc: "Retry-After too long"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			server, hits := flakyServer(t, 1, tc.failure)
+			counter := &Counter{}
+			client := WrapClient(server.Client(), testConfig(counter))
+
+			req, err := http.NewRequestWithContext(t.Context(), tc.method, server.URL, http.NoBody)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, int32(1), hits.Load())
+			assert.Equal(t, 0, counter.Load())
+		})
+	}
+}
+
+func TestTransport_ReplaysBody(t *testing.T) {
+	server, hits := flakyServer(t, 1, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	cfg := testConfig(nil)
+	cfg.Idempotent = func(*http.Request) bool { return true }
+	client := WrapClient(server.Client(), cfg)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestTransport_StopsWhenContextIsCanceled(t *testing.T) {
+	server, hits := flakyServer(t, 10, withStatus(http.StatusServiceUnavailable))
+	cfg := testConfig(nil)
+	cfg.BaseDelay = time.Hour
+	cfg.MaxDelay = time.Hour
+	client := WrapClient(server.Client(), cfg)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, http.NoBody)
+	require.NoError(t, err)
+
+	_, err = client.Do(req) //nolint:bodyclose // no response is returned
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestTransport_Backoff(t *testing.T) {
+	original := jitter
+	jitter = func(d time.Duration) time.Duration { return d }
+	t.Cleanup(func() { jitter = original })
+
+	transport := NewTransport(nil, Config{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	assert.Equal(t, 100*time.Millisecond, transport.backoff(0))
+	assert.Equal(t, 200*time.Millisecond, transport.backoff(1))
+	assert.Equal(t, 800*time.Millisecond, transport.backoff(3))
+	assert.Equal(t, time.Second, transport.backoff(4), "the backoff is capped")
+	assert.Equal(t, time.Second, transport.backoff(80), "overflowing backoffs are capped")
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := ParseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = ParseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), delay.Seconds(), 5)
+
+	delay, ok = ParseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Zero(t, delay)
+
+	_, ok = ParseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = ParseRetryAfter("soon")
+	assert.False(t, ok)
+}
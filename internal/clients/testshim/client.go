@@ -4,41 +4,65 @@ package testshim
 import (
 	"context"
 	"fmt"
-	"time"
 
+	"github.com/google/uuid"
 	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
 	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/workflow"
 
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
 	"github.com/snyk/cli-extension-secrets/internal/clients/snykclient"
 )
 
 // Client interface for the test shim API.
 type Client interface {
 	StartTest(ctx context.Context, params testapi.StartTestParams) (testapi.TestHandle, error)
+	// SubmitTest creates a test without waiting for it and returns the ID of the test job.
+	SubmitTest(ctx context.Context, params testapi.StartTestParams) (uuid.UUID, error)
+	// GetTestResult returns the result of a test given its test job ID or test ID,
+	// waiting for the test to complete if it is still running.
+	GetTestResult(ctx context.Context, orgID string, id uuid.UUID) (testapi.TestResult, error)
 }
 
-// TestAPIClient wraps the test shim API client.
+// TestAPIClient wraps the test shim API client. Tests are started and polled by the test client of the framework,
+// paced by a pollSchedule; tests that were not started by this client are read through the low-level client.
 type TestAPIClient struct {
 	testapi.TestClient
+	lowLevelClient *testapi.ClientWithResponses
+	// newPoller paces the polling of each test job retrieved by GetTestResult.
+	newPoller func() Poller
 }
 
 // NewClient creates a new TestAPIClient from the given invocation context.
-func NewClient(ictx workflow.InvocationContext) (*TestAPIClient, error) {
+// Only idempotent requests, such as polling a test, are retried; creating a test is not.
+func NewClient(ictx workflow.InvocationContext, retryConfig retry.Config, pollConfig PollConfig) (*TestAPIClient, error) {
 	config := ictx.GetConfiguration()
-	httpClient := ictx.GetNetworkAccess().GetHttpClient()
+	httpClient := retry.WrapClient(ictx.GetNetworkAccess().GetHttpClient(), retryConfig)
 	snykClient := snykclient.NewSnykClient(httpClient, config.GetString(configuration.API_URL), config.GetString(configuration.ORGANIZATION))
 
-	testShimClient, err := testapi.NewTestClient(
-		snykClient.GetAPIBaseURL(),
-		testapi.WithPollInterval(2*time.Second),
-		testapi.WithCustomHTTPClient(snykClient.GetClient()),
+	return newTestAPIClient(snykClient.GetAPIBaseURL(), snykClient.GetClient(), pollConfig)
+}
+
+func newTestAPIClient(baseURL string, doer testapi.HttpRequestDoer, pollConfig PollConfig) (*TestAPIClient, error) {
+	schedule := newPollSchedule(pollConfig, doer)
+	testClient, err := testapi.NewTestClient(
+		baseURL,
+		testapi.WithPollInterval(pollConfig.Interval),
+		testapi.WithJitterFunc(schedule.next),
+		testapi.WithCustomHTTPClient(schedule),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create test API client: %w", err)
 	}
 
+	lowLevelClient, err := testapi.NewClientWithResponses(baseURL, testapi.WithHTTPClient(doer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create test API client: %w", err)
+	}
+
 	return &TestAPIClient{
-		testShimClient,
+		TestClient:     testClient,
+		lowLevelClient: lowLevelClient,
+		newPoller:      func() Poller { return NewAdaptivePoller(pollConfig) },
 	}, nil
 }
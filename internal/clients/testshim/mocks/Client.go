@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: client.go
+// Source: internal/clients/testshim/client.go
 
 // Package mock_testshim is a generated GoMock package.
 package mock_testshim
@@ -7,8 +7,9 @@ package mock_testshim
 import (
 	context "context"
 	reflect "reflect"
-	gomock "github.com/golang/mock/gomock"
 
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	testapi "github.com/snyk/go-application-framework/pkg/apiclients/testapi"
 )
 
@@ -35,6 +36,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// GetTestResult mocks base method.
+func (m *MockClient) GetTestResult(ctx context.Context, orgID string, id uuid.UUID) (testapi.TestResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTestResult", ctx, orgID, id)
+	ret0, _ := ret[0].(testapi.TestResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTestResult indicates an expected call of GetTestResult.
+func (mr *MockClientMockRecorder) GetTestResult(ctx, orgID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTestResult", reflect.TypeOf((*MockClient)(nil).GetTestResult), ctx, orgID, id)
+}
+
 // StartTest mocks base method.
 func (m *MockClient) StartTest(ctx context.Context, params testapi.StartTestParams) (testapi.TestHandle, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +65,18 @@ func (mr *MockClientMockRecorder) StartTest(ctx, params interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTest", reflect.TypeOf((*MockClient)(nil).StartTest), ctx, params)
 }
+
+// SubmitTest mocks base method.
+func (m *MockClient) SubmitTest(ctx context.Context, params testapi.StartTestParams) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitTest", ctx, params)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitTest indicates an expected call of SubmitTest.
+func (mr *MockClientMockRecorder) SubmitTest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitTest", reflect.TypeOf((*MockClient)(nil).SubmitTest), ctx, params)
+}
@@ -0,0 +1,125 @@
+package testshim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+)
+
+// Default bounds of the adaptive polling of test jobs.
+const (
+	DefaultPollInterval    = testapi.MinPollInterval
+	MinPollInterval        = testapi.MinPollInterval
+	DefaultMaxPollInterval = 10 * time.Second
+	pollGrowthFactor       = 1.5
+)
+
+// PollConfig bounds the interval between two polls of a running test job.
+type PollConfig struct {
+	// Interval is the delay before the first poll, growing with every further poll.
+	Interval time.Duration
+	// MaxInterval caps the delay between two polls, including delays suggested by the server.
+	MaxInterval time.Duration
+}
+
+// DefaultPollConfig returns the polling bounds used unless overridden.
+func DefaultPollConfig() PollConfig {
+	return PollConfig{Interval: DefaultPollInterval, MaxInterval: DefaultMaxPollInterval}
+}
+
+// Poller paces the polling of a single test job.
+type Poller interface {
+	// Wait blocks until the next poll is due. hint is the delay suggested by the server, or zero.
+	Wait(ctx context.Context, hint time.Duration) error
+}
+
+// adaptivePoller polls quickly at first so that small scans finish fast, then backs off
+// exponentially up to a cap so that long scans do not hammer the API.
+type adaptivePoller struct {
+	cfg  PollConfig
+	next time.Duration
+}
+
+// NewAdaptivePoller creates a Poller growing the interval from cfg.Interval to cfg.MaxInterval.
+//
+//nolint:ireturn // a poller is created per test job by the client
+func NewAdaptivePoller(cfg PollConfig) Poller {
+	return newAdaptivePoller(cfg)
+}
+
+func newAdaptivePoller(cfg PollConfig) *adaptivePoller {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultPollInterval
+	}
+	if cfg.MaxInterval < cfg.Interval {
+		cfg.MaxInterval = cfg.Interval
+	}
+	return &adaptivePoller{cfg: cfg, next: cfg.Interval}
+}
+
+// Wait sleeps for the next interval, or until ctx ends.
+func (p *adaptivePoller) Wait(ctx context.Context, hint time.Duration) error {
+	timer := time.NewTimer(p.interval(hint))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("canceled while polling: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// interval returns the delay before the next poll and grows the one after it.
+func (p *adaptivePoller) interval(hint time.Duration) time.Duration {
+	d := p.next
+	if hint > 0 {
+		d = min(hint, p.cfg.MaxInterval)
+	}
+
+	p.next = min(time.Duration(float64(d)*pollGrowthFactor), p.cfg.MaxInterval)
+	return d
+}
+
+// pollSchedule paces the polling of the test client of the framework, which waits the poll interval before the first
+// poll and asks its jitter function for the delay before every further poll. As the request doer of the client, it
+// also picks up the delays asked for by the server. A client polling several tests at once shares its schedule.
+type pollSchedule struct {
+	doer testapi.HttpRequestDoer
+
+	mu     sync.Mutex
+	poller *adaptivePoller
+	hint   time.Duration
+}
+
+func newPollSchedule(cfg PollConfig, doer testapi.HttpRequestDoer) *pollSchedule {
+	poller := newAdaptivePoller(cfg)
+	// the first poll is paced by the poll interval of the client
+	poller.interval(0)
+	return &pollSchedule{doer: doer, poller: poller}
+}
+
+// next returns the delay before the next poll, ignoring the poll interval of the client.
+func (s *pollSchedule) next(time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hint := s.hint
+	s.hint = 0
+	return s.poller.interval(hint)
+}
+
+// Do sends req, recording the delay asked for by the response with Retry-After for the next poll.
+func (s *pollSchedule) Do(req *http.Request) (*http.Response, error) {
+	resp, err := s.doer.Do(req)
+	if hint := pollHint(resp); err == nil && hint > 0 {
+		s.mu.Lock()
+		s.hint = hint
+		s.mu.Unlock()
+	}
+	return resp, err //nolint:wrapcheck // transparent doer
+}
@@ -0,0 +1,181 @@
+package testshim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptivePoller_Interval(t *testing.T) {
+	poller, ok := NewAdaptivePoller(PollConfig{Interval: 100 * time.Millisecond, MaxInterval: time.Second}).(*adaptivePoller)
+	require.True(t, ok)
+
+	var intervals []time.Duration
+	for range 8 {
+		intervals = append(intervals, poller.interval(0))
+	}
+	assert.Equal(t, []time.Duration{
+		100 * time.Millisecond,
+		150 * time.Millisecond,
+		225 * time.Millisecond,
+		337500 * time.Microsecond,
+		506250 * time.Microsecond,
+		759375 * time.Microsecond,
+		time.Second,
+		time.Second,
+	}, intervals)
+}
+
+func TestAdaptivePoller_ServerHint(t *testing.T) {
+	poller, ok := NewAdaptivePoller(PollConfig{Interval: 100 * time.Millisecond, MaxInterval: 10 * time.Second}).(*adaptivePoller)
+	require.True(t, ok)
+
+	assert.Equal(t, 2*time.Second, poller.interval(2*time.Second), "the hint is respected")
+	assert.Equal(t, 3*time.Second, poller.interval(0), "the interval grows from the hint")
+	assert.Equal(t, 10*time.Second, poller.interval(time.Hour), "the hint is capped")
+}
+
+func TestAdaptivePoller_Defaults(t *testing.T) {
+	poller, ok := NewAdaptivePoller(PollConfig{MaxInterval: time.Millisecond}).(*adaptivePoller)
+	require.True(t, ok)
+	assert.Equal(t, PollConfig{Interval: DefaultPollInterval, MaxInterval: DefaultPollInterval}, poller.cfg)
+}
+
+func TestAdaptivePoller_WaitStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := NewAdaptivePoller(PollConfig{Interval: time.Hour}).Wait(ctx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// newStartTestParams returns the parameters of a test of a base resource, as the test client requires one.
+func newStartTestParams(t *testing.T, orgID uuid.UUID) testapi.StartTestParams {
+	t.Helper()
+
+	var baseResource testapi.BaseResourceCreateItem
+	baseResource.Type = testapi.BaseResourceCreateItemTypeBase
+	require.NoError(t, baseResource.Resource.FromUploadResource(testapi.UploadResource{
+		ContentType: testapi.UploadResourceContentTypeSource,
+		RevisionId:  uuid.NewString(),
+	}))
+	var resource testapi.TestResourceCreateItem
+	require.NoError(t, resource.FromBaseResourceCreateItem(baseResource))
+
+	resources := []testapi.TestResourceCreateItem{resource}
+	return testapi.NewStartTestParamsFromResources(orgID.String(), &resources, nil)
+}
+
+// doerFunc is a testapi.HttpRequestDoer calling a function.
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestPollSchedule(t *testing.T) {
+	retryAfter := ""
+	schedule := newPollSchedule(PollConfig{Interval: time.Second, MaxInterval: 10 * time.Second}, doerFunc(func(*http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}
+		resp.Header.Set("Retry-After", retryAfter)
+		return resp, nil
+	}))
+	doRequest := func() {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.snyk.io", http.NoBody)
+		require.NoError(t, err)
+		resp, err := schedule.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	// the client waits the poll interval before the first poll
+	assert.Equal(t, 1500*time.Millisecond, schedule.next(testapi.DefaultPollInterval), "the interval of the client is ignored")
+	doRequest()
+	assert.Equal(t, 2250*time.Millisecond, schedule.next(0))
+
+	retryAfter = "5"
+	doRequest()
+	assert.Equal(t, 5*time.Second, schedule.next(0), "the server hint is respected")
+	assert.Equal(t, 7500*time.Millisecond, schedule.next(0), "the hint applies to the next poll only")
+}
+
+func TestTestAPIClient_StartTest_LongRunningScan(t *testing.T) {
+	const pendingPolls = 5
+
+	orgID := uuid.New()
+	jobID := uuid.New()
+	testID := uuid.New()
+
+	var jobPolls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests", orgID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusAccepted, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"pending","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			jobID))
+	})
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/test_jobs/%s", orgID, jobID), func(w http.ResponseWriter, _ *http.Request) {
+		switch n := jobPolls.Add(1); {
+		case n == 1:
+			// the job may not be visible right after its creation
+			writeJSONAPI(w, http.StatusNotFound, `{"errors":[]}`)
+		case n <= pendingPolls:
+			writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+				`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"started","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+				jobID))
+		default:
+			writeJSONAPI(w, http.StatusSeeOther, fmt.Sprintf(
+				`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"finished","created_at":"2024-01-01T00:00:00Z"},`+
+					`"relationships":{"test":{"data":{"id":%q,"type":"tests"}}}},"jsonapi":{"version":"1.0"},"links":{}}`,
+				jobID, testID))
+		}
+	})
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests/%s", orgID, testID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"tests","attributes":{"state":{"execution":"finished"}}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			testID))
+	})
+
+	client := newTestClient(t, mux)
+
+	handle, err := client.StartTest(t.Context(), newStartTestParams(t, orgID))
+	require.NoError(t, err)
+	assert.Nil(t, handle.Result(), "no result before the test completes")
+
+	require.NoError(t, handle.Wait(t.Context()))
+	<-handle.Done()
+
+	assert.Equal(t, testID, *handle.Result().GetTestID())
+	assert.Equal(t, int32(pendingPolls+1), jobPolls.Load())
+}
+
+func TestTestAPIClient_StartTest_WaitCanceled(t *testing.T) {
+	orgID := uuid.New()
+	jobID := uuid.New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests", orgID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusAccepted, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"pending","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			jobID))
+	})
+	client := newTestClient(t, mux)
+
+	handle, err := client.StartTest(t.Context(), newStartTestParams(t, orgID))
+	require.NoError(t, err)
+
+	// canceled before the first poll
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	err = handle.Wait(ctx)
+	var snykErr snyk_errors.Error
+	require.ErrorAs(t, err, &snykErr)
+	assert.Contains(t, snykErr.Detail, context.DeadlineExceeded.Error())
+	assert.Nil(t, handle.Result())
+}
@@ -0,0 +1,361 @@
+package testshim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+)
+
+// Errors returned while retrieving test results.
+var (
+	ErrInvalidTestParams = errors.New("invalid test parameters")
+	ErrTestJobErrored    = errors.New("test job errored")
+	ErrUnexpectedStatus  = errors.New("unexpected response status")
+)
+
+// Resources a StatusError can be about, telling apart what a 404 response did not find.
+const (
+	ResourceOrg  = "org"
+	ResourceTest = "test"
+)
+
+// StatusError is an unexpected test API response about Resource, along with the errors its body reported.
+type StatusError struct {
+	StatusCode int
+	Resource   string
+	Errors     []testapi.IoSnykApiCommonError
+	op         string
+}
+
+// newStatusError returns a StatusError for a response with statusCode to op on resource, decoding the errors of body
+// if any.
+func newStatusError(statusCode int, resource string, body []byte, op string) *StatusError {
+	statusErr := &StatusError{StatusCode: statusCode, Resource: resource, op: op}
+	var document testapi.IoSnykApiCommonErrorDocument
+	if err := json.Unmarshal(body, &document); err == nil {
+		statusErr.Errors = document.Errors
+	}
+	// otherwise not a JSON:API error document, only the status is known
+	return statusErr
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %d %s", ErrUnexpectedStatus, e.StatusCode, e.op)
+}
+
+// Unwrap makes StatusError match ErrUnexpectedStatus.
+func (e *StatusError) Unwrap() error {
+	return ErrUnexpectedStatus
+}
+
+// SubmitTest creates a test and returns the ID of its job without waiting for the test to complete.
+func (c *TestAPIClient) SubmitTest(ctx context.Context, params testapi.StartTestParams) (uuid.UUID, error) {
+	orgID, err := uuid.Parse(params.OrgID())
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: org ID %q is not a valid UUID", ErrInvalidTestParams, params.OrgID())
+	}
+
+	body := testapi.TestRequestBody{
+		Data: testapi.TestDataCreate{
+			Attributes: testapi.TestAttributesCreate{
+				Subject:   params.Subject(),
+				Resources: params.Resources(),
+				Config:    params.TestConfig(),
+			},
+			Type: testapi.Tests,
+		},
+	}
+
+	resp, err := c.lowLevelClient.CreateTestWithApplicationVndAPIPlusJSONBodyWithResponse(
+		ctx, orgID, &testapi.CreateTestParams{Version: testapi.DefaultAPIVersion}, body)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to send create test request: %w", err)
+	}
+	if resp.ApplicationvndApiJSON202 == nil || resp.ApplicationvndApiJSON202.Data.Id == uuid.Nil {
+		return uuid.Nil, newStatusError(resp.StatusCode(), ResourceOrg, resp.Body, "creating test")
+	}
+
+	return resp.ApplicationvndApiJSON202.Data.Id, nil
+}
+
+// GetTestResult resolves id as a test job, waiting for it to complete, and falls back to
+// treating it as a test ID when no such job exists.
+//
+//nolint:ireturn // supposed to return interface.
+func (c *TestAPIClient) GetTestResult(ctx context.Context, orgID string, id uuid.UUID) (testapi.TestResult, error) {
+	orgUUID, err := uuid.Parse(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: org ID %q is not a valid UUID", ErrInvalidTestParams, orgID)
+	}
+
+	testID, err := c.resolveTestID(ctx, orgUUID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.lowLevelClient.GetTestWithResponse(ctx, orgUUID, testID, &testapi.GetTestParams{Version: testapi.DefaultAPIVersion})
+	if err != nil {
+		return nil, fmt.Errorf("get test request failed (testID: %s): %w", testID, err)
+	}
+	if resp.ApplicationvndApiJSON200 == nil {
+		return nil, newStatusError(resp.StatusCode(), ResourceTest, resp.Body, "fetching test "+testID.String())
+	}
+
+	return newFetchedTestResult(c.lowLevelClient, orgUUID, &resp.ApplicationvndApiJSON200.Data), nil
+}
+
+func (c *TestAPIClient) resolveTestID(ctx context.Context, orgID, id uuid.UUID) (uuid.UUID, error) {
+	params := &testapi.GetJobParams{Version: testapi.DefaultAPIVersion}
+	poller := c.newPoller()
+
+	for {
+		resp, err := c.lowLevelClient.GetJobWithResponse(ctx, orgID, id, params)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("get test job request failed (jobID: %s): %w", id, err)
+		}
+
+		switch resp.StatusCode() {
+		case http.StatusSeeOther:
+			if resp.ApplicationvndApiJSON303 == nil || resp.ApplicationvndApiJSON303.Data.Relationships == nil ||
+				resp.ApplicationvndApiJSON303.Data.Relationships.Test.Data.Id == uuid.Nil {
+				return uuid.Nil, fmt.Errorf("test job %s completed without a test ID", id)
+			}
+			return resp.ApplicationvndApiJSON303.Data.Relationships.Test.Data.Id, nil
+
+		case http.StatusNotFound:
+			// not a job, the ID refers to the test itself
+			return id, nil
+
+		case http.StatusOK:
+			if resp.ApplicationvndApiJSON200 != nil &&
+				resp.ApplicationvndApiJSON200.Data.Attributes.Status == testapi.TestExecutionStatesErrored {
+				return uuid.Nil, fmt.Errorf("%w (jobID: %s)", ErrTestJobErrored, id)
+			}
+
+		default:
+			return uuid.Nil, newStatusError(resp.StatusCode(), ResourceTest, resp.Body, "polling test job "+id.String())
+		}
+
+		if err := poller.Wait(ctx, pollHint(resp.HTTPResponse)); err != nil {
+			return uuid.Nil, fmt.Errorf("stopped waiting for test job %s: %w", id, err)
+		}
+	}
+}
+
+// pollHint returns the delay the server asked for with Retry-After, or zero.
+func pollHint(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	hint, _ := retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	return hint
+}
+
+// fetchedTestResult is a testapi.TestResult read through the low-level test API client.
+type fetchedTestResult struct {
+	client *testapi.ClientWithResponses
+	orgID  uuid.UUID
+	data   *testapi.TestData
+
+	metadataMu sync.Mutex
+	metadata   map[string]interface{}
+
+	findingsOnce     sync.Once
+	findings         []testapi.FindingData
+	findingsComplete bool
+	findingsErr      error
+}
+
+func newFetchedTestResult(client *testapi.ClientWithResponses, orgID uuid.UUID, data *testapi.TestData) *fetchedTestResult {
+	return &fetchedTestResult{
+		client:   client,
+		orgID:    orgID,
+		data:     data,
+		metadata: map[string]interface{}{},
+	}
+}
+
+func (r *fetchedTestResult) GetTestID() *uuid.UUID { return r.data.Id }
+
+func (r *fetchedTestResult) GetTestConfiguration() *testapi.TestConfiguration {
+	return r.data.Attributes.Config
+}
+
+func (r *fetchedTestResult) GetCreatedAt() *time.Time { return r.data.Attributes.CreatedAt }
+
+func (r *fetchedTestResult) Get(key testapi.TestResultKeys) interface{} {
+	switch key {
+	case testapi.TestResultTestSubject:
+		return r.GetTestSubject()
+	case testapi.TestResultSubjectLocators:
+		return r.GetSubjectLocators()
+	case testapi.TestResultTestResources:
+		return r.GetTestResources()
+	case testapi.TestResultRawSummary:
+		return r.GetRawSummary()
+	case testapi.TestResultTestFacts:
+		return r.GetTestFacts()
+	case testapi.TestResultBreachedPolicies:
+		return r.GetBreachedPolicies()
+	case testapi.TestResultMetadata:
+		return r.GetMetadata()
+	case testapi.TestResultComponents:
+		return r.data.Attributes.Components
+	default:
+		return nil
+	}
+}
+
+func (r *fetchedTestResult) GetTestSubject() *testapi.TestSubject {
+	return r.data.Attributes.Subject
+}
+
+func (r *fetchedTestResult) GetSubjectLocators() *[]testapi.TestSubjectLocator {
+	return r.data.Attributes.SubjectLocators
+}
+
+func (r *fetchedTestResult) GetTestResources() *[]testapi.TestResource {
+	return r.data.Attributes.Resources
+}
+
+func (r *fetchedTestResult) GetExecutionState() testapi.TestExecutionStates {
+	if r.data.Attributes.State == nil {
+		return testapi.TestExecutionStates("unknown")
+	}
+	return r.data.Attributes.State.Execution
+}
+
+func (r *fetchedTestResult) GetErrors() *[]testapi.IoSnykApiCommonError {
+	if r.data.Attributes.State == nil {
+		return nil
+	}
+	return r.data.Attributes.State.Errors
+}
+
+func (r *fetchedTestResult) GetWarnings() *[]testapi.IoSnykApiCommonError {
+	if r.data.Attributes.State == nil {
+		return nil
+	}
+	return r.data.Attributes.State.Warnings
+}
+
+func (r *fetchedTestResult) GetPassFail() *testapi.PassFail {
+	if r.data.Attributes.Outcome == nil {
+		return nil
+	}
+	return &r.data.Attributes.Outcome.Result
+}
+
+func (r *fetchedTestResult) GetOutcomeReason() *testapi.TestOutcomeReason {
+	if r.data.Attributes.Outcome == nil {
+		return nil
+	}
+	return r.data.Attributes.Outcome.Reason
+}
+
+func (r *fetchedTestResult) GetBreachedPolicies() *testapi.PolicyRefSet {
+	if r.data.Attributes.Outcome == nil {
+		return nil
+	}
+	return r.data.Attributes.Outcome.BreachedPolicies
+}
+
+func (r *fetchedTestResult) GetEffectiveSummary() *testapi.FindingSummary {
+	return r.data.Attributes.EffectiveSummary
+}
+
+func (r *fetchedTestResult) GetRawSummary() *testapi.FindingSummary {
+	return r.data.Attributes.RawSummary
+}
+
+func (r *fetchedTestResult) GetTestFacts() *[]testapi.TestFact { return r.data.Attributes.TestFacts }
+
+func (r *fetchedTestResult) SetMetadata(key string, value interface{}) {
+	r.metadataMu.Lock()
+	defer r.metadataMu.Unlock()
+	r.metadata[key] = value
+}
+
+func (r *fetchedTestResult) GetMetadataValue(key string) interface{} {
+	r.metadataMu.Lock()
+	defer r.metadataMu.Unlock()
+	return r.metadata[key]
+}
+
+func (r *fetchedTestResult) GetMetadata() map[string]interface{} {
+	r.metadataMu.Lock()
+	defer r.metadataMu.Unlock()
+	return r.metadata
+}
+
+// Findings fetches all pages of findings on the first call and returns the cached data afterwards.
+func (r *fetchedTestResult) Findings(ctx context.Context) (resultFindings []testapi.FindingData, complete bool, err error) {
+	r.findingsOnce.Do(func() {
+		r.findings, r.findingsComplete, r.findingsErr = r.fetchFindings(ctx)
+	})
+	return r.findings, r.findingsComplete, r.findingsErr
+}
+
+func (r *fetchedTestResult) fetchFindings(ctx context.Context) ([]testapi.FindingData, bool, error) {
+	if r.data.Id == nil {
+		return nil, false, testapi.ErrInvalidStateForFindings
+	}
+
+	limit := int8(testapi.MaxFindingsPerPage)
+	params := &testapi.ListFindingsParams{Version: testapi.DefaultAPIVersion, Limit: &limit}
+	findings := []testapi.FindingData{}
+
+	for {
+		resp, err := r.client.ListFindingsWithResponse(ctx, r.orgID, *r.data.Id, params)
+		if err != nil {
+			return findings, false, fmt.Errorf("%w: %w", testapi.ErrFindingsPageRequest, err)
+		}
+		if resp.ApplicationvndApiJSON200 == nil {
+			return findings, false, testapi.ErrFindingsPageResponse
+		}
+
+		page := resp.ApplicationvndApiJSON200
+		findings = append(findings, page.Data...)
+		if page.Links.Next == nil {
+			return findings, true, nil
+		}
+
+		cursor, err := nextCursor(page.Links.Next)
+		if err != nil {
+			return findings, false, err
+		}
+		params.StartingAfter = &cursor
+	}
+}
+
+// nextCursor extracts the starting_after cursor from a pagination link.
+func nextCursor(link *testapi.IoSnykApiCommonLinkProperty) (string, error) {
+	href, err := link.AsIoSnykApiCommonLinkString()
+	if err != nil {
+		linkObj, objErr := link.AsIoSnykApiCommonLinkObject()
+		if objErr != nil {
+			return "", testapi.ErrFindingsNextPageCursor
+		}
+		href = linkObj.Href
+	}
+
+	parsed, err := url.Parse(href)
+	if err != nil {
+		return "", testapi.ErrFindingsNextPageCursor
+	}
+	cursor := parsed.Query().Get("starting_after")
+	if cursor == "" {
+		return "", testapi.ErrFindingsNextPageCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,202 @@
+package testshim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/snykclient"
+)
+
+const jsonAPIContentType = "application/vnd.api+json"
+
+func newTestClient(t *testing.T, handler http.Handler) *TestAPIClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	snykClient := snykclient.NewSnykClient(server.Client(), server.URL, "")
+	// the test client waits at least MinPollInterval before the first poll, and 1ms before every further one
+	client, err := newTestAPIClient(server.URL, snykClient.GetClient(), PollConfig{Interval: time.Millisecond, MaxInterval: time.Millisecond})
+	require.NoError(t, err)
+	client.newPoller = func() Poller { return &fakePoller{} }
+
+	return client
+}
+
+// fakePoller polls without waiting.
+type fakePoller struct{}
+
+func (p *fakePoller) Wait(ctx context.Context, _ time.Duration) error {
+	return ctx.Err()
+}
+
+func writeJSONAPI(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", jsonAPIContentType)
+	w.WriteHeader(status)
+	_, _ = fmt.Fprint(w, body)
+}
+
+func TestTestAPIClient_SubmitTest(t *testing.T) {
+	orgID := uuid.New()
+	jobID := uuid.New()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, fmt.Sprintf("/orgs/%s/tests", orgID), r.URL.Path)
+		writeJSONAPI(w, http.StatusAccepted, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"pending","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			jobID))
+	}))
+
+	resources := []testapi.TestResourceCreateItem{}
+	params := testapi.NewStartTestParamsFromResources(orgID.String(), &resources, &testapi.TestConfiguration{})
+
+	id, err := client.SubmitTest(t.Context(), params)
+	require.NoError(t, err)
+	assert.Equal(t, jobID, id)
+}
+
+func TestTestAPIClient_SubmitTest_Errors(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusForbidden,
+			`{"errors":[{"code":"SNYK-CLI-0016","detail":"secrets testing is not entitled","status":"403"}],"jsonapi":{"version":"1.0"}}`)
+	}))
+
+	resources := []testapi.TestResourceCreateItem{}
+
+	_, err := client.SubmitTest(t.Context(), testapi.NewStartTestParamsFromResources("my-org", &resources, nil))
+	assert.ErrorIs(t, err, ErrInvalidTestParams)
+
+	_, err = client.SubmitTest(t.Context(), testapi.NewStartTestParamsFromResources(uuid.NewString(), &resources, nil))
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.EqualError(t, err, "unexpected response status 403 creating test")
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusForbidden, statusErr.StatusCode)
+	require.Len(t, statusErr.Errors, 1)
+	assert.Equal(t, "secrets testing is not entitled", statusErr.Errors[0].Detail)
+}
+
+func TestTestAPIClient_GetTestResult(t *testing.T) {
+	orgID := uuid.New()
+	jobID := uuid.New()
+	testID := uuid.New()
+	findingIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	var jobPolls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/test_jobs/%s", orgID, jobID), func(w http.ResponseWriter, _ *http.Request) {
+		if jobPolls.Add(1) < 3 {
+			writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+				`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"started","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+				jobID))
+			return
+		}
+		writeJSONAPI(w, http.StatusSeeOther, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"finished","created_at":"2024-01-01T00:00:00Z"},`+
+				`"relationships":{"test":{"data":{"id":%q,"type":"tests"}}}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			jobID, testID))
+	})
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests/%s", orgID, testID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"tests","attributes":{"state":{"execution":"finished"},"outcome":{"result":"fail"}}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			testID))
+	})
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests/%s/findings", orgID, testID), func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("starting_after") == "" {
+			writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+				`{"data":[{"id":%q,"type":"findings"}],"jsonapi":{"version":"1.0"},"links":{"next":"/findings?starting_after=cursor"}}`,
+				findingIDs[0]))
+			return
+		}
+		writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+			`{"data":[{"id":%q,"type":"findings"}],"jsonapi":{"version":"1.0"},"links":{}}`,
+			findingIDs[1]))
+	})
+	client := newTestClient(t, mux)
+
+	result, err := client.GetTestResult(t.Context(), orgID.String(), jobID)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(3), jobPolls.Load(), "the job should be polled until it completes")
+	assert.Equal(t, testID, *result.GetTestID())
+	assert.Equal(t, testapi.TestExecutionStatesFinished, result.GetExecutionState())
+	assert.Equal(t, testapi.Fail, *result.GetPassFail())
+
+	findings, complete, err := result.Findings(t.Context())
+	require.NoError(t, err)
+	assert.True(t, complete)
+	require.Len(t, findings, 2)
+	assert.Equal(t, findingIDs[0], *findings[0].Id)
+	assert.Equal(t, findingIDs[1], *findings[1].Id)
+
+	result.SetMetadata("key", "value")
+	assert.Equal(t, "value", result.GetMetadataValue("key"))
+}
+
+func TestTestAPIClient_GetTestResult_ByTestID(t *testing.T) {
+	orgID := uuid.New()
+	testID := uuid.New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/test_jobs/%s", orgID, testID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusNotFound, `{"errors":[]}`)
+	})
+	mux.HandleFunc(fmt.Sprintf("/orgs/%s/tests/%s", orgID, testID), func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"tests","attributes":{"state":{"execution":"finished"}}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			testID))
+	})
+	client := newTestClient(t, mux)
+
+	result, err := client.GetTestResult(t.Context(), orgID.String(), testID)
+	require.NoError(t, err)
+	assert.Equal(t, testID, *result.GetTestID())
+	assert.Nil(t, result.GetPassFail())
+}
+
+func TestTestAPIClient_GetTestResult_JobErrored(t *testing.T) {
+	orgID := uuid.New()
+	jobID := uuid.New()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONAPI(w, http.StatusOK, fmt.Sprintf(
+			`{"data":{"id":%q,"type":"test_jobs","attributes":{"status":"errored","created_at":"2024-01-01T00:00:00Z"}},"jsonapi":{"version":"1.0"},"links":{}}`,
+			jobID))
+	}))
+
+	_, err := client.GetTestResult(t.Context(), orgID.String(), jobID)
+	assert.ErrorIs(t, err, ErrTestJobErrored)
+}
+
+func TestTestAPIClient_GetTestResult_NotFound(t *testing.T) {
+	orgID := uuid.New()
+	id := uuid.New()
+
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// neither a test job nor a test
+		writeJSONAPI(w, http.StatusNotFound,
+			`{"errors":[{"status":"404","code":"SNYK-OPENAPI-0004","detail":"test not found"}],"jsonapi":{"version":"1.0"}}`)
+	}))
+
+	_, err := client.GetTestResult(t.Context(), orgID.String(), id)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	assert.Equal(t, ResourceTest, statusErr.Resource)
+	require.Len(t, statusErr.Errors, 1)
+	assert.Equal(t, "test not found", statusErr.Errors[0].Detail)
+}
@@ -0,0 +1,63 @@
+package upload
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// ByteCounter counts the request body bytes sent by file uploads, including retried attempts.
+type ByteCounter struct {
+	n atomic.Int64
+}
+
+// Load returns the number of bytes sent.
+func (c *ByteCounter) Load() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.n.Load()
+}
+
+// countBytes returns a copy of c counting the bytes of the file uploads it sends into counter.
+func countBytes(c *http.Client, counter *ByteCounter) *http.Client {
+	if counter == nil {
+		return c
+	}
+
+	next := c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	wrapped := *c
+	wrapped.Transport = &countingTransport{next: next, counter: counter}
+	return &wrapped
+}
+
+type countingTransport struct {
+	next    http.RoundTripper
+	counter *ByteCounter
+}
+
+// RoundTrip sends req, counting the bytes of its body as the underlying transport reads them.
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || req.Body == nil || req.Body == http.NoBody ||
+		!uploadFilesPath.MatchString(req.URL.Path) {
+		return t.next.RoundTrip(req) //nolint:wrapcheck // transparent transport
+	}
+
+	countedReq := req.Clone(req.Context())
+	countedReq.Body = &countingReader{ReadCloser: req.Body, counter: t.counter}
+	return t.next.RoundTrip(countedReq) //nolint:wrapcheck // transparent transport
+}
+
+type countingReader struct {
+	io.ReadCloser
+	counter *ByteCounter
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.counter.n.Add(int64(n))
+	return n, err //nolint:wrapcheck // transparent reader
+}
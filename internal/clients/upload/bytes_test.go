@@ -0,0 +1,45 @@
+package upload
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	counter := &ByteCounter{}
+	client := countBytes(server.Client(), counter)
+
+	post := func(path, body string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	post("/hidden/orgs/org/upload_revisions/rev/files", "0123456789")
+	post("/hidden/orgs/org/upload_revisions/rev/files", "01234")
+	post("/hidden/orgs/org/upload_revisions", "not a file upload")
+
+	assert.Equal(t, int64(15), counter.Load())
+}
+
+func TestCountBytes_NilCounter(t *testing.T) {
+	client := &http.Client{}
+
+	assert.Same(t, client, countBytes(client, nil))
+	assert.Zero(t, (*ByteCounter)(nil).Load())
+}
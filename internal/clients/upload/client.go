@@ -3,12 +3,17 @@ package upload
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"regexp"
 
 	"github.com/google/uuid"
 
 	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
 	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
 )
 
 // Client defines the interface for file upload operations.
@@ -21,13 +26,39 @@ type FileUploadClient struct {
 	fileupload.Client
 }
 
-// NewClient creates a new FileUploadClient for the given org.
-func NewClient(ictx workflow.InvocationContext, orgID string) (*FileUploadClient, error) {
+// NewClient creates a new FileUploadClient for the given org, which must be an org ID.
+// The bytes of the uploaded files are counted into bytesSent if it is set.
+func NewClient(ictx workflow.InvocationContext, orgID string, retryConfig retry.Config, bytesSent *ByteCounter) (*FileUploadClient, error) {
+	orgUUID, err := uuid.Parse(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("org ID %q is not a valid UUID: %w", orgID, err)
+	}
+
 	config := ictx.GetConfiguration()
-	httpClient := ictx.GetNetworkAccess().GetHttpClient()
+	retryConfig.Idempotent = isIdempotent
+	httpClient := countBytes(ictx.GetNetworkAccess().GetHttpClient(), bytesSent)
+	httpClient = retry.WrapClient(httpClient, retryConfig)
 	baseURL := config.GetString(configuration.API_URL)
-	cfg := fileupload.Config{BaseURL: baseURL, OrgID: uuid.MustParse(orgID)}
+	cfg := fileupload.Config{BaseURL: baseURL, OrgID: orgUUID}
 
 	uploadClient := fileupload.NewClient(httpClient, cfg)
 	return &FileUploadClient{uploadClient}, nil
 }
+
+var (
+	uploadFilesPath = regexp.MustCompile(`/upload_revisions/[^/]+/files$`)
+	revisionPath    = regexp.MustCompile(`/upload_revisions/[^/]+$`)
+)
+
+// isIdempotent allows retrying file uploads into an existing revision, which overwrite the same
+// paths, and sealing a revision. Creating a revision is not retried, as it would leave an orphan.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost:
+		return uploadFilesPath.MatchString(req.URL.Path)
+	case http.MethodPatch:
+		return revisionPath.MatchString(req.URL.Path)
+	default:
+		return retry.IdempotentMethod(req)
+	}
+}
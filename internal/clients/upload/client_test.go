@@ -0,0 +1,39 @@
+package upload
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+)
+
+func TestIsIdempotent(t *testing.T) {
+	const revisions = "https://api.snyk.io/hidden/orgs/org/upload_revisions"
+
+	testCases := []struct {
+		method   string
+		url      string
+		expected bool
+		desc     string
+	}{
+		{method: http.MethodPost, url: revisions + "?version=2024-10-15", expected: false, desc: "create revision"},
+		{method: http.MethodPost, url: revisions + "/rev/files?version=2024-10-15", expected: true, desc: "upload files"},
+		{method: http.MethodPatch, url: revisions + "/rev?version=2024-10-15", expected: true, desc: "seal revision"},
+		{method: http.MethodGet, url: revisions + "/rev", expected: true, desc: "get revision"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.url, http.NoBody)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, isIdempotent(req))
+		})
+	}
+}
+
+func TestNewClient_InvalidOrgID(t *testing.T) {
+	_, err := NewClient(nil, "my-org", retry.DefaultConfig(), nil)
+	assert.ErrorContains(t, err, `org ID "my-org" is not a valid UUID`)
+}
@@ -0,0 +1,167 @@
+// Package codeowners parses CODEOWNERS files and resolves the owners of repository paths.
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Errors returned by the codeowners package.
+var (
+	ErrNotFound           = errors.New("no CODEOWNERS file found")
+	ErrUnsupportedPattern = errors.New("unsupported CODEOWNERS pattern")
+)
+
+// Locations are the CODEOWNERS file locations relative to the repository root, in lookup order.
+// As on GitHub, only the first file found is used.
+var Locations = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+type rule struct {
+	regex  *regexp.Regexp
+	owners []string
+}
+
+// Ruleset is a parsed CODEOWNERS file.
+type Ruleset struct {
+	rules []rule
+}
+
+// Load reads the CODEOWNERS file of the repository at repoRoot.
+// It returns the parsed rules and the path of the file that was used.
+func Load(repoRoot string) (*Ruleset, string, error) {
+	for _, location := range Locations {
+		filePath := filepath.Join(repoRoot, filepath.FromSlash(location))
+		f, err := os.Open(filePath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open %s: %w", filePath, err)
+		}
+
+		ruleset, err := Parse(f)
+		closeErr := f.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		if closeErr != nil {
+			return nil, "", fmt.Errorf("failed to close %s: %w", filePath, closeErr)
+		}
+		return ruleset, filePath, nil
+	}
+
+	return nil, "", ErrNotFound
+}
+
+// Parse reads CODEOWNERS rules. Blank lines and comments are ignored,
+// a pattern without owners explicitly removes ownership.
+// As on GitHub, lines with unsupported syntax are skipped.
+func Parse(r io.Reader) (*Ruleset, error) {
+	ruleset := &Ruleset{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		regex, err := compilePattern(fields[0])
+		if err != nil {
+			continue
+		}
+		ruleset.rules = append(ruleset.rules, rule{
+			regex:  regex,
+			owners: fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODEOWNERS: %w", err)
+	}
+
+	return ruleset, nil
+}
+
+// Owners returns the owners of filePath, relative to the repository root.
+// The last matching rule takes precedence, nil is returned for unowned paths.
+func (r *Ruleset) Owners(filePath string) []string {
+	filePath = strings.TrimPrefix(path.Clean(filepath.ToSlash(filePath)), "/")
+
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if r.rules[i].regex.MatchString(filePath) {
+			if len(r.rules[i].owners) == 0 {
+				return nil
+			}
+			return r.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compilePattern translates a CODEOWNERS pattern, which follows gitignore semantics, into a regular expression.
+// Negation and character ranges are not supported by CODEOWNERS.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") || strings.ContainsAny(pattern, "[]") {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedPattern, pattern)
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+
+	// a pattern with a leading or inner slash is relative to the repository root,
+	// otherwise it matches at any depth
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// a pattern matching a directory owns everything below it,
+	// except for a trailing wildcard which only matches direct children
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(trimmed, "*"):
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
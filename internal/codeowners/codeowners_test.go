@@ -0,0 +1,116 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleCodeowners = `
+# default owners
+*       @org/everyone
+
+*.js    @org/frontend   # trailing comment
+/build/logs/ @org/ops
+docs/*  docs@example.com
+apps/   @org/apps
+/scripts/** @org/tooling
+**/secrets/*.json @org/security
+config/?.yaml @org/config
+/vendor/
+`
+
+func TestRuleset_Owners(t *testing.T) {
+	ruleset, err := Parse(strings.NewReader(sampleCodeowners))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		expected []string
+		desc     string
+	}{
+		{"README.md", []string{"@org/everyone"}, "catch-all rule"},
+		{"src/app.js", []string{"@org/frontend"}, "extension matches at any depth"},
+		{"build/logs/today/app.log", []string{"@org/ops"}, "anchored directory owns nested files"},
+		{"src/build/logs/app.log", []string{"@org/everyone"}, "anchored directory does not match below the root"},
+		{"docs/getting-started.md", []string{"docs@example.com"}, "trailing wildcard matches direct children"},
+		{"docs/build-app/troubleshooting.md", []string{"@org/everyone"}, "trailing wildcard does not match nested files"},
+		{"apps/web/index.html", []string{"@org/apps"}, "unanchored directory at the root"},
+		{"services/apps/main.go", []string{"@org/apps"}, "unanchored directory at any depth"},
+		{"scripts/ci/build.sh", []string{"@org/tooling"}, "double star matches nested files"},
+		{"secrets/gcp.json", []string{"@org/security"}, "leading double star matches the root"},
+		{"deploy/secrets/aws.json", []string{"@org/security"}, "leading double star matches nested dirs"},
+		{"config/a.yaml", []string{"@org/config"}, "question mark matches a single character"},
+		{"config/ab.yaml", []string{"@org/everyone"}, "question mark does not match several characters"},
+		{"vendor/lib/lib.go", nil, "pattern without owners removes ownership"},
+		{"/apps/web/index.html", []string{"@org/apps"}, "leading slash in the path is ignored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ruleset.Owners(tt.path))
+		})
+	}
+}
+
+func TestRuleset_Owners_NoRules(t *testing.T) {
+	ruleset, err := Parse(strings.NewReader("# only comments\n\n"))
+	require.NoError(t, err)
+
+	assert.Nil(t, ruleset.Owners("main.go"))
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("prefers .github over root and docs", func(t *testing.T) {
+		root := t.TempDir()
+		writeCodeowners(t, root, ".github/CODEOWNERS", "* @github")
+		writeCodeowners(t, root, "CODEOWNERS", "* @root")
+		writeCodeowners(t, root, "docs/CODEOWNERS", "* @docs")
+
+		ruleset, filePath, err := Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, ".github", "CODEOWNERS"), filePath)
+		assert.Equal(t, []string{"@github"}, ruleset.Owners("main.go"))
+	})
+
+	t.Run("falls back to root then docs", func(t *testing.T) {
+		root := t.TempDir()
+		writeCodeowners(t, root, "docs/CODEOWNERS", "* @docs")
+
+		ruleset, _, err := Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@docs"}, ruleset.Owners("main.go"))
+
+		writeCodeowners(t, root, "CODEOWNERS", "* @root")
+		ruleset, _, err = Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@root"}, ruleset.Owners("main.go"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := Load(t.TempDir())
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("unsupported patterns are skipped", func(t *testing.T) {
+		root := t.TempDir()
+		writeCodeowners(t, root, "CODEOWNERS", "* @everyone\n!main.go @negated\n[a-z].go @ranged\n")
+
+		ruleset, _, err := Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@everyone"}, ruleset.Owners("main.go"))
+		assert.Equal(t, []string{"@everyone"}, ruleset.Owners("a.go"))
+	})
+}
+
+func writeCodeowners(t *testing.T, root, name, content string) {
+	t.Helper()
+
+	filePath := filepath.Join(root, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+}
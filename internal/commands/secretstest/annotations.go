@@ -0,0 +1,109 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+
+	"github.com/snyk/cli-extension-secrets/internal/ci"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const (
+	// AnnotationsAuto picks the annotation format of the CI system the command runs on.
+	AnnotationsAuto = "auto"
+	// EnvGitHubStepSummary is the file GitHub Actions shows on the summary page of a job.
+	EnvGitHubStepSummary = "GITHUB_STEP_SUMMARY"
+)
+
+// applyAnnotationArgs resolves --annotations into the annotation format and the step summary file, if any.
+func applyAnnotationArgs(args *CommandArgs, config configuration.Configuration) {
+	args.Annotations = resolveAnnotations(config.GetString(FlagAnnotations), os.Getenv)
+	if args.Annotations == report.AnnotationsGitHub {
+		args.StepSummaryPath = os.Getenv(EnvGitHubStepSummary)
+	}
+}
+
+// resolveAnnotations returns the annotation format of an --annotations value. With auto, it is the format of
+// the detected CI system, or none if it has no annotations.
+func resolveAnnotations(value string, getenv func(string) string) string {
+	if value != AnnotationsAuto {
+		return value
+	}
+
+	env := ci.Detect(getenv)
+	if env == nil {
+		return ""
+	}
+	switch env.Provider {
+	case ci.GitHubActions:
+		return report.AnnotationsGitHub
+	case ci.AzurePipelines:
+		return report.AnnotationsAzure
+	default:
+		return ""
+	}
+}
+
+// writeAnnotations shows findings as the workflow commands of the CI system and appends their summary to the
+// step summary file, if requested. Annotations are best effort and do not fail the run.
+func (c *Command) writeAnnotations(ctx context.Context, findings []report.Finding) {
+	if c.Annotations == "" {
+		return
+	}
+
+	var b strings.Builder
+	opts := report.AnnotationOptions{Format: c.Annotations, PathPrefix: c.RootFolderID}
+	if err := report.RenderAnnotations(&b, findings, opts); err != nil {
+		c.Logger.Warn().Err(err).Msg("could not write annotations")
+	} else if err := outputAnnotations(ctx, strings.TrimSuffix(b.String(), "\n")); err != nil {
+		c.Logger.Warn().Err(err).Msg("could not output annotations")
+	}
+
+	if c.StepSummaryPath == "" {
+		return
+	}
+	if err := appendStepSummary(c.StepSummaryPath, findings); err != nil {
+		c.Logger.Warn().Err(err).Str("path", c.StepSummaryPath).Msg("could not append step summary")
+	}
+}
+
+// outputAnnotations shows annotations through the user interface of the CLI. CI systems pick up workflow commands
+// from either stream, so they are shown on stderr when stdout carries JSON or SARIF results.
+func outputAnnotations(ctx context.Context, annotations string) error {
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil || annotations == "" {
+		return nil
+	}
+
+	config := ictx.GetConfiguration()
+	if config.GetBool(FlagJSON) || config.GetBool(FlagSARIF) {
+		return ictx.GetUserInterface().OutputError(errors.New(annotations))
+	}
+	return ictx.GetUserInterface().Output(annotations)
+}
+
+// appendStepSummary appends the markdown summary of findings to the step summary file, which other steps of the
+// job write to as well.
+func appendStepSummary(path string, findings []report.Finding) error {
+	var b bytes.Buffer
+	if err := report.RenderMarkdownSummary(&b, findings); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, &b); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
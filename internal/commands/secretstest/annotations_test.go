@@ -0,0 +1,142 @@
+package secretstest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+func TestResolveAnnotations(t *testing.T) {
+	testCases := []struct {
+		value string
+		env   map[string]string
+		want  string
+		desc  string
+	}{
+		{value: "", want: "", desc: "not requested"},
+		{value: report.AnnotationsGitHub, want: report.AnnotationsGitHub, desc: "github"},
+		{value: report.AnnotationsAzure, env: map[string]string{"GITHUB_ACTIONS": "true"}, want: report.AnnotationsAzure, desc: "explicit format"},
+		{value: AnnotationsAuto, env: map[string]string{"GITHUB_ACTIONS": "true"}, want: report.AnnotationsGitHub, desc: "auto on github actions"},
+		{value: AnnotationsAuto, env: map[string]string{"TF_BUILD": "True"}, want: report.AnnotationsAzure, desc: "auto on azure pipelines"},
+		{value: AnnotationsAuto, env: map[string]string{"GITLAB_CI": "true"}, want: "", desc: "auto on a CI system without annotations"},
+		{value: AnnotationsAuto, want: "", desc: "auto outside of CI"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+			assert.Equal(t, tc.want, resolveAnnotations(tc.value, getenv))
+		})
+	}
+}
+
+func TestApplyAnnotationArgs(t *testing.T) {
+	t.Setenv(EnvGitHubStepSummary, "/tmp/step-summary.md")
+
+	args := &CommandArgs{}
+	applyAnnotationArgs(args, setupMockConfig(map[string]any{FlagAnnotations: report.AnnotationsGitHub}))
+	assert.Equal(t, report.AnnotationsGitHub, args.Annotations)
+	assert.Equal(t, "/tmp/step-summary.md", args.StepSummaryPath)
+
+	args = &CommandArgs{}
+	applyAnnotationArgs(args, setupMockConfig(map[string]any{FlagAnnotations: report.AnnotationsAzure}))
+	assert.Equal(t, report.AnnotationsAzure, args.Annotations)
+	assert.Empty(t, args.StepSummaryPath, "the step summary is only written on github actions")
+
+	args = &CommandArgs{}
+	applyAnnotationArgs(args, setupMockConfig(map[string]any{}))
+	assert.Empty(t, args.Annotations)
+	assert.Empty(t, args.StepSummaryPath)
+}
+
+// setupAnnotationsIctx returns a context whose user interface records the annotations shown on stdout and stderr.
+func setupAnnotationsIctx(t *testing.T, ctrl *gomock.Controller, config configuration.Configuration) (context.Context, *strings.Builder, *strings.Builder) {
+	t.Helper()
+
+	var stdout, stderr strings.Builder
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockUserInterface := mocks.NewMockUserInterface(ctrl)
+	mockIctx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	mockIctx.EXPECT().GetUserInterface().Return(mockUserInterface).AnyTimes()
+	mockUserInterface.EXPECT().Output(gomock.Any()).DoAndReturn(func(output string) error {
+		stdout.WriteString(output)
+		return nil
+	}).AnyTimes()
+	mockUserInterface.EXPECT().OutputError(gomock.Any()).DoAndReturn(func(err error, _ ...any) error {
+		stderr.WriteString(err.Error())
+		return nil
+	}).AnyTimes()
+
+	return cmdctx.WithIctx(t.Context(), mockIctx), &stdout, &stderr
+}
+
+func TestCommand_WriteAnnotations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	ctx, annotations, stderr := setupAnnotationsIctx(t, ctrl, configuration.New())
+	cmd.Annotations = report.AnnotationsGitHub
+	cmd.RootFolderID = "services/api"
+	cmd.StepSummaryPath = filepath.Join(t.TempDir(), "step-summary.md")
+	require.NoError(t, os.WriteFile(cmd.StepSummaryPath, []byte("## Build\n\n"), 0o600))
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	require.NoError(t, cmd.writeReports(ctx, testResult))
+
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, annotations.String(), "::error file=services/api/gcp-credentials.json,line=7,col=20,")
+	assert.Contains(t, annotations.String(), "title=Snyk Secrets%3A Private Key::critical severity secret found by rule private-key")
+
+	summary, err := os.ReadFile(cmd.StepSummaryPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "## Build\n\n## Snyk Secrets\n\n**1 secret found:** 1 critical", "the summary is appended")
+	assert.Contains(t, string(summary), "| critical | Private Key | `private-key` | `gcp-credentials.json:7:20` |")
+}
+
+func TestCommand_WriteAnnotations_StepSummaryUnwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	ctx, annotations, _ := setupAnnotationsIctx(t, ctrl, configuration.New())
+	cmd.Annotations = report.AnnotationsAzure
+	// a directory cannot be appended to
+	cmd.StepSummaryPath = t.TempDir()
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	assert.NoError(t, cmd.writeReports(ctx, testResult), "annotations do not fail the run")
+	assert.Contains(t, annotations.String(), "##vso[task.logissue type=error;sourcepath=gcp-credentials.json;linenumber=7;columnnumber=20;code=private-key]")
+}
+
+func TestCommand_WriteAnnotations_MachineReadableOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	config := configuration.New()
+	config.Set(FlagJSON, true)
+	ctx, stdout, annotations := setupAnnotationsIctx(t, ctrl, config)
+	cmd.Annotations = report.AnnotationsGitHub
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	require.NoError(t, cmd.writeReports(ctx, testResult))
+
+	assert.Empty(t, stdout.String(), "stdout is kept for the JSON results")
+	assert.Contains(t, annotations.String(), "::error file=gcp-credentials.json,line=7,col=20,")
+}
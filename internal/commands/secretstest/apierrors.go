@@ -0,0 +1,248 @@
+package secretstest
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	cli_errors "github.com/snyk/error-catalog-golang-public/cli"
+	"github.com/snyk/error-catalog-golang-public/errorcodes"
+	"github.com/snyk/error-catalog-golang-public/openapi"
+	"github.com/snyk/error-catalog-golang-public/snyk"
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
+)
+
+// Error meta keys holding what the test API reported, so that --json output keeps the original errors.
+const (
+	MetaAPIErrorIDs      = "apiErrorIds"
+	MetaAPIErrorCodes    = "apiErrorCodes"
+	MetaAPIErrorStatuses = "apiErrorStatuses"
+)
+
+// secretsDocsURL documents the secrets commands, linked from all test API errors.
+const secretsDocsURL = "https://docs.snyk.io/snyk-cli/commands/secrets"
+
+// apiErrorMapping maps test API errors with one of codes or, failing that, statuses to a catalog error. A mapping
+// with resources only applies to errors about one of them, as reported by testshim.StatusError.
+type apiErrorMapping struct {
+	codes     []string
+	statuses  []string
+	resources []string
+	summary   string
+	newError  func(detail string, options ...snyk_errors.Option) snyk_errors.Error
+}
+
+// testAPIErrorMappings are the test API errors with a specific remediation, in order of precedence. Statuses are
+// only a fallback for errors without a known code, and only map statuses with a single meaning.
+var testAPIErrorMappings = []apiErrorMapping{
+	{
+		codes:    []string{errorcodes.CLI.FeatureNotEnabledError, errorcodes.OpenAPI.ForbiddenError},
+		statuses: []string{"403"},
+		summary:  "Secrets testing is not enabled for the organization",
+		newError: cli_errors.NewFeatureNotEnabledError,
+	},
+	{
+		codes:     []string{errorcodes.OpenAPI.NotFoundError},
+		statuses:  []string{"404"},
+		resources: []string{testshim.ResourceOrg},
+		summary:   "The organization was not found or is not accessible with the current credentials",
+		newError:  openapi.NewNotFoundError,
+	},
+	{
+		codes:     []string{errorcodes.OpenAPI.NotFoundError},
+		statuses:  []string{"404"},
+		resources: []string{testshim.ResourceTest},
+		summary:   "The test was not found or is not accessible with the current credentials and organization",
+		newError:  openapi.NewNotFoundError,
+	},
+	{
+		codes:    []string{errorcodes.Snyk.TestLimitReachedError},
+		statuses: []string{"402"},
+		summary:  "The test quota of the organization is exceeded",
+		newError: snyk.NewTestLimitReachedError,
+	},
+	{
+		codes:    []string{errorcodes.Snyk.TooManyRequestsError},
+		statuses: []string{"429"},
+		summary:  "Too many requests were sent to the API",
+		newError: snyk.NewTooManyRequestsError,
+	},
+	{
+		codes:    []string{errorcodes.CLI.NoSupportedFilesFoundError, errorcodes.OpenAPI.UnsupportedMediaTypeError},
+		statuses: []string{"415"},
+		summary:  "The test resource is not supported by secrets testing",
+		newError: cli_errors.NewNoSupportedFilesFoundError,
+	},
+	{
+		codes:    []string{errorcodes.OpenAPI.BadRequestError},
+		statuses: []string{"400", "422"},
+		summary:  "The test request is invalid",
+		newError: openapi.NewBadRequestError,
+	},
+	{
+		codes:    []string{errorcodes.Snyk.TimeoutError},
+		statuses: []string{"408", "504"},
+		summary:  "The scan timed out",
+		newError: snyk.NewTimeoutError,
+	},
+}
+
+// NewTestAPIError maps the errors the test API reported, in a response with statusCode about resource or for an
+// errored test if statusCode is 0, to the catalog error with the matching remediation. The catalog error keeps the
+// IDs, codes and statuses of the original errors in its meta. It returns nil if neither the errors nor the status are
+// known.
+func (ef *ErrorFactory) NewTestAPIError(statusCode int, resource string, apiErrors []testapi.IoSnykApiCommonError, cause error) error {
+	mapping := matchTestAPIError(statusCode, resource, apiErrors)
+	if mapping == nil {
+		return nil
+	}
+
+	details := make([]string, 0, len(apiErrors))
+	ids := make([]string, 0, len(apiErrors))
+	codes := make([]string, 0, len(apiErrors))
+	statuses := make([]string, 0, len(apiErrors))
+	links := []string{secretsDocsURL}
+	for _, apiError := range apiErrors {
+		if apiError.Detail != "" {
+			details = append(details, apiError.Detail)
+		}
+		if apiError.Id != nil {
+			ids = append(ids, apiError.Id.String())
+		}
+		if apiError.Code != nil {
+			codes = append(codes, *apiError.Code)
+		}
+		statuses = append(statuses, apiError.Status)
+		if link := aboutLink(apiError); link != "" {
+			links = append(links, link)
+		}
+	}
+	if len(apiErrors) == 0 {
+		statuses = append(statuses, strconv.Itoa(statusCode))
+	}
+
+	detail := mapping.summary + "."
+	if len(details) > 0 {
+		detail = fmt.Sprintf("%s: %s.", mapping.summary, strings.Join(details, "; "))
+	}
+	ef.logger.Error().Err(cause).Strs(MetaAPIErrorIDs, ids).Msg(detail)
+
+	catalogErr := mapping.newError(detail,
+		snyk_errors.WithCause(cause),
+		snyk_errors.WithMeta(MetaAPIErrorIDs, ids),
+		snyk_errors.WithMeta(MetaAPIErrorCodes, codes),
+		snyk_errors.WithMeta(MetaAPIErrorStatuses, statuses),
+	)
+	for _, link := range links {
+		if !slices.Contains(catalogErr.Links, link) {
+			catalogErr.Links = append(catalogErr.Links, link)
+		}
+	}
+	return catalogErr
+}
+
+// matchTestAPIError returns the mapping of the first error with a known code, or else of the first known status,
+// among the mappings applying to resource.
+func matchTestAPIError(statusCode int, resource string, apiErrors []testapi.IoSnykApiCommonError) *apiErrorMapping {
+	var mappings []*apiErrorMapping
+	for i := range testAPIErrorMappings {
+		if len(testAPIErrorMappings[i].resources) == 0 || slices.Contains(testAPIErrorMappings[i].resources, resource) {
+			mappings = append(mappings, &testAPIErrorMappings[i])
+		}
+	}
+
+	for _, apiError := range apiErrors {
+		if apiError.Code == nil {
+			continue
+		}
+		for _, mapping := range mappings {
+			if slices.Contains(mapping.codes, *apiError.Code) {
+				return mapping
+			}
+		}
+	}
+
+	statuses := make([]string, 0, len(apiErrors)+1)
+	for _, apiError := range apiErrors {
+		statuses = append(statuses, apiError.Status)
+	}
+	if statusCode != 0 {
+		statuses = append(statuses, strconv.Itoa(statusCode))
+	}
+	for _, status := range statuses {
+		for _, mapping := range mappings {
+			if slices.Contains(mapping.statuses, status) {
+				return mapping
+			}
+		}
+	}
+
+	return nil
+}
+
+// responseErrors returns the errors of an API response held by err as catalog errors, as the test API client of the
+// framework reports them, along with the status code of the response. Errors parsed from a response have a status
+// code but, unlike the catalog errors created locally, no classification.
+func responseErrors(err error) ([]testapi.IoSnykApiCommonError, int) {
+	var apiErrors []testapi.IoSnykApiCommonError
+	statusCode := 0
+
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) { //nolint:errorlint // walks the tree of joined errors itself
+		case snyk_errors.Error:
+			if e.StatusCode == 0 || e.Classification != "" {
+				return
+			}
+			if statusCode == 0 {
+				statusCode = e.StatusCode
+			}
+			apiErrors = append(apiErrors, toAPIError(e))
+		case interface{ Unwrap() []error }:
+			for _, joined := range e.Unwrap() {
+				walk(joined)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return apiErrors, statusCode
+}
+
+// toAPIError converts a catalog error parsed from an API response back to the error the API reported.
+func toAPIError(snykErr snyk_errors.Error) testapi.IoSnykApiCommonError {
+	apiError := testapi.IoSnykApiCommonError{Detail: snykErr.Detail, Status: strconv.Itoa(snykErr.StatusCode)}
+	if snykErr.ErrorCode != "" {
+		apiError.Code = &snykErr.ErrorCode
+	}
+	if id, err := uuid.Parse(snykErr.ID); err == nil {
+		apiError.Id = &id
+	}
+	if snykErr.Type != "" {
+		var about testapi.IoSnykApiCommonLinkProperty
+		if err := about.FromIoSnykApiCommonLinkString(snykErr.Type); err == nil {
+			apiError.Links = &testapi.IoSnykApiCommonErrorLink{About: &about}
+		}
+	}
+	return apiError
+}
+
+// aboutLink returns the link to the details of apiError, if it has one.
+func aboutLink(apiError testapi.IoSnykApiCommonError) string {
+	if apiError.Links == nil || apiError.Links.About == nil {
+		return ""
+	}
+	if link, err := apiError.Links.About.AsIoSnykApiCommonLinkString(); err == nil {
+		return link
+	}
+	if object, err := apiError.Links.About.AsIoSnykApiCommonLinkObject(); err == nil {
+		return object.Href
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+//nolint:testpackage // whitebox testing the test API error mapping
+package secretstest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/snyk/error-catalog-golang-public/errorcodes"
+	"github.com/snyk/error-catalog-golang-public/snyk"
+	"github.com/snyk/error-catalog-golang-public/snyk_errors"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
+)
+
+func newAPIError(code, status, detail string) testapi.IoSnykApiCommonError {
+	id := uuid.New()
+	apiError := testapi.IoSnykApiCommonError{Id: &id, Status: status, Detail: detail}
+	if code != "" {
+		apiError.Code = &code
+	}
+	return apiError
+}
+
+func TestErrorFactory_NewTestAPIError(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		resource   string
+		apiError   testapi.IoSnykApiCommonError
+		errorCode  string
+		summary    string
+		desc       string
+	}{
+		{
+			apiError:  newAPIError(errorcodes.CLI.FeatureNotEnabledError, "403", "secrets is not entitled"),
+			errorCode: errorcodes.CLI.FeatureNotEnabledError,
+			desc:      "entitlement missing",
+		},
+		{
+			resource:  testshim.ResourceOrg,
+			apiError:  newAPIError("", "404", "org not found"),
+			errorCode: errorcodes.OpenAPI.NotFoundError,
+			summary:   "The organization was not found",
+			desc:      "org not found",
+		},
+		{
+			resource:  testshim.ResourceTest,
+			apiError:  newAPIError(errorcodes.OpenAPI.NotFoundError, "404", "test not found"),
+			errorCode: errorcodes.OpenAPI.NotFoundError,
+			summary:   "The test was not found",
+			desc:      "test not found",
+		},
+		{
+			apiError:  newAPIError("", "402", "quota exceeded"),
+			errorCode: errorcodes.Snyk.TestLimitReachedError,
+			summary:   "The test quota of the organization is exceeded",
+			desc:      "quota exceeded",
+		},
+		{
+			apiError:  newAPIError("", "429", "rate limit exceeded"),
+			errorCode: errorcodes.Snyk.TooManyRequestsError,
+			summary:   "Too many requests",
+			desc:      "rate limited",
+		},
+		{
+			apiError:  newAPIError(errorcodes.OpenAPI.UnsupportedMediaTypeError, "415", "resource type not supported"),
+			errorCode: errorcodes.CLI.NoSupportedFilesFoundError,
+			desc:      "unsupported resource",
+		},
+		{
+			apiError:  newAPIError("", "422", "branch is too long"),
+			errorCode: errorcodes.OpenAPI.BadRequestError,
+			summary:   "The test request is invalid",
+			desc:      "validation error",
+		},
+		{
+			resource:  testshim.ResourceOrg,
+			apiError:  newAPIError(errorcodes.Snyk.TestLimitReachedError, "404", "quota exceeded"),
+			errorCode: errorcodes.Snyk.TestLimitReachedError,
+			desc:      "code takes precedence over the not found status",
+		},
+		{
+			apiError:  newAPIError(errorcodes.Snyk.TimeoutError, "500", "scan timed out"),
+			errorCode: errorcodes.Snyk.TimeoutError,
+			desc:      "scan timeout, code takes precedence over status",
+		},
+		{
+			statusCode: http.StatusForbidden,
+			apiError:   newAPIError("", "", "access denied"),
+			errorCode:  errorcodes.CLI.FeatureNotEnabledError,
+			desc:       "response status",
+		},
+	}
+
+	logger := zerolog.Nop()
+	ef := NewErrorFactory(&logger)
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cause := fmt.Errorf("test execution error: %s", tc.apiError.Detail)
+
+			err := ef.NewTestAPIError(tc.statusCode, tc.resource, []testapi.IoSnykApiCommonError{tc.apiError}, cause)
+
+			catalogErr := requireCatalogError(t, err)
+			assert.Equal(t, tc.errorCode, catalogErr.ErrorCode)
+			assert.Contains(t, catalogErr.Detail, tc.summary)
+			assert.Contains(t, catalogErr.Detail, tc.apiError.Detail)
+			assert.Contains(t, catalogErr.Links, secretsDocsURL)
+			assert.Equal(t, []string{tc.apiError.Id.String()}, catalogErr.Meta[MetaAPIErrorIDs])
+			assert.Equal(t, []string{tc.apiError.Status}, catalogErr.Meta[MetaAPIErrorStatuses])
+			assert.ErrorIs(t, err, cause)
+		})
+	}
+}
+
+func TestErrorFactory_NewTestAPIError_AboutLink(t *testing.T) {
+	var about testapi.IoSnykApiCommonLinkProperty
+	require.NoError(t, about.FromIoSnykApiCommonLinkString("https://example.com/errors/quota"))
+	apiError := newAPIError("", "402", "quota exceeded")
+	apiError.Links = &testapi.IoSnykApiCommonErrorLink{About: &about}
+
+	logger := zerolog.Nop()
+	err := NewErrorFactory(&logger).NewTestAPIError(0, "", []testapi.IoSnykApiCommonError{apiError}, nil)
+
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Links, "https://example.com/errors/quota")
+}
+
+func TestErrorFactory_NewTestAPIError_Unknown(t *testing.T) {
+	logger := zerolog.Nop()
+	ef := NewErrorFactory(&logger)
+
+	assert.NoError(t, ef.NewTestAPIError(0, "", []testapi.IoSnykApiCommonError{newAPIError("", "500", "scanner error")}, nil))
+	assert.NoError(t, ef.NewTestAPIError(http.StatusInternalServerError, "", nil, nil))
+}
+
+func TestErrorFactory_NewExecuteTestError_StatusError(t *testing.T) {
+	logger := zerolog.Nop()
+	ef := NewErrorFactory(&logger)
+
+	err := ef.NewExecuteTestError(fmt.Errorf("failed to start test: %w",
+		&testshim.StatusError{StatusCode: http.StatusNotFound, Resource: testshim.ResourceOrg}))
+
+	catalogErr := requireCatalogError(t, err)
+	assert.Equal(t, errorcodes.OpenAPI.NotFoundError, catalogErr.ErrorCode)
+	assert.Contains(t, catalogErr.Detail, "The organization was not found")
+	assert.Equal(t, []string{"404"}, catalogErr.Meta[MetaAPIErrorStatuses])
+	assert.ErrorIs(t, err, testshim.ErrUnexpectedStatus)
+}
+
+// frameworkAPIError returns the error the test API client of the framework reports for a response with body.
+func frameworkAPIError(t *testing.T, body string) error {
+	t.Helper()
+
+	snykErrs, err := snyk_errors.FromJSONAPIErrorBytes([]byte(body))
+	require.NoError(t, err)
+	joined := make([]error, 0, len(snykErrs))
+	for _, snykErr := range snykErrs {
+		joined = append(joined, snykErr)
+	}
+	return fmt.Errorf("test execution failed: %w", errors.Join(joined...))
+}
+
+func TestErrorFactory_NewExecuteTestError_FrameworkErrors(t *testing.T) {
+	id := uuid.New()
+	testCases := []struct {
+		body         string
+		expectedCode string
+		desc         string
+	}{
+		{
+			body:         fmt.Sprintf(`{"errors":[{"id":%q,"status":"403","code":%q,"detail":"not entitled"}]}`, id, errorcodes.CLI.FeatureNotEnabledError),
+			expectedCode: errorcodes.CLI.FeatureNotEnabledError,
+			desc:         "entitlement",
+		},
+		{
+			body:         fmt.Sprintf(`{"errors":[{"id":%q,"status":"402","detail":"quota exceeded"}]}`, id),
+			expectedCode: errorcodes.Snyk.TestLimitReachedError,
+			desc:         "quota",
+		},
+		{
+			body:         fmt.Sprintf(`{"errors":[{"id":%q,"status":"415","detail":"unsupported resource"}]}`, id),
+			expectedCode: errorcodes.CLI.NoSupportedFilesFoundError,
+			desc:         "unsupported media type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			logger := zerolog.Nop()
+			err := NewErrorFactory(&logger).NewExecuteTestError(frameworkAPIError(t, tc.body))
+
+			catalogErr := requireCatalogError(t, err)
+			assert.Equal(t, tc.expectedCode, catalogErr.ErrorCode)
+			assert.Contains(t, catalogErr.Links, secretsDocsURL)
+			assert.Equal(t, []string{id.String()}, catalogErr.Meta[MetaAPIErrorIDs])
+		})
+	}
+
+	t.Run("not reported by the API", func(t *testing.T) {
+		logger := zerolog.Nop()
+		// the framework reports canceled polling without a status code
+		canceled := snyk.NewBadRequestError("polling job canceled")
+		canceled.StatusCode = 0
+
+		err := NewErrorFactory(&logger).NewExecuteTestError(canceled)
+		catalogErr := requireCatalogError(t, err)
+		assert.Equal(t, canceled.ErrorCode, catalogErr.ErrorCode)
+		assert.Nil(t, catalogErr.Meta[MetaAPIErrorIDs])
+	})
+}
+
+func TestCommand_CheckTestResult_MapsAPIErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	_, _, cmd := setupTestCommand(t, ctrl)
+
+	apiError := newAPIError("", "504", "scan did not complete in time")
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesErrored)
+	testResult.EXPECT().GetErrors().Return(&[]testapi.IoSnykApiCommonError{apiError})
+
+	_, err := cmd.checkTestResult(t.Context(), testResult)
+
+	catalogErr := requireCatalogError(t, err)
+	assert.Equal(t, errorcodes.Snyk.TimeoutError, catalogErr.ErrorCode)
+	assert.Equal(t, []string{apiError.Id.String()}, catalogErr.Meta[MetaAPIErrorIDs])
+}
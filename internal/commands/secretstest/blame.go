@@ -0,0 +1,76 @@
+package secretstest
+
+import (
+	"context"
+	"path"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/blame"
+)
+
+// BlameMetadata is the per-finding metadata key holding git blame information.
+const BlameMetadata = "blame"
+
+type lineBlamer interface {
+	BlameLines(filePath string, fromLine, toLine int) (*blame.Info, error)
+}
+
+var newBlamerFunc = func(repoDir string) (lineBlamer, error) {
+	return blame.NewBlamer(repoDir)
+}
+
+// blameLocation is the blame information of a single finding location.
+type blameLocation struct {
+	FilePath string `json:"filePath"`
+	FromLine int    `json:"fromLine"`
+	blame.Info
+}
+
+// addBlameInfo attaches the commit, author and timestamp that last touched each finding location, and keeps them for
+// the reports. Blame failures are logged and never fail the workflow.
+func (c *Command) addBlameInfo(ctx context.Context, testResult testapi.TestResult) {
+	if c.GitRootDir == "" {
+		c.Logger.Warn().Msg("--blame requires the input path to be inside a git repository, skipping blame")
+		return
+	}
+
+	blamer, err := newBlamerFunc(c.GitRootDir)
+	if err != nil {
+		c.Logger.Warn().Err(err).Msg("could not open git repository for blame")
+		return
+	}
+
+	findings, _, err := testResult.Findings(ctx)
+	if err != nil {
+		c.Logger.Warn().Err(err).Msg("could not read findings for blame")
+		return
+	}
+
+	for i := range findings {
+		var blamed []blameLocation
+		for _, loc := range sourceLocations(&findings[i]) {
+			// finding paths are relative to the upload root, blame paths to the git root
+			repoPath := path.Join(c.RootFolderID, loc.FilePath)
+			toLine := loc.FromLine
+			if loc.ToLine != nil {
+				toLine = *loc.ToLine
+			}
+
+			info, blameErr := blamer.BlameLines(repoPath, loc.FromLine, toLine)
+			if blameErr != nil {
+				c.Logger.Debug().Err(blameErr).Str("filePath", repoPath).Msg("could not blame finding location")
+				continue
+			}
+			blamed = append(blamed, blameLocation{FilePath: loc.FilePath, FromLine: loc.FromLine, Info: *info})
+		}
+
+		if len(blamed) > 0 {
+			if c.blames == nil {
+				c.blames = map[string][]blameLocation{}
+			}
+			c.blames[findingID(&findings[i])] = blamed
+			setFindingMetadata(testResult, findingID(&findings[i]), BlameMetadata, blamed)
+		}
+	}
+}
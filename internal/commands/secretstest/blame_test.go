@@ -0,0 +1,134 @@
+//nolint:testpackage // whitebox testing the blame enrichment
+package secretstest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/blame"
+)
+
+type fakeBlamer struct {
+	calls []string
+	info  map[string]*blame.Info
+}
+
+func (f *fakeBlamer) BlameLines(filePath string, _, _ int) (*blame.Info, error) {
+	f.calls = append(f.calls, filePath)
+	if info, ok := f.info[filePath]; ok {
+		return info, nil
+	}
+	return nil, errors.New("not tracked")
+}
+
+func stubBlamer(t *testing.T, b lineBlamer, err error) {
+	t.Helper()
+	original := newBlamerFunc
+	newBlamerFunc = func(string) (lineBlamer, error) { return b, err }
+	t.Cleanup(func() { newBlamerFunc = original })
+}
+
+// mockTestResultWithMetadata returns a mock test result with the fixture findings and an in-memory metadata store.
+func mockTestResultWithMetadata(t *testing.T, ctrl *gomock.Controller) (*gafclientmocks.MockTestResult, map[string]any) {
+	t.Helper()
+
+	findingContent, err := os.ReadFile("./testdata/finding.json")
+	require.NoError(t, err)
+	var findings []testapi.FindingData
+	require.NoError(t, json.Unmarshal(findingContent, &findings))
+
+	metadata := map[string]any{}
+	mockTestResult := gafclientmocks.NewMockTestResult(ctrl)
+	mockTestResult.EXPECT().Findings(gomock.Any()).Return(findings, true, nil).AnyTimes()
+	mockTestResult.EXPECT().GetMetadataValue(gomock.Any()).DoAndReturn(func(key string) any {
+		return metadata[key]
+	}).AnyTimes()
+	mockTestResult.EXPECT().SetMetadata(gomock.Any(), gomock.Any()).Do(func(key string, value any) {
+		metadata[key] = value
+	}).AnyTimes()
+
+	return mockTestResult, metadata
+}
+
+func TestCommand_AddBlameInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	cmd.GitRootDir = t.TempDir()
+	cmd.RootFolderID = "services/api"
+
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	blamer := &fakeBlamer{info: map[string]*blame.Info{
+		"services/api/gcp-credentials.json": {CommitSHA: "abc123", AuthorEmail: "dev@example.com", Timestamp: when},
+	}}
+	stubBlamer(t, blamer, nil)
+
+	mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+
+	cmd.addBlameInfo(t.Context(), mockTestResult)
+
+	assert.Equal(t, []string{"services/api/gcp-credentials.json", "services/api/gcp-credentials.json"}, blamer.calls)
+
+	findingsMeta, ok := metadata[FindingsMetadata].(map[string]map[string]any)
+	require.True(t, ok)
+	blamed, ok := findingsMeta["bdaa4c47-9609-435c-80ef-317586c3a97a"][BlameMetadata].([]blameLocation)
+	require.True(t, ok)
+	require.Len(t, blamed, 2)
+	assert.Equal(t, "gcp-credentials.json", blamed[0].FilePath)
+	assert.Equal(t, 7, blamed[0].FromLine)
+	assert.Equal(t, 13, blamed[1].FromLine)
+	assert.Equal(t, "abc123", blamed[1].CommitSHA)
+
+	out, err := json.Marshal(blamed[0])
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"filePath":"gcp-credentials.json","fromLine":7,"commitSha":"abc123","authorEmail":"dev@example.com","timestamp":"2024-01-01T00:00:00Z"}`,
+		string(out))
+}
+
+func TestCommand_AddBlameInfo_Skipped(t *testing.T) {
+	t.Run("no git root", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		stubBlamer(t, nil, errors.New("must not be called"))
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		cmd.addBlameInfo(t.Context(), mockTestResult)
+
+		assert.Empty(t, metadata)
+	})
+
+	t.Run("repository cannot be opened", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = t.TempDir()
+		stubBlamer(t, nil, errors.New("not a repository"))
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		cmd.addBlameInfo(t.Context(), mockTestResult)
+
+		assert.Empty(t, metadata)
+	})
+
+	t.Run("untracked files", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = t.TempDir()
+		stubBlamer(t, &fakeBlamer{}, nil)
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		cmd.addBlameInfo(t.Context(), mockTestResult)
+
+		assert.Empty(t, metadata)
+	})
+}
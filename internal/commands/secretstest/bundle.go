@@ -0,0 +1,180 @@
+package secretstest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/bundle"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+// bundleContext is the context of a test exported to a scan bundle, from which the host uploading the bundle
+// runs the same test.
+type bundleContext struct {
+	RootFolderID      string       `json:"rootFolderId"`
+	RepoURL           string       `json:"repoUrl,omitempty"`
+	Branch            string       `json:"branch,omitempty"`
+	CommitRef         string       `json:"commitRef,omitempty"`
+	SeverityThreshold string       `json:"severityThreshold,omitempty"`
+	ReportConfig      ReportConfig `json:"reportConfig"`
+}
+
+// bundleExport describes a bundle written with --bundle-output.
+type bundleExport struct {
+	Path   string `json:"path"`
+	Files  int    `json:"files"`
+	Signed bool   `json:"signed"`
+}
+
+func (e bundleExport) toWorkflowData(id workflow.Identifier, jsonOutput bool) (workflow.Data, error) {
+	typeID := workflow.NewTypeIdentifier(id, "bundle")
+
+	if jsonOutput {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bundle export: %w", err)
+		}
+		return workflow.NewData(typeID, contentTypeJSON, payload), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scan bundle written to %s with %d files", e.Path, e.Files)
+	if e.Signed {
+		b.WriteString(", signed")
+	}
+	b.WriteString(".\n\n")
+	fmt.Fprintf(&b, "Test it from a connected host with: snyk secrets upload-bundle %s\n", e.Path)
+	return workflow.NewData(typeID, contentTypeText, []byte(b.String())), nil
+}
+
+// newBundleClients creates no clients, as exporting a bundle does not contact the API.
+func newBundleClients(_ workflow.InvocationContext, _ string) (*WorkflowClients, error) {
+	return &WorkflowClients{}, nil
+}
+
+// ExportBundle filters the files of inputPath and writes them, along with the context of the test,
+// to a scan bundle at bundlePath, signed with signingKey if set.
+func (c *Command) ExportBundle(
+	ctx context.Context,
+	inputPath, bundlePath string,
+	signingKey ed25519.PrivateKey,
+) ([]workflow.Data, error) {
+	stopReporting := c.reporter.start(func() string { return PhaseFiltering })
+	defer stopReporting()
+
+	w, err := bundle.Create(bundlePath, signingKey)
+	if err != nil {
+		return nil, c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to create bundle")
+	}
+
+	if err := c.addFilesToBundle(ctx, w, inputPath); err != nil {
+		w.Abort()
+		return nil, err
+	}
+
+	manifestContext, err := json.Marshal(bundleContext{
+		RootFolderID:      c.RootFolderID,
+		RepoURL:           c.RepoURL,
+		Branch:            c.Branch,
+		CommitRef:         c.CommitRef,
+		SeverityThreshold: c.SeverityThreshold,
+		ReportConfig:      c.ReportConfig,
+	})
+	if err != nil {
+		w.Abort()
+		return nil, c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to marshal bundle context")
+	}
+
+	manifest, err := w.Close(manifestContext)
+	if err != nil {
+		return nil, c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to write bundle")
+	}
+	c.Logger.Info().Str("bundle", bundlePath).Int(LogFieldCount, len(manifest.Files)).Msg("Scan bundle written")
+
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil {
+		return nil, fmt.Errorf("invocation context is nil")
+	}
+
+	export := bundleExport{Path: bundlePath, Files: len(manifest.Files), Signed: signingKey != nil}
+	data, err := export.toWorkflowData(ictx.GetWorkflowIdentifier(), ictx.GetConfiguration().GetBool(FlagJSON))
+	if err != nil {
+		return nil, c.ErrorFactory.NewPrepareOutputError(err)
+	}
+	return []workflow.Data{data}, nil
+}
+
+// addFilesToBundle adds the files of inputPath passing filtering to the bundle, named after their path
+// relative to the directory the upload would be relative to.
+func (c *Command) addFilesToBundle(ctx context.Context, w *bundle.Writer, inputPath string) error {
+	pathsChan, dir, err := c.filterFiles(ctx, inputPath)
+	if err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to filter files")
+	}
+
+	count := 0
+	for path := range pathsChan {
+		name, err := filepath.Rel(dir, path)
+		if err == nil {
+			err = w.AddFile(filepath.ToSlash(name), path)
+		}
+		if err != nil {
+			return c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to add file to bundle")
+		}
+		count++
+	}
+	// the pipeline also closes its channel when the context ends before the walk completes
+	if ctx.Err() != nil {
+		return c.interrupted(ctx, PhaseFiltering, ctx.Err())
+	}
+
+	if count == 0 {
+		return c.ErrorFactory.NewUploadError(fileupload.ErrNoFilesProvided)
+	}
+	return nil
+}
+
+// RunBundle uploads the files of a scan bundle extracted into dir and tests them like the run that exported it.
+func (c *Command) RunBundle(ctx context.Context, dir string, files []bundle.File) ([]workflow.Data, error) {
+	defer c.recordRetries(ctx)
+
+	uploadRevision, err := c.uploadBundleFiles(ctx, dir, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.runTest(ctx, uploadRevision)
+}
+
+func (c *Command) uploadBundleFiles(ctx context.Context, dir string, files []bundle.File) (string, error) {
+	ctx, cancel := withDeadline(ctx, "the upload deadline", c.Timeouts.FilterAndUpload)
+	defer cancel()
+
+	stopReporting := c.reporter.start(c.progress.uploadPhase)
+	defer stopReporting()
+
+	c.sourceDir = dir
+
+	// the files were filtered when the bundle was written
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		for _, file := range files {
+			c.progress.FileChecked(true)
+			select {
+			case paths <- filepath.Join(dir, filepath.FromSlash(file.Path)):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return c.uploadFiles(ctx, c.progress.forwardToUpload(ctx, paths), dir)
+}
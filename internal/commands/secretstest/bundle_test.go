@@ -0,0 +1,356 @@
+package secretstest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/bundle"
+	mock_testshim "github.com/snyk/cli-extension-secrets/internal/clients/testshim/mocks"
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+}
+
+// writeKeyPair writes an ed25519 key pair as PEM files into dir and returns their paths.
+func writeKeyPair(t *testing.T, dir string) (signingKeyPath, verifyKeyPath string) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	signingKeyPath = filepath.Join(dir, "signing.pem")
+	require.NoError(t, os.WriteFile(signingKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	verifyKeyPath = filepath.Join(dir, "verify.pem")
+	require.NoError(t, os.WriteFile(verifyKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+
+	return signingKeyPath, verifyKeyPath
+}
+
+func TestSecretsWorkflow_BundleOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inputDir := t.TempDir()
+	writeTestFiles(t, inputDir, map[string]string{"config.env": "TOKEN=abc", "src/app.js": "const a = 1"})
+	keyDir := t.TempDir()
+	signingKeyPath, verifyKeyPath := writeKeyPair(t, keyDir)
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+
+	// neither the feature flag nor the organization are checked, as the host writing the bundle may be offline
+	mockConfig := configuration.New()
+	mockConfig.Set(FeatureFlagIsSecretsEnabled, false)
+	mockConfig.Set(configuration.INPUT_DIRECTORY, []string{inputDir})
+	mockConfig.Set(FlagBundleOutput, bundlePath)
+	mockConfig.Set(FlagBundleSigningKey, signingKeyPath)
+	mockConfig.Set(FlagSeverityThreshold, optionHigh)
+	mockConfig.Set(FlagReport, true)
+	mockConfig.Set(FlagTargetName, "my-project")
+	mockConfig.Set(FlagJSON, true)
+
+	mockIctx := setupMockIctxWithNetworkAccess(ctrl, mockConfig)
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(WorkflowID)
+
+	output, err := SecretsWorkflow(mockIctx, []workflow.Data{})
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	var export bundleExport
+	require.NoError(t, json.Unmarshal(output[0].GetPayload().([]byte), &export))
+	assert.Equal(t, bundleExport{Path: bundlePath, Files: 2, Signed: true}, export)
+
+	verifyKey, err := bundle.LoadVerifyKey(verifyKeyPath)
+	require.NoError(t, err)
+	manifest, err := bundle.Extract(bundlePath, t.TempDir(), verifyKey)
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.ElementsMatch(t, []string{"config.env", "src/app.js"}, paths)
+
+	var bc bundleContext
+	require.NoError(t, json.Unmarshal(manifest.Context, &bc))
+	assert.Equal(t, optionHigh, bc.SeverityThreshold)
+	assert.True(t, bc.ReportConfig.Report)
+	assert.Equal(t, "my-project", bc.ReportConfig.TargetName)
+	assert.Nil(t, bc.ReportConfig.ProjectPageURL)
+}
+
+func TestSecretsWorkflow_BundleOutput_NoFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+
+	mockConfig := configuration.New()
+	mockConfig.Set(configuration.INPUT_DIRECTORY, []string{t.TempDir()})
+	mockConfig.Set(FlagBundleOutput, bundlePath)
+
+	mockIctx := setupMockIctxWithNetworkAccess(ctrl, mockConfig)
+
+	_, err := SecretsWorkflow(mockIctx, []workflow.Data{})
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "No supported files found.")
+	assert.NoFileExists(t, bundlePath)
+}
+
+func TestValidateBundleFlags(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+
+	testCases := []struct {
+		config      map[string]any
+		expectedErr string
+		desc        string
+	}{
+		{config: map[string]any{}, desc: "no bundle"},
+		{config: map[string]any{FlagBundleOutput: bundlePath}, desc: "bundle"},
+		{config: map[string]any{FlagBundleOutput: bundlePath, FlagBundleSigningKey: "key.pem"}, desc: "signed bundle"},
+		{
+			config:      map[string]any{FlagBundleSigningKey: "key.pem"},
+			expectedErr: "Invalid use of --bundle-signing-key, it can only be used in combination with the --bundle-output option",
+			desc:        "signing key without bundle",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagBlame: true},
+			expectedErr: "Invalid use of --blame, it cannot be combined with the --bundle-output option",
+			desc:        "blame",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagGroupBy: GroupByOwner},
+			expectedErr: "Invalid use of --group-by, it cannot be combined with the --bundle-output option",
+			desc:        "group by",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagNoWait: true},
+			expectedErr: "Invalid use of --no-wait, it cannot be combined with the --bundle-output option",
+			desc:        "no wait",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagUploadManifest: "upload.json"},
+			expectedErr: "Invalid use of --upload-manifest, it cannot be combined with the --bundle-output option",
+			desc:        "upload manifest",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagMinimalUpload: true},
+			expectedErr: "Invalid use of --minimal-upload, it cannot be combined with the --bundle-output option",
+			desc:        "minimal upload",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagJUnitFileOutput: "junit.xml"},
+			expectedErr: "Invalid use of --junit-file-output, it cannot be combined with the --bundle-output option",
+			desc:        "junit file output",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagGitLabFileOutput: "gl-secret-detection-report.json"},
+			expectedErr: "Invalid use of --gitlab-file-output, it cannot be combined with the --bundle-output option",
+			desc:        "gitlab file output",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagHTMLFileOutput: "secrets.html"},
+			expectedErr: "Invalid use of --html-file-output, it cannot be combined with the --bundle-output option",
+			desc:        "html file output",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: bundlePath, FlagFormatTemplate: "csv"},
+			expectedErr: "Invalid use of --format-template, it cannot be combined with the --bundle-output option",
+			desc:        "format template",
+		},
+		{
+			config:      map[string]any{FlagBundleOutput: "scan\x00.tar.zst"},
+			expectedErr: "Invalid --bundle-output: path contains invalid characters",
+			desc:        "invalid path",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateBundleFlags(setupMockConfig(tc.config))
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCommand_RunBundle_UploadsBundleFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+	cmd.RootFolderID = "services/api"
+	cmd.Branch = "main"
+	mockUI.EXPECT().SetTitle(gomock.Any()).AnyTimes()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"config.env": "TOKEN=abc", "src/app.js": "const a = 1"})
+	files := []bundle.File{{Path: "config.env"}, {Path: "src/app.js"}}
+
+	var uploaded []string
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), dir).DoAndReturn(
+		func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			for path := range paths {
+				uploaded = append(uploaded, path)
+			}
+			return fileupload.UploadResult{RevisionID: uuid.New(), UploadedFilesCount: len(uploaded)}, nil
+		},
+	)
+
+	var params testapi.StartTestParams
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().StartTest(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, p testapi.StartTestParams) (testapi.TestHandle, error) {
+			params = p
+			return nil, errors.New("scanner error")
+		},
+	)
+
+	_, err := cmd.RunBundle(t.Context(), dir, files)
+	requireCatalogError(t, err)
+
+	assert.Equal(t, []string{filepath.Join(dir, "config.env"), filepath.Join(dir, "src", "app.js")}, uploaded)
+	assert.EqualValues(t, 2, cmd.progress.filesUploaded.Load())
+	assert.Equal(t, cmd.OrgID, params.OrgID())
+}
+
+func TestUploadBundleWorkflow_InvalidBundle(t *testing.T) {
+	keyDir := t.TempDir()
+	_, verifyKeyPath := writeKeyPair(t, keyDir)
+
+	inputDir := t.TempDir()
+	writeTestFiles(t, inputDir, map[string]string{"config.env": "TOKEN=abc"})
+	unsignedPath := filepath.Join(t.TempDir(), "scan.tar.zst")
+	w, err := bundle.Create(unsignedPath, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.AddFile("config.env", filepath.Join(inputDir, "config.env")))
+	_, err = w.Close(json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	notABundlePath := filepath.Join(inputDir, "config.env")
+
+	testCases := []struct {
+		bundlePath  string
+		verifyKey   string
+		expectedErr string
+		desc        string
+	}{
+		{
+			bundlePath:  unsignedPath,
+			verifyKey:   verifyKeyPath,
+			expectedErr: "bundle is not signed. It must be signed with the key matching --bundle-verify-key.",
+			desc:        "unsigned bundle",
+		},
+		{bundlePath: notABundlePath, verifyKey: verifyKeyPath, expectedErr: "invalid bundle", desc: "not a bundle"},
+		{bundlePath: unsignedPath, verifyKey: notABundlePath, expectedErr: "Invalid --bundle-verify-key", desc: "invalid verification key"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockConfig := configuration.New()
+			mockConfig.Set(FeatureFlagIsSecretsEnabled, true)
+			mockConfig.Set(configuration.ORGANIZATION, uuid.New().String())
+			mockConfig.Set(configuration.INPUT_DIRECTORY, []string{tc.bundlePath})
+			if tc.verifyKey != "" {
+				mockConfig.Set(FlagBundleVerifyKey, tc.verifyKey)
+			}
+			mockIctx := setupMockIctx(ctrl, mockConfig)
+
+			_, err := UploadBundleWorkflow(mockIctx, []workflow.Data{})
+			catalogErr := requireCatalogError(t, err)
+			assert.Contains(t, catalogErr.Detail, tc.expectedErr)
+		})
+	}
+}
+
+func TestExtractBundle_Unverified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inputDir := t.TempDir()
+	writeTestFiles(t, inputDir, map[string]string{"config.env": "TOKEN=abc"})
+	bundlePath := filepath.Join(t.TempDir(), "scan.tar.zst")
+	w, err := bundle.Create(bundlePath, nil)
+	require.NoError(t, err)
+	require.NoError(t, w.AddFile("config.env", filepath.Join(inputDir, "config.env")))
+	_, err = w.Close(json.RawMessage(`{"branch":"main"}`))
+	require.NoError(t, err)
+
+	logger := zerolog.Nop()
+	mockUserInterface := mocks.NewMockUserInterface(ctrl)
+	var warning error
+	mockUserInterface.EXPECT().OutputError(gomock.Any()).DoAndReturn(func(err error, _ ...any) error {
+		warning = err
+		return nil
+	})
+	u := &CLIUserInterface{logger: &logger, ui: mockUserInterface}
+
+	manifest, bc, err := extractBundle(bundlePath, t.TempDir(), nil, u, NewErrorFactory(&logger))
+	require.NoError(t, err)
+	assert.Len(t, manifest.Files, 1)
+	assert.Equal(t, "main", bc.Branch)
+	require.Error(t, warning)
+	assert.Contains(t, warning.Error(), "is not verified, as --bundle-verify-key is not set")
+}
+
+func TestUploadBundleWorkflow_FFIsFalse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockConfig := configuration.New()
+	mockConfig.Set(FeatureFlagIsSecretsEnabled, false)
+	mockIctx := setupMockIctx(ctrl, mockConfig)
+
+	_, err := UploadBundleWorkflow(mockIctx, []workflow.Data{})
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, FeatureNotEnabledMsg)
+}
+
+func TestBundleExport_ToWorkflowData_Text(t *testing.T) {
+	export := bundleExport{Path: "scan.tar.zst", Files: 3, Signed: true}
+
+	data, err := export.toWorkflowData(&url.URL{}, false)
+	require.NoError(t, err)
+
+	text := string(data.GetPayload().([]byte))
+	assert.Contains(t, text, "Scan bundle written to scan.tar.zst with 3 files, signed.")
+	assert.Contains(t, text, "snyk secrets upload-bundle scan.tar.zst")
+	assert.Equal(t, contentTypeText, data.GetContentType())
+}
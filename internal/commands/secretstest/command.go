@@ -4,19 +4,23 @@ package secretstest
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/rs/zerolog"
 	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/utils/ufm"
 	"github.com/snyk/go-application-framework/pkg/workflow"
 
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
 	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
 	"github.com/snyk/cli-extension-secrets/internal/clients/upload"
 	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
@@ -25,21 +29,21 @@ import (
 
 // Secrets workflow constants.
 const (
-	FilterAndUploadFilesTimeout = 30 * time.Second
-	LogFieldCount               = "count"
-	ReportURL                   = "report-url"
+	LogFieldCount = "count"
+	ReportURL     = "report-url"
 )
 
 // ReportConfig holds the configuration for the --report flag and related project attributes.
+// It is written to scan bundles, except for the project page URL which depends on the host testing the bundle.
 type ReportConfig struct {
-	Report                     bool
-	TargetName                 string
-	TargetReference            string
-	ProjectTags                string
-	ProjectBusinessCriticality string
-	ProjectEnvironment         string
-	ProjectLifecycle           string
-	ProjectPageURL             *string
+	Report                     bool    `json:"report"`
+	TargetName                 string  `json:"targetName,omitempty"`
+	TargetReference            string  `json:"targetReference,omitempty"`
+	ProjectTags                string  `json:"projectTags,omitempty"`
+	ProjectBusinessCriticality string  `json:"projectBusinessCriticality,omitempty"`
+	ProjectEnvironment         string  `json:"projectEnvironment,omitempty"`
+	ProjectLifecycle           string  `json:"projectLifecycle,omitempty"`
+	ProjectPageURL             *string `json:"-"`
 }
 
 // CommandArgs holds the arguments required to construct a Command.
@@ -56,6 +60,39 @@ type CommandArgs struct {
 	ErrorFactory      *ErrorFactory
 	SeverityThreshold string
 	ReportConfig      ReportConfig
+	GitRootDir        string
+	Blame             bool
+	GroupBy           string
+	NoWait            bool
+	AllowPartial      bool
+	KeywordPrefilter  bool
+	MinimalUpload     bool
+	TextReport        bool
+	SARIFReport       bool
+	Color             bool
+	Timeouts          PhaseTimeouts
+	// ProgressStream receives the progress of the run as NDJSON, if set.
+	ProgressStream io.Writer
+	// UploadManifestPath is where the upload manifest is written, if set.
+	UploadManifestPath string
+	// JUnitOutputPath is where the JUnit report is written, if set.
+	JUnitOutputPath string
+	// JUnitIncludePassing lists the files without findings as passing test cases of the JUnit report.
+	JUnitIncludePassing bool
+	// GitLabOutputPath is where the GitLab secret detection report is written, if set.
+	GitLabOutputPath string
+	// HTMLOutputPath is where the HTML report is written, if set.
+	HTMLOutputPath string
+	// Annotations is the format of the CI annotations written for findings, if set.
+	Annotations string
+	// StepSummaryPath is the file the markdown summary of findings is appended to, if set.
+	StepSummaryPath string
+	// FormatTemplate renders the findings in place of the text report, or to TemplateOutputPath if set.
+	FormatTemplate *template.Template
+	// TemplateOutputPath is where the output of FormatTemplate is written, if set.
+	TemplateOutputPath string
+	// HistoryDir is the directory of the scan history the run is recorded in, if set.
+	HistoryDir string
 }
 
 // Command orchestrates file upload, scanning, and output preparation for secrets testing.
@@ -72,6 +109,41 @@ type Command struct {
 	UserInterface     UserInterface
 	SeverityThreshold string
 	ReportConfig      ReportConfig
+	GitRootDir        string
+	Blame             bool
+	GroupBy           string
+	NoWait            bool
+	AllowPartial      bool
+	KeywordPrefilter  bool
+	MinimalUpload     bool
+	TextReport        bool
+	SARIFReport       bool
+	Color             bool
+	Timeouts          PhaseTimeouts
+	JUnitOutputPath   string
+	GitLabOutputPath  string
+	HTMLOutputPath    string
+	Annotations       string
+	StepSummaryPath   string
+	FormatTemplate    *template.Template
+	// TemplateOutputPath is where the output of FormatTemplate is written instead of printed, if set.
+	TemplateOutputPath string
+	// HistoryDir is the directory of the scan history the run is recorded in, if set.
+	HistoryDir string
+
+	// sourceDir is the directory the paths of findings are relative to, if the files are available locally.
+	sourceDir string
+	// fingerprints are the fingerprints of findings by finding ID, see addFingerprints.
+	fingerprints map[string]string
+	// owners and blames are the owners and blame of findings by finding ID, see addOwnerInfo and addBlameInfo.
+	owners         map[string][]string
+	blames         map[string][]blameLocation
+	progress       workflowProgress
+	reporter       *progressReporter
+	uploadManifest *uploadManifestRecorder
+	scannedFiles   *scannedFilesRecorder
+	// startedAt is when the run started, the start time of the scan in GitLab reports.
+	startedAt time.Time
 }
 
 type newClientsFunc func(workflow.InvocationContext, string) (*WorkflowClients, error)
@@ -80,16 +152,23 @@ type newClientsFunc func(workflow.InvocationContext, string) (*WorkflowClients,
 type WorkflowClients struct {
 	TestAPIShim testshim.Client
 	FileUpload  upload.Client
+	// Retries counts the requests the clients retried, if set.
+	Retries *retry.Counter
+	// BytesSent counts the bytes of the uploaded files, if set.
+	BytesSent *upload.ByteCounter
 }
 
 // NewWorkflowClients creates the API clients needed for the secrets workflow.
 func NewWorkflowClients(ictx workflow.InvocationContext, orgID string) (*WorkflowClients, error) {
-	uploadClient, err := upload.NewClient(ictx, orgID)
+	retryConfig := buildRetryConfig(ictx.GetConfiguration())
+
+	bytesSent := &upload.ByteCounter{}
+	uploadClient, err := upload.NewClient(ictx, orgID, retryConfig, bytesSent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create upload client: %w", err)
 	}
 
-	testShimClient, err := testshim.NewClient(ictx)
+	testShimClient, err := testshim.NewClient(ictx, retryConfig, buildPollConfig(ictx.GetConfiguration()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create test shim client: %w", err)
 	}
@@ -97,9 +176,21 @@ func NewWorkflowClients(ictx workflow.InvocationContext, orgID string) (*Workflo
 	return &WorkflowClients{
 		TestAPIShim: testShimClient,
 		FileUpload:  uploadClient,
+		Retries:     retryConfig.Counter,
+		BytesSent:   bytesSent,
 	}, nil
 }
 
+// buildRetryConfig applies --max-retries to the default retry limits.
+func buildRetryConfig(config configuration.Configuration) retry.Config {
+	retryConfig := retry.DefaultConfig()
+	if config.IsSet(FlagMaxRetries) {
+		retryConfig.MaxRetries = config.GetInt(FlagMaxRetries)
+	}
+	retryConfig.Counter = &retry.Counter{}
+	return retryConfig
+}
+
 // NewCommand constructs a Command from the provided arguments.
 func NewCommand(args *CommandArgs) (*Command, error) {
 	if args == nil {
@@ -115,20 +206,64 @@ func NewCommand(args *CommandArgs) (*Command, error) {
 		return nil, fmt.Errorf("failed to create clients: %w", err)
 	}
 
-	return &Command{
-		Logger:            logger,
-		Clients:           clients,
-		OrgID:             args.OrgID,
-		RepoURL:           args.RepoURL,
-		Branch:            args.Branch,
-		CommitRef:         args.CommitRef,
-		RootFolderID:      args.RootFolderID,
-		ErrorFactory:      args.ErrorFactory,
-		UserInterface:     args.UserInterface,
-		Excludes:          args.Excludes,
-		SeverityThreshold: args.SeverityThreshold,
-		ReportConfig:      args.ReportConfig,
-	}, nil
+	c := &Command{
+		Logger:             logger,
+		Clients:            clients,
+		OrgID:              args.OrgID,
+		RepoURL:            args.RepoURL,
+		Branch:             args.Branch,
+		CommitRef:          args.CommitRef,
+		RootFolderID:       args.RootFolderID,
+		ErrorFactory:       args.ErrorFactory,
+		UserInterface:      args.UserInterface,
+		Excludes:           args.Excludes,
+		SeverityThreshold:  args.SeverityThreshold,
+		ReportConfig:       args.ReportConfig,
+		GitRootDir:         args.GitRootDir,
+		Blame:              args.Blame,
+		GroupBy:            args.GroupBy,
+		NoWait:             args.NoWait,
+		AllowPartial:       args.AllowPartial,
+		KeywordPrefilter:   args.KeywordPrefilter,
+		MinimalUpload:      args.MinimalUpload,
+		TextReport:         args.TextReport,
+		SARIFReport:        args.SARIFReport,
+		Color:              args.Color,
+		Timeouts:           args.Timeouts,
+		JUnitOutputPath:    args.JUnitOutputPath,
+		GitLabOutputPath:   args.GitLabOutputPath,
+		HTMLOutputPath:     args.HTMLOutputPath,
+		Annotations:        args.Annotations,
+		StepSummaryPath:    args.StepSummaryPath,
+		FormatTemplate:     args.FormatTemplate,
+		TemplateOutputPath: args.TemplateOutputPath,
+		HistoryDir:         args.HistoryDir,
+		startedAt:          time.Now(),
+	}
+
+	if args.UploadManifestPath != "" {
+		apiURL := args.InvocationContext.GetConfiguration().GetString(configuration.API_URL)
+		c.uploadManifest = newUploadManifestRecorder(args.UploadManifestPath, args.OrgID, apiURL)
+	}
+	if args.JUnitOutputPath != "" && args.JUnitIncludePassing {
+		c.scannedFiles = &scannedFilesRecorder{}
+	}
+
+	if args.UserInterface != nil || args.ProgressStream != nil {
+		c.reporter = &progressReporter{
+			stream:    args.ProgressStream,
+			progress:  &c.progress,
+			bytesSent: clients.BytesSent,
+			logger:    logger,
+			interval:  progressInterval,
+			now:       time.Now,
+		}
+		if args.UserInterface != nil {
+			c.reporter.ui = args.UserInterface
+		}
+	}
+
+	return c, nil
 }
 
 // RunWorkflow uploads files, triggers a scan, and returns the formatted results.
@@ -136,56 +271,149 @@ func (c *Command) RunWorkflow(
 	ctx context.Context,
 	inputPath string,
 ) ([]workflow.Data, error) {
+	defer c.recordRetries(ctx)
+
 	uploadRevision, err := c.filterAndUploadFiles(ctx, inputPath)
 	if err != nil {
 		return nil, err
 	}
 
+	return c.runTest(ctx, uploadRevision)
+}
+
+// runTest tests the uploaded files and returns the formatted results, or the test submission with --no-wait.
+func (c *Command) runTest(ctx context.Context, uploadRevision string) ([]workflow.Data, error) {
+	c.progress.uploadRevision.Store(uploadRevision)
+
+	if c.NoWait {
+		return c.submitScan(ctx, uploadRevision)
+	}
+
 	c.UserInterface.SetTitle(TitleScanning)
 	testResult, err := c.triggerScan(ctx, uploadRevision)
 	if err != nil {
 		return nil, err
 	}
 
+	if c.Blame {
+		c.UserInterface.SetTitle(TitleBlaming)
+		c.addBlameInfo(ctx, testResult)
+	}
+	ownerSummaries := c.addOwnerInfo(ctx, testResult)
+	c.addFingerprints(ctx, testResult)
+
 	c.UserInterface.SetTitle(TitleRetrievingResults)
-	output, err := c.prepareOutput(ctx, testResult)
+	output, err := c.prepareOutputForRun(ctx, testResult, ownerSummaries)
 	if err != nil {
 		return nil, c.ErrorFactory.NewPrepareOutputError(err)
 	}
+	if err := c.writeReports(ctx, testResult); err != nil {
+		return nil, err
+	}
+	if err := c.appendHistory(ctx, testResult); err != nil {
+		return nil, err
+	}
+
+	if c.GroupBy == GroupByOwner {
+		output = append(output, c.ownerSummaryOutput(ctx, ownerSummaries)...)
+	}
+	c.flagPartialResults(ctx, output, testResult)
 
 	return output, err
 }
 
 func (c *Command) filterAndUploadFiles(ctx context.Context, inputPath string) (string, error) {
-	instrumentation := cmdctx.Instrumentation(ctx)
+	ctx, cancel := withDeadline(ctx, "the filtering and upload deadline", c.Timeouts.FilterAndUpload)
+	defer cancel()
 
-	textFilesFilter := ff.NewPipeline(
-		ff.WithConcurrency(runtime.NumCPU()),
-		ff.WithExcludeGlobs(c.Excludes),
-		ff.WithFilters(
-			ff.FileSizeFilter(c.Logger),
-			ff.TextFileOnlyFilter(c.Logger),
-		),
-		ff.WithLogger(c.Logger),
-		ff.WithAnalytics(instrumentation),
-	)
-	pathsChan := textFilesFilter.Filter(ctx, []string{inputPath})
+	stopReporting := c.reporter.start(c.progress.uploadPhase)
+	defer stopReporting()
 
+	pathsChan, dir, err := c.filterFiles(ctx, inputPath)
+	if err != nil {
+		if manifestErr := c.writeUploadManifest("", err); manifestErr != nil {
+			return "", manifestErr
+		}
+		return "", err
+	}
+	c.sourceDir = dir
+	pathsChan = c.progress.forwardToUpload(ctx, pathsChan)
+
+	if c.MinimalUpload {
+		stagedPaths, stagingDir, cleanup, err := c.stageMinimalUpload(ctx, pathsChan, dir)
+		if err != nil {
+			if manifestErr := c.writeUploadManifest("", err); manifestErr != nil {
+				return "", manifestErr
+			}
+			return "", c.ErrorFactory.NewUploadError(err)
+		}
+		defer cleanup()
+		return c.uploadFiles(ctx, stagedPaths, stagingDir)
+	}
+	return c.uploadFiles(ctx, pathsChan, dir)
+}
+
+// filterFiles starts filtering the files of inputPath. It returns the paths of the files to test
+// and the directory they are relative to.
+func (c *Command) filterFiles(ctx context.Context, inputPath string) (<-chan string, string, error) {
 	// for file inputPath we need to compute the relativity of the file path w.r.t. the file's dir
 	dir := inputPath
 	ok, err := isFile(inputPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine if inputPath is a file: %w", err)
+		return nil, "", fmt.Errorf("failed to determine if inputPath is a file: %w", err)
 	}
 	if ok {
 		dir = filepath.Dir(inputPath)
 	}
 
+	filters := []ff.FileFilter{
+		ff.FileSizeFilter(c.Logger),
+		ff.TextFileOnlyFilter(c.Logger),
+	}
+	// the keyword filter reads the files, so it only checks the text files of a supported size
+	if c.KeywordPrefilter {
+		filters = append(filters, ff.KeywordFilter(c.Logger))
+	}
+
+	opts := []ff.Option{
+		ff.WithConcurrency(runtime.NumCPU()),
+		ff.WithExcludeGlobs(c.Excludes),
+		ff.WithFilters(filters...),
+		ff.WithLogger(c.Logger),
+		ff.WithAnalytics(cmdctx.Instrumentation(ctx)),
+		ff.WithProgress(&c.progress),
+	}
+	if c.uploadManifest != nil {
+		opts = append(opts, ff.WithDecisions(c.uploadManifest))
+	}
+	textFilesFilter := ff.NewPipeline(opts...)
+	return textFilesFilter.Filter(ctx, []string{inputPath}), dir, nil
+}
+
+// uploadFiles uploads the files at paths, relative to dir, and returns the upload revision.
+func (c *Command) uploadFiles(ctx context.Context, paths <-chan string, dir string) (string, error) {
+	instrumentation := cmdctx.Instrumentation(ctx)
+
+	if c.uploadManifest != nil {
+		paths = c.uploadManifest.record(ctx, paths, dir)
+	}
+	if c.scannedFiles != nil {
+		paths = c.scannedFiles.record(ctx, paths, dir)
+	}
+
 	uploadStartTime := time.Now()
-	uploadRevision, err := c.Clients.FileUpload.CreateRevisionFromChan(ctx, pathsChan, dir)
+	uploadRevision, err := c.Clients.FileUpload.CreateRevisionFromChan(ctx, paths, dir)
 	if err != nil {
+		err = c.interrupted(ctx, c.progress.uploadPhase(), err)
+		if manifestErr := c.writeUploadManifest("", err); manifestErr != nil {
+			return "", manifestErr
+		}
 		return "", c.ErrorFactory.NewUploadError(err)
 	}
+	if err := c.writeUploadManifest(uploadRevision.RevisionID.String(), nil); err != nil {
+		return "", err
+	}
+	c.progress.filesUploaded.Store(int64(uploadRevision.UploadedFilesCount))
 	if instrumentation != nil {
 		instrumentation.RecordFileUploadTimeMs(uploadStartTime)
 	}
@@ -193,20 +421,41 @@ func (c *Command) filterAndUploadFiles(ctx context.Context, inputPath string) (s
 	return uploadRevision.RevisionID.String(), nil
 }
 
+// recordRetries records the requests the clients had to retry, whether or not the run succeeded.
+func (c *Command) recordRetries(ctx context.Context) {
+	instrumentation := cmdctx.Instrumentation(ctx)
+	if instrumentation == nil || c.Clients.Retries == nil {
+		return
+	}
+	instrumentation.RecordRetryCount(c.Clients.Retries.Load())
+}
+
+// buildPollConfig applies --poll-interval and --max-poll-interval, validated beforehand, to the default polling bounds.
+func buildPollConfig(config configuration.Configuration) testshim.PollConfig {
+	pollConfig := testshim.DefaultPollConfig()
+
+	if interval, err := parseDurationFlag(config, FlagPollInterval); err == nil && interval > 0 {
+		pollConfig.Interval = interval
+		pollConfig.MaxInterval = max(pollConfig.MaxInterval, interval)
+	}
+	if maxInterval, err := parseDurationFlag(config, FlagMaxPollInterval); err == nil && maxInterval > 0 {
+		pollConfig.MaxInterval = maxInterval
+		pollConfig.Interval = min(pollConfig.Interval, maxInterval)
+	}
+
+	return pollConfig
+}
+
 //nolint:ireturn // supposed to return interface.
 func (c *Command) triggerScan(ctx context.Context, uploadRevision string) (testapi.TestResult, error) {
 	instrumentation := cmdctx.Instrumentation(ctx)
 	scanStartTime := time.Now()
 
-	testResource, err := createTestResource(uploadRevision, c.RepoURL, c.RootFolderID, c.Branch, c.CommitRef)
+	param, err := c.buildStartTestParams(uploadRevision)
 	if err != nil {
-		return nil, c.ErrorFactory.NewTestResourceError(err)
+		return nil, err
 	}
 
-	testConfig := buildTestConfiguration(&c.ReportConfig, c.SeverityThreshold, c.Branch)
-	resources := []testapi.TestResourceCreateItem{testResource}
-	param := testapi.NewStartTestParamsFromResources(c.OrgID, &resources, testConfig)
-
 	testResult, err := c.executeTest(ctx, param)
 	if err != nil {
 		return nil, c.ErrorFactory.NewExecuteTestError(err)
@@ -218,6 +467,43 @@ func (c *Command) triggerScan(ctx context.Context, uploadRevision string) (testa
 	return testResult, nil
 }
 
+// submitScan starts a test without waiting for it and returns the IDs needed to retrieve its results later.
+func (c *Command) submitScan(ctx context.Context, uploadRevision string) ([]workflow.Data, error) {
+	param, err := c.buildStartTestParams(uploadRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	testID, err := c.Clients.TestAPIShim.SubmitTest(ctx, param)
+	if err != nil {
+		return nil, c.ErrorFactory.NewExecuteTestError(fmt.Errorf("failed to start test: %w", err))
+	}
+	c.Logger.Info().Str("testID", testID.String()).Str("uploadRevision", uploadRevision).Msg("Test submitted without waiting for results")
+
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil {
+		return nil, fmt.Errorf("invocation context is nil")
+	}
+
+	submission := testSubmission{TestID: testID.String(), UploadRevision: uploadRevision}
+	data, err := submission.toWorkflowData(ictx.GetWorkflowIdentifier(), ictx.GetConfiguration().GetBool(FlagJSON))
+	if err != nil {
+		return nil, c.ErrorFactory.NewPrepareOutputError(err)
+	}
+	return []workflow.Data{data}, nil
+}
+
+func (c *Command) buildStartTestParams(uploadRevision string) (testapi.StartTestParams, error) {
+	testResource, err := createTestResource(uploadRevision, c.RepoURL, c.RootFolderID, c.Branch, c.CommitRef)
+	if err != nil {
+		return testapi.StartTestParams{}, c.ErrorFactory.NewTestResourceError(err)
+	}
+
+	testConfig := buildTestConfiguration(&c.ReportConfig, c.SeverityThreshold, c.Branch)
+	resources := []testapi.TestResourceCreateItem{testResource}
+	return testapi.NewStartTestParamsFromResources(c.OrgID, &resources, testConfig), nil
+}
+
 func createTestResource(revisionID, repoURL, rootFolderID, branch, commitRef string) (testapi.TestResourceCreateItem, error) {
 	uploadResource := testapi.UploadResource{
 		ContentType:   testapi.UploadResourceContentTypeSource,
@@ -301,38 +587,76 @@ func (c *Command) prepareOutput(
 
 //nolint:ireturn // supposed to return interface.
 func (c *Command) executeTest(ctx context.Context, params testapi.StartTestParams) (testapi.TestResult, error) {
-	testHandle, err := c.Clients.TestAPIShim.StartTest(ctx, params)
+	scanCtx, cancelScan := withDeadline(ctx, "the scan deadline", c.Timeouts.Scan)
+	defer cancelScan()
+
+	testHandle, err := c.Clients.TestAPIShim.StartTest(scanCtx, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start test: %w", err)
+		return nil, c.interrupted(scanCtx, PhaseScan, fmt.Errorf("failed to start test: %w", err))
 	}
+	c.progress.scanState.Store(ScanStateStarted)
+	c.progress.setPhase(PhaseScan)
+	stopReporting := c.reporter.start(c.progress.currentPhase)
+	defer stopReporting()
 
-	if waitErr := testHandle.Wait(ctx); waitErr != nil {
-		return nil, fmt.Errorf("test run failed: %w", waitErr)
+	if waitErr := testHandle.Wait(scanCtx); waitErr != nil {
+		return nil, c.interrupted(scanCtx, PhaseScan, fmt.Errorf("test run failed: %w", waitErr))
 	}
 
-	finalResult := testHandle.Result()
+	return c.retrieveTestResult(ctx, testHandle.Result())
+}
+
+// retrieveTestResult checks a completed test within the deadline of the results phase.
+//
+//nolint:ireturn // supposed to return interface.
+func (c *Command) retrieveTestResult(ctx context.Context, finalResult testapi.TestResult) (testapi.TestResult, error) {
+	ctx, cancel := withDeadline(ctx, "the results deadline", c.Timeouts.Results)
+	defer cancel()
+	c.progress.setPhase(PhaseResults)
+
+	testResult, err := c.checkTestResult(ctx, finalResult)
+	if err != nil {
+		return nil, c.interrupted(ctx, PhaseResults, err)
+	}
+	return testResult, nil
+}
+
+// checkTestResult verifies that a completed test succeeded and that all of its findings could be retrieved.
+//
+//nolint:ireturn // supposed to return interface.
+func (c *Command) checkTestResult(ctx context.Context, finalResult testapi.TestResult) (testapi.TestResult, error) {
 	if finalResult == nil {
 		return nil, fmt.Errorf("test completed but no result was returned")
 	}
 
-	if finalResult.GetExecutionState() == testapi.TestExecutionStatesErrored {
+	state := finalResult.GetExecutionState()
+	c.progress.scanState.Store(string(state))
+	if state == testapi.TestExecutionStatesErrored {
 		apiErrors := finalResult.GetErrors()
 		if apiErrors != nil && len(*apiErrors) > 0 {
 			var errorMessages []string
 			for _, apiError := range *apiErrors {
 				errorMessages = append(errorMessages, apiError.Detail)
 			}
-			return nil, fmt.Errorf("test execution error: %v", strings.Join(errorMessages, "; "))
+			err := fmt.Errorf("test execution error: %v", strings.Join(errorMessages, "; "))
+			if apiErr := c.ErrorFactory.NewTestAPIError(0, testshim.ResourceTest, *apiErrors, err); apiErr != nil {
+				return nil, apiErr
+			}
+			return nil, err
 		}
 		return nil, fmt.Errorf("test execution error: %v", "an unknown error occurred")
 	}
 
 	findingsData, complete, err := finalResult.Findings(ctx)
+	c.progress.findings.Store(int64(len(findingsData)))
 	if err != nil {
 		c.Logger.Error().Err(err).Msg("Error fetching findings")
 		if !complete && len(findingsData) > 0 {
 			c.Logger.Warn().Int(LogFieldCount, len(findingsData)).Msg("Partial findings retrieved as an error occurred")
 		}
+		if c.AllowPartial {
+			return c.partialResult(finalResult, findingsData), nil
+		}
 		return nil, fmt.Errorf("test execution error: test completed but findings could not be retrieved: %w", err)
 	}
 
@@ -340,6 +664,9 @@ func (c *Command) executeTest(ctx context.Context, params testapi.StartTestParam
 		if len(findingsData) > 0 {
 			c.Logger.Warn().Int(LogFieldCount, len(findingsData)).Msg("Partial findings retrieved; findings retrieval incomplete")
 		}
+		if c.AllowPartial {
+			return c.partialResult(finalResult, findingsData), nil
+		}
 		return nil, fmt.Errorf("test execution error: test completed but findings could not be retrieved")
 	}
 
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -82,6 +83,7 @@ func TestCommand_RunWorkflow_Success(t *testing.T) {
 	mockTestResult.EXPECT().Get(testapi.TestResultTestFacts).Return(nil)
 	mockTestResult.EXPECT().Get(testapi.TestResultMetadata).Return(map[string]interface{}{})
 	mockTestResult.EXPECT().Get(testapi.TestResultComponents).Return(&[]testapi.TestComponent{})
+	expectFindingsMetadata(mockTestResult)
 
 	// Mock CLIUserInterface calls
 	mockUI.EXPECT().SetTitle(gomock.Any()).AnyTimes()
@@ -874,6 +876,7 @@ func setupSuccessfulRunWithParamCapture(
 	mockTestResult.EXPECT().Get(testapi.TestResultTestFacts).Return(nil)
 	mockTestResult.EXPECT().Get(testapi.TestResultMetadata).Return(map[string]interface{}{})
 	mockTestResult.EXPECT().Get(testapi.TestResultComponents).Return(&[]testapi.TestComponent{})
+	expectFindingsMetadata(mockTestResult)
 
 	mockUI.EXPECT().SetTitle(gomock.Any()).AnyTimes()
 	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{})
@@ -915,4 +918,37 @@ func setupMockTestResultForPrepareOutput(m *gafclientmocks.MockTestResult) {
 	m.EXPECT().Get(testapi.TestResultTestFacts).Return(nil).AnyTimes()
 	m.EXPECT().Get(testapi.TestResultMetadata).Return(map[string]interface{}{}).AnyTimes()
 	m.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesFinished).AnyTimes()
+	expectFindingsMetadata(m)
+}
+
+// expectFindingsMetadata allows the per-finding metadata, such as fingerprints, to be attached to the test result.
+func expectFindingsMetadata(m *gafclientmocks.MockTestResult) {
+	m.EXPECT().GetMetadataValue(FindingsMetadata).Return(nil).AnyTimes()
+	m.EXPECT().SetMetadata(FindingsMetadata, gomock.Any()).AnyTimes()
+}
+
+func TestCommand_FilterFiles_KeywordPrefilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"config.env": "DB_PASSWORD=hunter2", "main.go": "package main\n\nfunc main() {}\n"})
+
+	for _, prefilter := range []bool{false, true} {
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.KeywordPrefilter = prefilter
+
+		paths, _, err := cmd.filterFiles(t.Context(), dir)
+		require.NoError(t, err)
+		var kept []string
+		for path := range paths {
+			kept = append(kept, filepath.Base(path))
+		}
+
+		if prefilter {
+			assert.Equal(t, []string{"config.env"}, kept)
+		} else {
+			assert.ElementsMatch(t, []string{"config.env", "main.go"}, kept)
+		}
+	}
 }
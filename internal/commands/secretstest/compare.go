@@ -0,0 +1,443 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/utils/ufm"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/instrumentation"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// compareFailureMsg is the message of errors reading the compared results.
+const compareFailureMsg = "failed to read the compared results"
+
+// CompareWorkflowID is the unique identifier for the secrets compare workflow.
+var CompareWorkflowID = workflow.NewWorkflowIdentifier("secrets.compare")
+
+// comparedInput is one of the two compared tests: the JSON output of a test, or the ID of a test.
+type comparedInput struct {
+	name   string
+	path   string
+	testID uuid.UUID
+}
+
+// comparison is the JSON output of the secrets compare command.
+type comparison struct {
+	Old      string            `json:"old"`
+	New      string            `json:"new"`
+	Summary  map[string]int    `json:"summary"`
+	Findings []comparedFinding `json:"findings"`
+}
+
+// comparedFinding is a finding of either compared test in the JSON output, see report.ComparedFinding.
+type comparedFinding struct {
+	State             string             `json:"state"`
+	Fingerprint       string             `json:"fingerprint,omitempty"`
+	Key               string             `json:"key,omitempty"`
+	RuleID            string             `json:"ruleId,omitempty"`
+	Title             string             `json:"title"`
+	Severity          string             `json:"severity"`
+	Ignored           bool               `json:"ignored"`
+	Locations         []comparedLocation `json:"locations"`
+	PreviousLocations []comparedLocation `json:"previousLocations,omitempty"`
+}
+
+type comparedLocation struct {
+	FilePath   string `json:"filePath"`
+	FromLine   int    `json:"fromLine"`
+	FromColumn int    `json:"fromColumn,omitempty"`
+	ToLine     int    `json:"toLine,omitempty"`
+	ToColumn   int    `json:"toColumn,omitempty"`
+}
+
+// GetSecretsCompareFlagSet returns the flag set for the secrets compare command.
+func GetSecretsCompareFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet("snyk-cli-extension-secrets-compare", pflag.ExitOnError)
+
+	flagSet.Bool(FlagJSON, false, "Print the comparison on the console as a JSON data structure.")
+	flagSet.Bool(FlagSARIF, false, "Return the comparison in SARIF format, with the state of every finding as its baseline state.")
+	flagSet.String(FlagJSONFileOutput, "",
+		"Save the comparison as a JSON data structure directly to the specified file, regardless of whether or not you use the --json option.")
+	flagSet.String(FlagSARIFFileOutput, "",
+		"Save the comparison in SARIF format directly to the specified file, regardless of whether or not you use the --sarif option.")
+	flagSet.String(FlagSeverityThreshold, "", "Compare only findings at the specified level or higher.")
+	flagSet.Int(FlagMaxRetries, retry.DefaultMaxRetries, "The number of times a request failing transiently is retried.")
+	addPollFlags(flagSet)
+	flagSet.String(FlagTimeout, "", "Abort if the results are not available within the given duration, e.g. 600 (seconds) or 10m.")
+	addScanTimeoutFlags(flagSet)
+
+	return flagSet
+}
+
+// CompareWorkflow is the entry point for the secrets compare workflow.
+// It compares the findings of two tests, given as their JSON output or test IDs, and reports the new, fixed, moved
+// and unchanged findings. The command fails like a test when there are new findings.
+func CompareWorkflow(
+	ictx workflow.InvocationContext,
+	_ []workflow.Data,
+) ([]workflow.Data, error) {
+	config := ictx.GetConfiguration()
+	logger := ictx.GetEnhancedLogger()
+	errorFactory := NewErrorFactory(logger)
+
+	u := NewUI(ictx)
+	u.SetTitle(TitleValidating)
+	defer u.Clear()
+
+	inputs, err := validateCompareInput(config, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseDurationFlag(config, FlagTimeout)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	phaseTimeouts, err := parsePhaseTimeouts(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	ctx, cancel := newWorkflowContext(timeout)
+	defer cancel()
+	ctx = cmdctx.WithIctx(ctx, ictx)
+	ctx = cmdctx.WithInstrumentation(ctx, instrumentation.NewGAFInstrumentation(ictx.GetAnalytics()))
+
+	c, err := newCompareCommand(ctx, ictx, inputs, phaseTimeouts, u, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	u.SetTitle(TitleRetrievingResults)
+	var findings [2][]report.Finding
+	for i, input := range inputs {
+		findings[i], err = c.comparedFindings(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		findings[i] = report.FilterBySeverity(findings[i], config.GetString(FlagSeverityThreshold))
+	}
+
+	compared := report.Compare(findings[0], findings[1])
+	logger.Info().Interface("states", report.CountStates(compared)).Msg("Compared secrets test results")
+
+	output, err := c.prepareComparisonOutput(ictx, config, inputs, compared)
+	if err != nil {
+		return nil, errorFactory.NewPrepareOutputError(err)
+	}
+	return output, nil
+}
+
+// newCompareCommand returns the command retrieving the compared tests given by ID. The organization is only
+// required, and the feature flag only checked, when a test ID is compared.
+func newCompareCommand(
+	ctx context.Context,
+	ictx workflow.InvocationContext,
+	inputs [2]comparedInput,
+	timeouts PhaseTimeouts,
+	u *CLIUserInterface,
+	errorFactory *ErrorFactory,
+) (*Command, error) {
+	config := ictx.GetConfiguration()
+	if inputs[0].testID == uuid.Nil && inputs[1].testID == uuid.Nil {
+		return &Command{Logger: ictx.GetEnhancedLogger(), ErrorFactory: errorFactory, UserInterface: u}, nil
+	}
+
+	if !config.GetBool(FeatureFlagIsSecretsEnabled) {
+		return nil, errorFactory.NewFeatureNotEnabledError(FeatureNotEnabledMsg)
+	}
+	orgID := config.GetString(configuration.ORGANIZATION)
+	if orgID == "" {
+		return nil, errorFactory.NewValidationFailureError(NoOrgProvidedMsg)
+	}
+	if err := validateOrg(orgID); err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	orgID, err := resolveOrgID(ctx, ictx, orgID, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewCommand(&CommandArgs{
+		InvocationContext: ictx,
+		UserInterface:     u,
+		OrgID:             orgID,
+		GetClients:        newResultClients,
+		ErrorFactory:      errorFactory,
+		Timeouts:          timeouts,
+	})
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnableToInitializeMsg)
+	}
+	return c, nil
+}
+
+func validateCompareInput(config configuration.Configuration, errorFactory *ErrorFactory) ([2]comparedInput, error) {
+	var inputs [2]comparedInput
+
+	args := config.GetStringSlice(configuration.INPUT_DIRECTORY)
+	if len(args) != 2 {
+		return inputs, errorFactory.NewValidationFailureError(
+			"Pass exactly two results to compare, the old one first: the paths of the JSON output of tests, or test IDs.")
+	}
+	for i, arg := range args {
+		input, err := parseComparedInput(arg)
+		if err != nil {
+			return inputs, errorFactory.NewValidationFailureError(err.Error())
+		}
+		inputs[i] = input
+	}
+	// the findings of test IDs are fingerprinted by their keys, as the tested files are not available, so they never
+	// match the fingerprints of the same findings in the JSON output of a test
+	if (inputs[0].testID == uuid.Nil) != (inputs[1].testID == uuid.Nil) {
+		return inputs, errorFactory.NewValidationFailureError(fmt.Sprintf(
+			"Cannot compare %s with %s: compare either two test IDs or the JSON output of two tests.", inputs[0].name, inputs[1].name))
+	}
+
+	if config.IsSet(FlagSeverityThreshold) {
+		flag := flagWithOptions{name: FlagSeverityThreshold, singleChoice: true, validOptions: validOptionsCriticality}
+		if err := validateFlagValue(config, flag); err != nil {
+			return inputs, errorFactory.NewValidationFailureError(err.Error())
+		}
+	}
+
+	if err := validateComparisonOutput(config); err != nil {
+		return inputs, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	for _, validate := range []func(configuration.Configuration) error{validateFileOutputPaths, validateMaxRetries, validatePollIntervals} {
+		if err := validate(config); err != nil {
+			return inputs, errorFactory.NewValidationFailureError(err.Error())
+		}
+	}
+	return inputs, nil
+}
+
+// parseComparedInput returns the compared test given by arg: the path of an existing file, or else a test ID.
+func parseComparedInput(arg string) (comparedInput, error) {
+	if info, err := os.Stat(arg); err == nil {
+		if info.IsDir() {
+			errMsg := fmt.Sprintf("Cannot compare %s: it is a directory, not the JSON output of a test.", arg)
+			return comparedInput{}, errors.New(errMsg)
+		}
+		return comparedInput{name: arg, path: arg}, nil
+	}
+
+	testID, err := uuid.Parse(strings.TrimSpace(arg))
+	if err != nil {
+		errMsg := fmt.Sprintf("Cannot compare %s: it is neither a file nor a test ID.", arg)
+		return comparedInput{}, errors.New(errMsg)
+	}
+	return comparedInput{name: testID.String(), testID: testID}, nil
+}
+
+// validateComparisonOutput rejects combining JSON and SARIF output. The CLI writes every JSON-based output to the
+// SARIF writers as well, so only one of them can be rendered.
+func validateComparisonOutput(config configuration.Configuration) error {
+	jsonFlag, sarifFlag := "", ""
+	if config.GetBool(FlagJSON) {
+		jsonFlag = FlagJSON
+	} else if config.GetString(FlagJSONFileOutput) != "" {
+		jsonFlag = FlagJSONFileOutput
+	}
+	if config.GetBool(FlagSARIF) {
+		sarifFlag = FlagSARIF
+	} else if config.GetString(FlagSARIFFileOutput) != "" {
+		sarifFlag = FlagSARIFFileOutput
+	}
+	if jsonFlag == "" || sarifFlag == "" {
+		return nil
+	}
+
+	errMsg := fmt.Sprintf("Invalid use of --%s, it cannot be combined with the --%s option when comparing results", sarifFlag, jsonFlag)
+	return errors.New(errMsg)
+}
+
+// comparedFindings returns the findings of a compared test, along with their fingerprints.
+func (c *Command) comparedFindings(ctx context.Context, input comparedInput) ([]report.Finding, error) {
+	if input.testID != uuid.Nil {
+		testResult, err := c.fetchTestResult(ctx, input.testID)
+		if err != nil {
+			return nil, c.ErrorFactory.NewExecuteTestError(err)
+		}
+		// the tested files are not available, so fingerprints are derived from the keys of findings
+		c.addFingerprints(ctx, testResult)
+		findings, err := c.reportFindings(ctx, testResult)
+		if err != nil {
+			return nil, c.ErrorFactory.NewGeneralSecretsFailureError(err, compareFailureMsg)
+		}
+		return findings, nil
+	}
+
+	findings, err := readJSONFindings(ctx, input.path)
+	if err != nil {
+		return nil, c.ErrorFactory.NewValidationFailureError(fmt.Sprintf("Cannot compare %s: %s.", input.name, err))
+	}
+	return findings, nil
+}
+
+// readJSONFindings returns the findings of the test results in the JSON output of a test, along with the
+// fingerprints in their metadata.
+func readJSONFindings(ctx context.Context, path string) ([]report.Finding, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the file: %w", err)
+	}
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("{")) {
+		content = append(append([]byte("["), content...), ']')
+	}
+	if !json.Valid(content) {
+		return nil, errors.New("it is not the JSON output of a test")
+	}
+
+	testResults := ufm.GetTestResultsFromWorkflowData(
+		workflow.NewData(workflow.NewTypeIdentifier(CompareWorkflowID, "input"), content_type.UFM_RESULT, content))
+	if len(testResults) == 0 {
+		return nil, errors.New("it holds no test results")
+	}
+
+	var findings []report.Finding
+	for _, testResult := range testResults {
+		apiFindings, _, err := testResult.Findings(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read findings: %w", err)
+		}
+		fingerprints := metadataFingerprints(testResult)
+		for _, finding := range report.FromTestAPI(apiFindings) {
+			id := finding.ID
+			if id == "" {
+				id = finding.Key
+			}
+			finding.Fingerprint = fingerprints[id]
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// metadataFingerprints returns the fingerprints in the findings metadata of a test result read from JSON, by
+// finding ID.
+func metadataFingerprints(testResult testapi.TestResult) map[string]string {
+	fingerprints := map[string]string{}
+	findingsMeta, ok := testResult.GetMetadataValue(FindingsMetadata).(map[string]any)
+	if !ok {
+		return fingerprints
+	}
+	for id, value := range findingsMeta {
+		meta, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if fingerprint, ok := meta[FingerprintMetadata].(string); ok {
+			fingerprints[id] = fingerprint
+		}
+	}
+	return fingerprints
+}
+
+// prepareComparisonOutput renders the comparison as requested, along with the test summary of the new findings
+// the CLI derives its exit code from. The text report is printed unless JSON or SARIF are.
+func (c *Command) prepareComparisonOutput(
+	ictx workflow.InvocationContext,
+	config configuration.Configuration,
+	inputs [2]comparedInput,
+	compared []report.ComparedFinding,
+) ([]workflow.Data, error) {
+	id := ictx.GetWorkflowIdentifier()
+	var output []workflow.Data
+
+	if !config.GetBool(FlagJSON) && !config.GetBool(FlagSARIF) {
+		var b strings.Builder
+		opts := report.ComparisonTextOptions{OldName: inputs[0].name, NewName: inputs[1].name, Color: useColor()}
+		if err := report.RenderComparisonText(&b, compared, opts); err != nil {
+			return nil, err
+		}
+		output = append(output, workflow.NewData(workflow.NewTypeIdentifier(id, "report"), contentTypeText, []byte(b.String())))
+	}
+
+	switch {
+	case config.GetBool(FlagJSON) || config.GetString(FlagJSONFileOutput) != "":
+		payload, err := json.MarshalIndent(newComparison(inputs, compared), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal comparison: %w", err)
+		}
+		output = append(output, workflow.NewData(workflow.NewTypeIdentifier(id, "comparison"), contentTypeJSON, payload))
+	case config.GetBool(FlagSARIF) || config.GetString(FlagSARIFFileOutput) != "":
+		opts := report.SARIFOptions{}
+		if info := ictx.GetRuntimeInfo(); info != nil {
+			opts.ToolVersion = info.GetVersion()
+		}
+		var b bytes.Buffer
+		if err := report.RenderComparisonSARIF(&b, compared, opts); err != nil {
+			return nil, err
+		}
+		output = append(output, workflow.NewData(workflow.NewTypeIdentifier(id, "sarif"), contentTypeSARIF, b.Bytes()))
+	}
+
+	var newFindings []report.Finding
+	for i := range compared {
+		if compared[i].State == report.StateNew {
+			newFindings = append(newFindings, compared[i].Finding)
+		}
+	}
+	summary, err := c.testSummaryData(id, newFindings)
+	if err != nil {
+		return nil, err
+	}
+	return append(output, summary), nil
+}
+
+func newComparison(inputs [2]comparedInput, compared []report.ComparedFinding) comparison {
+	result := comparison{
+		Old:      inputs[0].name,
+		New:      inputs[1].name,
+		Summary:  map[string]int{},
+		Findings: make([]comparedFinding, 0, len(compared)),
+	}
+	counts := report.CountStates(compared)
+	for _, state := range report.States {
+		result.Summary[state] = counts[state]
+	}
+
+	for i := range compared {
+		finding := &compared[i]
+		result.Findings = append(result.Findings, comparedFinding{
+			State:             finding.State,
+			Fingerprint:       finding.Fingerprint,
+			Key:               finding.Key,
+			RuleID:            finding.RuleID,
+			Title:             finding.Title,
+			Severity:          finding.Severity,
+			Ignored:           finding.Ignored,
+			Locations:         comparedLocations(finding.Locations),
+			PreviousLocations: comparedLocations(finding.PreviousLocations),
+		})
+	}
+	return result
+}
+
+func comparedLocations(locations []report.Location) []comparedLocation {
+	if locations == nil {
+		return nil
+	}
+	result := make([]comparedLocation, 0, len(locations))
+	for _, loc := range locations {
+		result = append(result, comparedLocation(loc))
+	}
+	return result
+}
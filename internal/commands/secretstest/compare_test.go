@@ -0,0 +1,268 @@
+package secretstest
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/json_schemas"
+	"github.com/snyk/go-application-framework/pkg/ui"
+	"github.com/snyk/go-application-framework/pkg/utils/ufm"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// writeComparedResult writes the JSON output of a test with the given findings and their fingerprints by finding ID.
+func writeComparedResult(t *testing.T, findings []testapi.FindingData, fingerprints map[string]string) string {
+	t.Helper()
+
+	findingsMeta := map[string]any{}
+	for id, fingerprint := range fingerprints {
+		findingsMeta[id] = map[string]any{FingerprintMetadata: fingerprint}
+	}
+	content, err := json.Marshal([]map[string]any{{
+		"executionState":   "finished",
+		"findingsComplete": true,
+		"metadata":         map[string]any{FindingsMetadata: findingsMeta},
+		"findings":         findings,
+	}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// movedFindings returns the test findings moved down by lines, and a new finding with another ID and key.
+func movedFindings(t *testing.T, lines int) []testapi.FindingData {
+	t.Helper()
+
+	moved := loadTestFindings(t)
+	var locations []testapi.FindingLocation
+	for _, loc := range moved[0].Attributes.Locations {
+		sourceLoc, err := loc.AsSourceLocation()
+		require.NoError(t, err)
+		sourceLoc.FromLine += lines
+		require.NoError(t, loc.FromSourceLocation(sourceLoc))
+		locations = append(locations, loc)
+	}
+	moved[0].Attributes.Locations = locations
+
+	added := loadTestFindings(t)[0]
+	id := uuid.MustParse("0b3e9a4c-59c7-4bd8-a7a6-51b3bc27c0d1")
+	added.Id = &id
+	added.Attributes.Key = "6a7b4c4e-37f9-5b57-9a1a-0c8f2b6b9e41"
+	return append(moved, added)
+}
+
+func setupCompareIctx(ctrl *gomock.Controller, config configuration.Configuration) workflow.InvocationContext {
+	mockIctx, mockProgressBar := setupMockIctxWithProgressBar(ctrl, config)
+	mockProgressBar.EXPECT().SetTitle(TitleRetrievingResults)
+	mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress)
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{}).AnyTimes()
+	mockIctx.EXPECT().GetRuntimeInfo().Return(nil).AnyTimes()
+	return mockIctx
+}
+
+func TestCompareWorkflow_JSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	findingID := "bdaa4c47-9609-435c-80ef-317586c3a97a"
+	oldPath := writeComparedResult(t, loadTestFindings(t), map[string]string{findingID: "fp-1"})
+	newPath := writeComparedResult(t, movedFindings(t, 2), map[string]string{findingID: "fp-1"})
+
+	config := configuration.New()
+	config.Set(configuration.INPUT_DIRECTORY, []string{oldPath, newPath})
+	config.Set(FlagJSON, true)
+
+	output, err := CompareWorkflow(setupCompareIctx(ctrl, config), nil)
+	require.NoError(t, err)
+	require.Len(t, output, 2)
+
+	assert.Equal(t, contentTypeJSON, output[0].GetContentType())
+	payload, ok := output[0].GetPayload().([]byte)
+	require.True(t, ok)
+	var result comparison
+	require.NoError(t, json.Unmarshal(payload, &result))
+	assert.Equal(t, oldPath, result.Old)
+	assert.Equal(t, map[string]int{report.StateNew: 1, report.StateFixed: 0, report.StateMoved: 1, report.StateUnchanged: 0}, result.Summary)
+	require.Len(t, result.Findings, 2)
+	assert.Equal(t, report.StateNew, result.Findings[0].State)
+	assert.Equal(t, report.StateMoved, result.Findings[1].State)
+	assert.Equal(t, "fp-1", result.Findings[1].Fingerprint)
+	assert.Equal(t, 9, result.Findings[1].Locations[0].FromLine)
+	assert.Equal(t, 7, result.Findings[1].PreviousLocations[0].FromLine)
+
+	assert.Equal(t, content_type.TEST_SUMMARY, output[1].GetContentType())
+	summaryPayload, ok := output[1].GetPayload().([]byte)
+	require.True(t, ok)
+	var summary json_schemas.TestSummary
+	require.NoError(t, json.Unmarshal(summaryPayload, &summary))
+	assert.Equal(t, []json_schemas.TestSummaryResult{{Severity: report.SeverityCritical, Total: 1, Open: 1}}, summary.Results,
+		"only new findings fail the command")
+}
+
+func TestCompareWorkflow_TextAndSARIFFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	newPath := writeComparedResult(t, loadTestFindings(t), nil)
+	oldPath := writeComparedResult(t, movedFindings(t, 0), nil)
+
+	config := configuration.New()
+	config.Set(configuration.INPUT_DIRECTORY, []string{oldPath, newPath})
+	config.Set(FlagSARIFFileOutput, filepath.Join(t.TempDir(), "comparison.sarif"))
+
+	output, err := CompareWorkflow(setupCompareIctx(ctrl, config), nil)
+	require.NoError(t, err)
+	require.Len(t, output, 3)
+
+	text, ok := output[0].GetPayload().([]byte)
+	require.True(t, ok)
+	assert.Contains(t, string(text), "Fixed (1)")
+	assert.Contains(t, string(text), "0 new, 1 fixed, 0 moved, 1 unchanged")
+	assert.Contains(t, string(text), "No new secrets found.")
+
+	assert.Equal(t, contentTypeSARIF, output[1].GetContentType())
+	sarif, ok := output[1].GetPayload().([]byte)
+	require.True(t, ok)
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				BaselineState string `json:"baselineState"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(sarif, &log))
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 2)
+	assert.Equal(t, "absent", log.Runs[0].Results[0].BaselineState)
+	assert.Equal(t, "unchanged", log.Runs[0].Results[1].BaselineState, "findings without fingerprints are matched by key")
+
+	summaryPayload, ok := output[2].GetPayload().([]byte)
+	require.True(t, ok)
+	var summary json_schemas.TestSummary
+	require.NoError(t, json.Unmarshal(summaryPayload, &summary))
+	assert.Empty(t, summary.Results, "no new findings")
+}
+
+func TestCompareWorkflow_InvalidInput(t *testing.T) {
+	resultPath := writeComparedResult(t, loadTestFindings(t), nil)
+	notJSONPath := filepath.Join(t.TempDir(), "results.txt")
+	require.NoError(t, os.WriteFile(notJSONPath, []byte("not json"), 0o600))
+	testID := uuid.NewString()
+
+	testCases := []struct {
+		args        []string
+		config      map[string]any
+		expectedErr string
+		desc        string
+	}{
+		{args: []string{resultPath}, expectedErr: "Pass exactly two results to compare", desc: "single result"},
+		{args: []string{resultPath, "v1.2.0"}, expectedErr: "Cannot compare v1.2.0: it is neither a file nor a test ID.", desc: "unknown result"},
+		{args: []string{resultPath, t.TempDir()}, expectedErr: "it is a directory", desc: "directory"},
+		{
+			args:        []string{testID, resultPath},
+			expectedErr: "Cannot compare " + testID + " with " + resultPath + ": compare either two test IDs or the JSON output of two tests.",
+			desc:        "test ID and json output",
+		},
+		{
+			args:        []string{resultPath, testID},
+			expectedErr: "compare either two test IDs or the JSON output of two tests",
+			desc:        "json output and test ID",
+		},
+		{
+			args:        []string{resultPath, resultPath},
+			config:      map[string]any{FlagJSON: true, FlagSARIFFileOutput: "comparison.sarif"},
+			expectedErr: "Invalid use of --sarif-file-output, it cannot be combined with the --json option when comparing results",
+			desc:        "json and sarif",
+		},
+		{
+			args:        []string{resultPath, resultPath},
+			config:      map[string]any{FlagSeverityThreshold: "urgent"},
+			expectedErr: "Invalid --severity-threshold",
+			desc:        "invalid severity threshold",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			config := setupMockConfig(tc.config)
+			config.Set(configuration.INPUT_DIRECTORY, tc.args)
+
+			_, err := CompareWorkflow(setupMockIctx(ctrl, config), nil)
+			catalogErr := requireCatalogError(t, err)
+			assert.Contains(t, catalogErr.Detail, tc.expectedErr)
+		})
+	}
+
+	t.Run("not json", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		config.Set(configuration.INPUT_DIRECTORY, []string{resultPath, notJSONPath})
+		mockIctx, mockProgressBar := setupMockIctxWithProgressBar(ctrl, config)
+		mockProgressBar.EXPECT().SetTitle(TitleRetrievingResults)
+		mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress)
+
+		_, err := CompareWorkflow(mockIctx, nil)
+		catalogErr := requireCatalogError(t, err)
+		assert.Contains(t, catalogErr.Detail, "it is not the JSON output of a test")
+	})
+}
+
+func TestCompareWorkflow_TestIDRequiresOrg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	config := configuration.New()
+	config.Set(FeatureFlagIsSecretsEnabled, true)
+	config.Set(configuration.INPUT_DIRECTORY, []string{uuid.NewString(), uuid.NewString()})
+
+	_, err := CompareWorkflow(setupMockIctx(ctrl, config), nil)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, NoOrgProvidedMsg)
+}
+
+func TestReadJSONFindings_CLIOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testID := uuid.New()
+	testResult.EXPECT().GetTestID().Return(&testID).AnyTimes()
+	testResult.EXPECT().GetTestConfiguration().Return(&testapi.TestConfiguration{}).AnyTimes()
+	testResult.EXPECT().GetCreatedAt().Return(&time.Time{}).AnyTimes()
+	testResult.EXPECT().GetErrors().Return(nil).AnyTimes()
+	testResult.EXPECT().GetWarnings().Return(nil).AnyTimes()
+	testResult.EXPECT().GetPassFail().Return(nil).AnyTimes()
+	testResult.EXPECT().GetOutcomeReason().Return(nil).AnyTimes()
+	testResult.EXPECT().GetEffectiveSummary().Return(nil).AnyTimes()
+	testResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesFinished).AnyTimes()
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil).AnyTimes()
+	testResult.EXPECT().Get(testapi.TestResultMetadata).Return(map[string]any{
+		FindingsMetadata: map[string]map[string]any{"bdaa4c47-9609-435c-80ef-317586c3a97a": {FingerprintMetadata: "fp-1"}},
+	}).AnyTimes()
+	testResult.EXPECT().Get(gomock.Any()).Return(nil).AnyTimes()
+
+	// the JSON output of the CLI is the serialized test result data
+	data := ufm.CreateWorkflowDataFromTestResults(&url.URL{}, []testapi.TestResult{testResult})
+	require.NotNil(t, data)
+	payload, ok := data.GetPayload().([]byte)
+	require.True(t, ok)
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, payload, 0o600))
+
+	findings, err := readJSONFindings(t.Context(), path)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "fp-1", findings[0].Fingerprint)
+	assert.Equal(t, "private-key", findings[0].RuleID)
+	assert.Len(t, findings[0].Locations, 2)
+}
@@ -0,0 +1,84 @@
+package secretstest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+)
+
+// Phases of the secrets workflow. Filtering and upload run concurrently and share a deadline.
+const (
+	PhaseFiltering = "filtering"
+	PhaseUpload    = "upload"
+	PhaseScan      = "scan"
+	PhaseResults   = "results"
+)
+
+// PhaseTimeouts bounds the duration of the workflow phases. A zero duration disables the deadline.
+type PhaseTimeouts struct {
+	FilterAndUpload time.Duration
+	Scan            time.Duration
+	Results         time.Duration
+}
+
+// parsePhaseTimeouts reads the deadlines of the workflow phases. Phases are unbounded unless their flag is set.
+func parsePhaseTimeouts(config configuration.Configuration) (PhaseTimeouts, error) {
+	var timeouts PhaseTimeouts
+	var err error
+	if timeouts.FilterAndUpload, err = parseDurationFlag(config, FlagUploadTimeout); err != nil {
+		return PhaseTimeouts{}, err
+	}
+	if timeouts.Scan, err = parseDurationFlag(config, FlagScanTimeout); err != nil {
+		return PhaseTimeouts{}, err
+	}
+	if timeouts.Results, err = parseDurationFlag(config, FlagResultsTimeout); err != nil {
+		return PhaseTimeouts{}, err
+	}
+	return timeouts, nil
+}
+
+// deadlineError is the cancellation cause of a context whose deadline elapsed.
+type deadlineError struct {
+	name    string
+	timeout time.Duration
+}
+
+func (e *deadlineError) Error() string {
+	return fmt.Sprintf("%s of %s elapsed", e.name, e.timeout)
+}
+
+func (e *deadlineError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// withDeadline returns a context canceled after timeout, recording name as the cause.
+func withDeadline(ctx context.Context, name string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, &deadlineError{name: name, timeout: timeout})
+}
+
+// newWorkflowContext returns a context that is canceled on SIGINT or SIGTERM, or once timeout elapses if it is positive.
+func newWorkflowContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, cancel := withDeadline(ctx, "--"+FlagTimeout, timeout)
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// interrupted returns a phase error if ctx ended before err occurred, or err otherwise.
+func (c *Command) interrupted(ctx context.Context, phase string, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return c.ErrorFactory.NewPhaseInterruptedError(phase, c.progress.String(), context.Cause(ctx), err)
+}
@@ -0,0 +1,118 @@
+//nolint:testpackage // whitebox testing the phase deadlines
+package secretstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	cli_errors "github.com/snyk/error-catalog-golang-public/cli"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mock_testshim "github.com/snyk/cli-extension-secrets/internal/clients/testshim/mocks"
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+func TestCommand_RunWorkflow_UploadDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	cmd.Timeouts = PhaseTimeouts{FilterAndUpload: 50 * time.Millisecond}
+	ctx := cmdctx.WithIctx(t.Context(), mocks.NewMockInvocationContext(ctrl))
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			for range paths {
+			}
+			// the upload stalls once all files were received
+			<-ctx.Done()
+			return fileupload.UploadResult{}, ctx.Err()
+		})
+
+	_, err := cmd.RunWorkflow(ctx, ".")
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "The upload phase did not complete: the filtering and upload deadline of 50ms elapsed.")
+	assert.Contains(t, catalogErr.Detail, "files passed filtering")
+	assert.ErrorIs(t, catalogErr.Cause, context.DeadlineExceeded)
+	assert.Equal(t, cli_errors.NewConnectionTimeoutError("").ErrorCode, catalogErr.ErrorCode)
+}
+
+func TestCommand_RunWorkflow_ScanCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+	ctx, cancel := context.WithCancelCause(t.Context())
+	ctx = cmdctx.WithIctx(ctx, mocks.NewMockInvocationContext(ctrl))
+
+	revisionID := uuid.New()
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fileupload.UploadResult{RevisionID: revisionID}, nil)
+
+	handle := gafclientmocks.NewMockTestHandle(ctrl)
+	handle.EXPECT().Wait(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+		cancel(errors.New("interrupt signal received"))
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().StartTest(gomock.Any(), gomock.Any()).Return(handle, nil)
+	mockUI.EXPECT().SetTitle(TitleScanning)
+
+	_, err := cmd.RunWorkflow(ctx, ".")
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "The scan phase did not complete: interrupt signal received.")
+	assert.Contains(t, catalogErr.Detail, "upload revision "+revisionID.String()+" was created")
+	assert.Equal(t, cli_errors.NewGeneralSecretsFailureError("").ErrorCode, catalogErr.ErrorCode)
+}
+
+func TestCommand_RetrieveResults_ResultsDeadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+	cmd.Timeouts = PhaseTimeouts{Results: 50 * time.Millisecond}
+	mockUI.EXPECT().SetTitle(TitleRetrievingResults)
+
+	partialFindings := []testapi.FindingData{{}, {}}
+	mockTestResult := gafclientmocks.NewMockTestResult(ctrl)
+	mockTestResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesFinished)
+	mockTestResult.EXPECT().Findings(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]testapi.FindingData, bool, error) {
+		<-ctx.Done()
+		return partialFindings, false, ctx.Err()
+	})
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockTestResult, nil)
+
+	_, err := cmd.RetrieveResults(t.Context(), uuid.New())
+	catalogErr := requireCatalogError(t, err)
+	assert.Equal(t,
+		"The results phase did not complete: the results deadline of 50ms elapsed. Progress so far: 2 findings were retrieved.",
+		catalogErr.Detail)
+}
+
+func TestParsePhaseTimeouts(t *testing.T) {
+	timeouts, err := parsePhaseTimeouts(setupMockConfig(map[string]any{}))
+	require.NoError(t, err)
+	assert.Equal(t, PhaseTimeouts{}, timeouts, "phases are unbounded by default")
+
+	timeouts, err = parsePhaseTimeouts(setupMockConfig(map[string]any{
+		FlagUploadTimeout:  "90",
+		FlagScanTimeout:    "15m",
+		FlagResultsTimeout: "5m",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, PhaseTimeouts{FilterAndUpload: 90 * time.Second, Scan: 15 * time.Minute, Results: 5 * time.Minute}, timeouts)
+
+	_, err = parsePhaseTimeouts(setupMockConfig(map[string]any{FlagScanTimeout: "soon"}))
+	assert.ErrorContains(t, err, "Invalid --scan-timeout")
+}
@@ -1,6 +1,7 @@
 package secretstest
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -10,6 +11,8 @@ import (
 	upload_errors "github.com/snyk/error-catalog-golang-public/uploadrevision"
 
 	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
 )
 
 // User-facing error messages.
@@ -39,8 +42,21 @@ func (ef *ErrorFactory) NewRevisionError(err error) error {
 	return ef.ensureCatalogError(err, "error creating upload revision")
 }
 
-// NewExecuteTestError wraps a test execution failure.
+// NewExecuteTestError wraps a test execution failure, mapping test API responses with a known error to the
+// catalog error with its remediation. These are reported by the test shim client as a testshim.StatusError, and by
+// the test API client of the framework as the catalog errors of the response.
 func (ef *ErrorFactory) NewExecuteTestError(err error) error {
+	var statusErr *testshim.StatusError
+	if errors.As(err, &statusErr) {
+		if apiErr := ef.NewTestAPIError(statusErr.StatusCode, statusErr.Resource, statusErr.Errors, err); apiErr != nil {
+			return apiErr
+		}
+	} else if apiErrors, statusCode := responseErrors(err); len(apiErrors) > 0 {
+		// the framework does not tell which resource the response is about
+		if apiErr := ef.NewTestAPIError(statusCode, "", apiErrors, err); apiErr != nil {
+			return apiErr
+		}
+	}
 	return ef.ensureCatalogError(err, "error executing test")
 }
 
@@ -59,6 +75,47 @@ func (ef *ErrorFactory) NewGeneralSecretsFailureError(err error, msg string) err
 	return ef.ensureCatalogError(err, msg)
 }
 
+// NewPhaseInterruptedError reports a workflow phase that hit its deadline or was canceled, along with the progress made.
+// cause is the reason the phase's context ended and err the error the phase failed with.
+func (ef *ErrorFactory) NewPhaseInterruptedError(phase, progress string, cause, err error) error {
+	reason := "the run was canceled"
+	var deadlineErr *deadlineError
+	switch {
+	case errors.As(cause, &deadlineErr):
+		reason = deadlineErr.Error()
+	case cause != nil && !errors.Is(cause, context.Canceled):
+		reason = cause.Error()
+	}
+
+	detail := fmt.Sprintf("The %s phase did not complete: %s.", phase, reason)
+	if progress != "" {
+		detail += fmt.Sprintf(" Progress so far: %s.", progress)
+	}
+	ef.logger.Error().Err(err).Msg(detail)
+
+	if deadlineErr != nil {
+		return ef.NewPhaseTimeoutError(detail, errors.Join(cause, err))
+	}
+	return cli_errors.NewGeneralSecretsFailureError(detail, snyk_errors.WithCause(errors.Join(cause, err)))
+}
+
+// NewPhaseTimeoutError returns the error of a workflow phase that did not complete before its deadline, or before
+// --timeout elapsed.
+func (ef *ErrorFactory) NewPhaseTimeoutError(detail string, err error) error {
+	return cli_errors.NewConnectionTimeoutError(detail, snyk_errors.WithCause(err))
+}
+
+// NewPartialResultsError returns the warning attached to the output of a run reporting partial results.
+// Its meta holds ExitCodePartialResults so that pipelines can tell partial results from complete ones.
+func (ef *ErrorFactory) NewPartialResultsError(retrieved int) snyk_errors.Error {
+	detail := partialResultsDetail(retrieved)
+	ef.logger.Warn().Msg(detail)
+
+	partialErr := cli_errors.NewGeneralSecretsFailureError(detail, snyk_errors.WithMeta(MetaExitCode, ExitCodePartialResults))
+	partialErr.Level = "warning"
+	return partialErr
+}
+
 // NewFeatureNotEnabledError returns an error indicating the feature flag is disabled.
 func (ef *ErrorFactory) NewFeatureNotEnabledError(msg string) error {
 	return cli_errors.NewFeatureNotEnabledError(msg)
@@ -0,0 +1,55 @@
+package secretstest
+
+import (
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+)
+
+// FindingsMetadata is the test result metadata key holding per-finding enrichment,
+// keyed by finding ID and then by enrichment name.
+const FindingsMetadata = "findings-metadata"
+
+// findingID returns the identifier used to key per-finding metadata.
+func findingID(finding *testapi.FindingData) string {
+	if finding.Id != nil {
+		return finding.Id.String()
+	}
+	if finding.Attributes != nil {
+		return finding.Attributes.Key
+	}
+	return ""
+}
+
+// setFindingMetadata attaches value under key to the finding with the given ID.
+func setFindingMetadata(testResult testapi.TestResult, id, key string, value any) {
+	findings, ok := testResult.GetMetadataValue(FindingsMetadata).(map[string]map[string]any)
+	if !ok {
+		findings = map[string]map[string]any{}
+		testResult.SetMetadata(FindingsMetadata, findings)
+	}
+
+	if findings[id] == nil {
+		findings[id] = map[string]any{}
+	}
+	findings[id][key] = value
+}
+
+// sourceLocations returns the source locations of a finding, skipping other location types.
+func sourceLocations(finding *testapi.FindingData) []testapi.SourceLocation {
+	if finding.Attributes == nil {
+		return nil
+	}
+
+	var locations []testapi.SourceLocation
+	for _, loc := range finding.Attributes.Locations {
+		discriminator, err := loc.Discriminator()
+		if err != nil || discriminator != string(testapi.SourceLocationTypeSource) {
+			continue
+		}
+		sourceLoc, err := loc.AsSourceLocation()
+		if err != nil {
+			continue
+		}
+		locations = append(locations, sourceLoc)
+	}
+	return locations
+}
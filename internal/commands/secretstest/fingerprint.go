@@ -0,0 +1,64 @@
+package secretstest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// FingerprintMetadata is the per-finding metadata key of the stable fingerprint of a finding.
+const FingerprintMetadata = "fingerprint"
+
+// addFingerprints attaches the stable fingerprint of each finding to the finding, and keeps them for the reports. The secrets are hashed with the
+// organization ID as the namespace, so fingerprints only match within an organization. The organization ID is not
+// secret, so fingerprints are pseudonymous rather than anonymous.
+func (c *Command) addFingerprints(ctx context.Context, testResult testapi.TestResult) {
+	apiFindings, _, err := testResult.Findings(ctx)
+	if err != nil {
+		c.Logger.Warn().Err(err).Msg("could not read findings for fingerprints")
+		return
+	}
+
+	opts := report.FingerprintOptions{SourceDir: c.sourceDir, RootFolder: c.RootFolderID, Namespace: c.OrgID}
+	c.fingerprints = map[string]string{}
+	for i := range apiFindings {
+		findings := report.FromTestAPI(apiFindings[i : i+1])
+		if len(findings) == 0 {
+			continue
+		}
+		id := findingID(&apiFindings[i])
+		c.fingerprints[id] = report.Fingerprint(&findings[0], opts)
+		setFindingMetadata(testResult, id, FingerprintMetadata, c.fingerprints[id])
+	}
+}
+
+// reportFindings returns the findings of testResult for the reports, along with their fingerprints, owners and blame.
+func (c *Command) reportFindings(ctx context.Context, testResult testapi.TestResult) ([]report.Finding, error) {
+	apiFindings, _, err := testResult.Findings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read findings: %w", err)
+	}
+	findings := report.FromTestAPI(apiFindings)
+
+	for i := range findings {
+		id := findings[i].ID
+		if id == "" {
+			id = findings[i].Key
+		}
+		findings[i].Fingerprint = c.fingerprints[id]
+		findings[i].Owners = c.owners[id]
+		for _, b := range c.blames[id] {
+			findings[i].Blame = append(findings[i].Blame, report.Blame{
+				FilePath:    b.FilePath,
+				FromLine:    b.FromLine,
+				CommitSHA:   b.CommitSHA,
+				AuthorEmail: b.AuthorEmail,
+				Timestamp:   b.Timestamp,
+			})
+		}
+	}
+	return findings, nil
+}
@@ -1,6 +1,10 @@
 package secretstest
 
-import "github.com/spf13/pflag"
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+)
 
 // CLI flag names for the secrets test command.
 const (
@@ -8,6 +12,13 @@ const (
 	FlagSARIF                      = "sarif"
 	FlagJSONFileOutput             = "json-file-output"
 	FlagSARIFFileOutput            = "sarif-file-output"
+	FlagJUnitFileOutput            = "junit-file-output"
+	FlagJUnitIncludePassing        = "junit-include-passing"
+	FlagGitLabFileOutput           = "gitlab-file-output"
+	FlagHTMLFileOutput             = "html-file-output"
+	FlagAnnotations                = "annotations"
+	FlagFormatTemplate             = "format-template"
+	FlagTemplateFileOutput         = "template-file-output"
 	FlagSeverityThreshold          = "severity-threshold"
 	FlagIncludeIgnores             = "include-ignores"
 	FlagExcludeFilePath            = "exclude"
@@ -19,6 +30,25 @@ const (
 	FlagProjectLifecycle           = "project-lifecycle"
 	FlagProjectTags                = "project-tags"
 	FlagRemoteRepoURL              = "remote-repo-url"
+	FlagRemoteName                 = "remote-name"
+	FlagBlame                      = "blame"
+	FlagGroupBy                    = "group-by"
+	FlagNoWait                     = "no-wait"
+	FlagTimeout                    = "timeout"
+	FlagUploadTimeout              = "upload-timeout"
+	FlagScanTimeout                = "scan-timeout"
+	FlagResultsTimeout             = "results-timeout"
+	FlagMaxRetries                 = "max-retries"
+	FlagPollInterval               = "poll-interval"
+	FlagMaxPollInterval            = "max-poll-interval"
+	FlagProgressJSON               = "progress-json"
+	FlagAllowPartial               = "allow-partial"
+	FlagBundleOutput               = "bundle-output"
+	FlagBundleSigningKey           = "bundle-signing-key"
+	FlagUploadManifest             = "upload-manifest"
+	FlagKeywordPrefilter           = "keyword-prefilter"
+	FlagMinimalUpload              = "minimal-upload"
+	FlagHistoryDir                 = "history-dir"
 )
 
 // GetSecretsTestFlagSet returns the flag set for the secrets test command.
@@ -31,6 +61,12 @@ func GetSecretsTestFlagSet() *pflag.FlagSet {
 		"Save test output as a JSON data structure directly to the specified file, regardless of whether or not you use the --json option.")
 	flagSet.String(FlagSARIFFileOutput, "",
 		"Save test output in SARIF format directly to the specified file, regardless of whether or not you use the --sarif option.")
+	addJUnitFileOutputFlag(flagSet)
+	addJUnitIncludePassingFlag(flagSet)
+	addGitLabFileOutputFlag(flagSet)
+	addHTMLFileOutputFlag(flagSet)
+	addAnnotationsFlag(flagSet)
+	addTemplateFlags(flagSet)
 	flagSet.String(FlagSeverityThreshold, "", "Report only vulnerabilities at the specified level or higher.")
 	flagSet.Bool(FlagIncludeIgnores, false, "Shows all discovered issues, including any that have been previously ignored.")
 	flagSet.String(FlagExcludeFilePath, "", "Ignores all issues originating from the specified file path.")
@@ -42,6 +78,89 @@ func GetSecretsTestFlagSet() *pflag.FlagSet {
 	flagSet.String(FlagProjectLifecycle, "", "Set the project lifecycle project attribute to one or more values (comma-separated).")
 	flagSet.String(FlagProjectTags, "", "Set the project tags to one or more values (comma-separated key value pairs with an \"=\" separator).")
 	flagSet.String(FlagRemoteRepoURL, "", "Set or override the remote URL for the repository.")
+	flagSet.String(FlagRemoteName, "", "Use the URL of the specified git remote. Defaults to the upstream of the current branch, then origin.")
+	flagSet.Bool(FlagBlame, false, "Add the commit, author and date that last changed each finding location to the results.")
+	flagSet.String(FlagGroupBy, "", "Summarize findings by the given attribute. Possible values: owner (from CODEOWNERS).")
+	flagSet.Bool(FlagNoWait, false, "Start the test without waiting for results. Retrieve them later with snyk secrets result --test-id.")
+	flagSet.Int(FlagMaxRetries, retry.DefaultMaxRetries, "The number of times a request failing transiently is retried.")
+	addAllowPartialFlag(flagSet)
+	addPollFlags(flagSet)
+	flagSet.String(FlagTimeout, "", "Abort the test if it does not complete within the given duration, e.g. 600 (seconds) or 10m.")
+	addUploadTimeoutFlag(flagSet)
+	addScanTimeoutFlags(flagSet)
+	flagSet.Bool(FlagProgressJSON, false, "Write the progress of the test to stderr as newline-delimited JSON events.")
+	addUploadManifestFlag(flagSet)
+	addHistoryDirFlag(flagSet)
+	flagSet.Bool(FlagKeywordPrefilter, false,
+		"Only upload files containing a keyword secrets are found next to, such as password, or a high-entropy token.")
+	flagSet.Bool(FlagMinimalUpload, false,
+		"Only upload the lines that could contain a secret and a few lines around them, blanking out all other lines.")
+	flagSet.String(FlagBundleOutput, "",
+		"Write the files to test and their context to the specified bundle instead of testing them, e.g. scan.tar.zst. "+
+			"Test the bundle from a connected host with snyk secrets upload-bundle.")
+	flagSet.String(FlagBundleSigningKey, "", "Sign the bundle written with --bundle-output with the ed25519 private key in the specified PEM file.")
 
 	return flagSet
 }
+
+func addPollFlags(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagPollInterval, "",
+		"The delay before the first check of the test status, growing with every further check, e.g. 2s. Defaults to 1s, the minimum.")
+	flagSet.String(FlagMaxPollInterval, "", "The longest delay between two checks of the test status, e.g. 1m. Defaults to 10s.")
+}
+
+func addUploadTimeoutFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagUploadTimeout, "", "Abort if filtering and uploading the files does not complete within the given duration, e.g. 5m.")
+}
+
+func addScanTimeoutFlags(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagScanTimeout, "", "Abort if the scan does not complete within the given duration, e.g. 15m.")
+	flagSet.String(FlagResultsTimeout, "", "Abort if the findings are not retrieved within the given duration, e.g. 5m.")
+}
+
+func addAllowPartialFlag(flagSet *pflag.FlagSet) {
+	flagSet.Bool(FlagAllowPartial, false,
+		"Report the findings retrieved so far, marked as incomplete, instead of failing when not all findings can be retrieved.")
+}
+
+func addUploadManifestFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagUploadManifest, "",
+		"Write a JSON record of every file uploaded, with its size and SHA-256 hash, to the specified file, even if the upload fails.")
+}
+
+func addHistoryDirFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagHistoryDir, "",
+		"Append a record of the run, with its findings and statistics, to the scan history in the specified directory. "+
+			"Summarize the history with snyk secrets trends.")
+}
+
+func addJUnitFileOutputFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagJUnitFileOutput, "",
+		"Save test output as a JUnit XML report to the specified file, with a test case for every file with findings.")
+}
+
+func addJUnitIncludePassingFlag(flagSet *pflag.FlagSet) {
+	flagSet.Bool(FlagJUnitIncludePassing, false, "List the tested files without findings as passing test cases of the --junit-file-output report.")
+}
+
+func addGitLabFileOutputFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagGitLabFileOutput, "",
+		"Save test output as a GitLab secret detection report to the specified file, e.g. gl-secret-detection-report.json.")
+}
+
+func addHTMLFileOutputFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagHTMLFileOutput, "",
+		"Save test output as a self-contained HTML report to the specified file, with masked snippets of the findings.")
+}
+
+func addAnnotationsFlag(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagAnnotations, "",
+		"Annotate the location of every finding on the pull request. Possible values: github, azure, auto (detect the CI system).")
+}
+
+func addTemplateFlags(flagSet *pflag.FlagSet) {
+	flagSet.String(FlagFormatTemplate, "",
+		"Render the findings with the specified Go text/template file, or a built-in template: csv, markdown.")
+	flagSet.String(FlagTemplateFileOutput, "",
+		"Save the output of --format-template to the specified file instead of printing it.")
+}
@@ -0,0 +1,44 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const (
+	gitLabReportFailureMsg = "failed to write GitLab report"
+	// unknownVersion is the scanner version of GitLab reports when the CLI does not report its version.
+	unknownVersion = "unknown"
+)
+
+// writeGitLabReport writes findings as a GitLab secret detection report, if requested.
+func (c *Command) writeGitLabReport(ctx context.Context, findings []report.Finding) error {
+	if c.GitLabOutputPath == "" {
+		return nil
+	}
+
+	opts := report.GitLabOptions{
+		ScannerVersion: unknownVersion,
+		CommitSHA:      c.CommitRef,
+		PathPrefix:     c.RootFolderID,
+		StartTime:      c.startedAt,
+		EndTime:        time.Now(),
+	}
+	if ictx := cmdctx.Ictx(ctx); ictx != nil && ictx.GetRuntimeInfo() != nil && ictx.GetRuntimeInfo().GetVersion() != "" {
+		opts.ScannerVersion = ictx.GetRuntimeInfo().GetVersion()
+	}
+
+	var b bytes.Buffer
+	if err := report.RenderGitLab(&b, findings, opts); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, gitLabReportFailureMsg)
+	}
+	if err := writeFileAtomic(c.GitLabOutputPath, b.Bytes()); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, gitLabReportFailureMsg)
+	}
+	c.Logger.Info().Str("path", c.GitLabOutputPath).Msg("GitLab report written")
+	return nil
+}
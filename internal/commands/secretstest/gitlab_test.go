@@ -0,0 +1,78 @@
+package secretstest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/runtimeinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+func TestCommand_WriteGitLabReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	cmd.GitLabOutputPath = filepath.Join(t.TempDir(), "gl-secret-detection-report.json")
+	cmd.CommitRef = "4f2a9c1e"
+	cmd.RootFolderID = "services/api"
+
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockIctx.EXPECT().GetRuntimeInfo().Return(runtimeinfo.New(runtimeinfo.WithVersion("1.1300.0"))).AnyTimes()
+	ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	require.NoError(t, cmd.writeReports(ctx, testResult))
+
+	content, err := os.ReadFile(cmd.GitLabOutputPath)
+	require.NoError(t, err)
+	var gitLabReport struct {
+		Scan struct {
+			Scanner struct {
+				Version string `json:"version"`
+			} `json:"scanner"`
+		} `json:"scan"`
+		Vulnerabilities []struct {
+			Severity string `json:"severity"`
+			Location struct {
+				File   string `json:"file"`
+				Commit struct {
+					SHA string `json:"sha"`
+				} `json:"commit"`
+				StartLine int `json:"start_line"` //nolint:tagliatelle // the GitLab report schema uses snake_case
+			} `json:"location"`
+		} `json:"vulnerabilities"`
+	}
+	require.NoError(t, json.Unmarshal(content, &gitLabReport))
+	assert.Equal(t, "1.1300.0", gitLabReport.Scan.Scanner.Version)
+	require.Len(t, gitLabReport.Vulnerabilities, 2, "one vulnerability per location")
+	assert.Equal(t, "Critical", gitLabReport.Vulnerabilities[0].Severity)
+	assert.Equal(t, "services/api/gcp-credentials.json", gitLabReport.Vulnerabilities[0].Location.File)
+	assert.Equal(t, "4f2a9c1e", gitLabReport.Vulnerabilities[0].Location.Commit.SHA)
+	assert.Equal(t, 7, gitLabReport.Vulnerabilities[0].Location.StartLine)
+}
+
+func TestCommand_WriteGitLabReport_Unwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	// a directory cannot be replaced by the report
+	cmd.GitLabOutputPath = t.TempDir()
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+
+	err := cmd.writeReports(t.Context(), testResult)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, gitLabReportFailureMsg)
+}
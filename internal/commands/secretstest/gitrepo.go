@@ -11,16 +11,22 @@ import (
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/snyk/go-application-framework/pkg/utils/git"
+
+	"github.com/snyk/cli-extension-secrets/internal/ci"
 )
 
-const errGitRootDirNotAvailable = "git root directory not available"
+const (
+	errGitRootDirNotAvailable = "git root directory not available"
+	defaultRemoteName         = "origin"
+)
 
 // Git repository detection and context resolution.
 var (
 	Git                   = ".git"
-	repoURLFromDirFunc    = git.RepoUrlFromDir
+	repoURLFromDirFunc    = repoURLFromDir
 	branchNameFromDirFunc = git.BranchNameFromDir
 	commitRefFromDirFunc  = commitRefFromDir
+	detectCIEnvFunc       = detectCIEnv
 )
 
 type gitRepoContext struct {
@@ -29,12 +35,16 @@ type gitRepoContext struct {
 	repoURL                    string
 	branch                     string
 	commitRef                  string
+	ciProvider                 ci.Provider
+	prNumber                   string
+	baseBranch                 string
 }
 
 func resolveGitContext(
 	inputPath string,
 	gitRootDir string,
 	remoteRepoURLOverride string,
+	remoteName string,
 	logger *zerolog.Logger,
 ) gitRepoContext {
 	var repoCtx gitRepoContext
@@ -46,13 +56,11 @@ func resolveGitContext(
 		logger.Err(err).Str("inputPathRelativeToGitRoot", repoCtx.inputPathRelativeToGitRoot).Msg("could not determine common repo root")
 	}
 
-	repoCtx.repoURL, err = findRepoURLWithOverride(gitRootDir, remoteRepoURLOverride)
+	repoCtx.repoURL, err = findRepoURLWithOverride(gitRootDir, remoteRepoURLOverride, remoteName)
 	if err != nil {
 		logger.Err(err).Str(InputPathKey, inputPath).Msg("could not compute gitRoot or repoURL")
 	}
 
-	repoCtx.repoURL = git.NormalizeGitURL(repoCtx.repoURL)
-
 	repoCtx.branch, err = findBranchName(gitRootDir)
 	if err != nil {
 		logger.Warn().Err(err).Msg("could not determine git branch")
@@ -63,9 +71,49 @@ func resolveGitContext(
 		logger.Warn().Err(err).Msg("could not determine git commit ref")
 	}
 
+	// CI checkouts are often detached or shallow, fill the gaps from the CI provider
+	if ciEnv := detectCIEnvFunc(); ciEnv != nil {
+		applyCIEnvironment(&repoCtx, ciEnv)
+		logger.Info().
+			Str("ciProvider", string(repoCtx.ciProvider)).
+			Str("branch", repoCtx.branch).
+			Str("commitRef", repoCtx.commitRef).
+			Str("prNumber", repoCtx.prNumber).
+			Str("baseBranch", repoCtx.baseBranch).
+			Msg("detected CI environment")
+	}
+
+	// never send or log credentials, tokens or query strings embedded in the remote URL
+	repoCtx.repoURL, err = scrubRepoURL(repoCtx.repoURL)
+	if err != nil {
+		logger.Warn().Err(err).Msg("discarding repository URL that could not be scrubbed")
+	}
+
 	return repoCtx
 }
 
+func detectCIEnv() *ci.Environment {
+	return ci.Detect(os.Getenv)
+}
+
+// applyCIEnvironment fills the repo context values that could not be read from git
+// with the values exposed by the CI provider. Values found in git take precedence.
+func applyCIEnvironment(repoCtx *gitRepoContext, ciEnv *ci.Environment) {
+	repoCtx.ciProvider = ciEnv.Provider
+	repoCtx.prNumber = ciEnv.PRNumber
+	repoCtx.baseBranch = ciEnv.BaseBranch
+
+	if repoCtx.repoURL == "" {
+		repoCtx.repoURL = ciEnv.RepoURL
+	}
+	if repoCtx.branch == "" {
+		repoCtx.branch = ciEnv.Branch
+	}
+	if repoCtx.commitRef == "" {
+		repoCtx.commitRef = ciEnv.CommitSHA
+	}
+}
+
 func findGitRoot(inputPath string) (string, error) {
 	if inputPath == "" {
 		return "", fmt.Errorf("no path provided")
@@ -84,7 +132,7 @@ func findGitRoot(inputPath string) (string, error) {
 	return gd, nil
 }
 
-func findRepoURLWithOverride(gitRootFolder, remoteRepoURLFlag string) (repoURL string, err error) {
+func findRepoURLWithOverride(gitRootFolder, remoteRepoURLFlag, remoteName string) (repoURL string, err error) {
 	if remoteRepoURLFlag != "" {
 		return remoteRepoURLFlag, nil
 	}
@@ -93,7 +141,7 @@ func findRepoURLWithOverride(gitRootFolder, remoteRepoURLFlag string) (repoURL s
 		return "", fmt.Errorf("repository URL could not be determined, git root not found and remote repo url flag not set")
 	}
 
-	repoURL, err = repoURLFromDirFunc(gitRootFolder)
+	repoURL, err = repoURLFromDirFunc(gitRootFolder, remoteName)
 	if err != nil {
 		return "", fmt.Errorf("no remote repository URL configured for %s: %w", gitRootFolder, err)
 	}
@@ -105,6 +153,55 @@ func findRepoURLWithOverride(gitRootFolder, remoteRepoURLFlag string) (repoURL s
 	return repoURL, nil
 }
 
+// repoURLFromDir returns the fetch URL of the given remote. Without a remote name it uses
+// the remote tracked by the current branch and falls back to origin.
+func repoURLFromDir(inputDir, remoteName string) (string, error) {
+	repo, err := gogit.PlainOpenWithOptions(inputDir, &gogit.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository at %s: %w", inputDir, err)
+	}
+
+	if remoteName == "" {
+		remoteName = upstreamRemoteName(repo)
+	}
+
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %q: %w", remoteName, err)
+	}
+
+	// based on the git docs, the first URL is used to fetch, so this is the one we use
+	remoteConfig := remote.Config()
+	if remoteConfig == nil || len(remoteConfig.URLs) == 0 || remoteConfig.URLs[0] == "" {
+		return "", fmt.Errorf("no URL configured for remote %q", remoteName)
+	}
+
+	return remoteConfig.URLs[0], nil
+}
+
+// upstreamRemoteName returns the remote the current branch tracks, or origin if
+// HEAD is detached or the branch has no upstream.
+func upstreamRemoteName(repo *gogit.Repository) string {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return defaultRemoteName
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return defaultRemoteName
+	}
+
+	branch, ok := cfg.Branches[head.Name().Short()]
+	if !ok || branch.Remote == "" || branch.Remote == "." {
+		return defaultRemoteName
+	}
+
+	return branch.Remote
+}
+
 func findBranchName(gitRootDir string) (string, error) {
 	if gitRootDir == "" {
 		return "", fmt.Errorf("%s", errGitRootDirNotAvailable)
@@ -6,9 +6,16 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/ci"
 )
 
 func TestFindGitRoot(t *testing.T) {
@@ -363,11 +370,11 @@ func TestFindRepoURLWithOverride(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			repoURLFromDirFunc = func(_ string) (string, error) {
+			repoURLFromDirFunc = func(_, _ string) (string, error) {
 				return tc.mockRepoURL, tc.mockRepoURLErr
 			}
 
-			repoURL, err := findRepoURLWithOverride(tc.gitRoot, tc.remoteRepoURLFlag)
+			repoURL, err := findRepoURLWithOverride(tc.gitRoot, tc.remoteRepoURLFlag, "")
 			if tc.expectErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tc.expectedErrString)
@@ -378,3 +385,127 @@ func TestFindRepoURLWithOverride(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveGitContext_CIEnvironmentFallback(t *testing.T) {
+	oldRepoURLFromDir := repoURLFromDirFunc
+	oldBranchNameFromDir := branchNameFromDirFunc
+	oldCommitRefFromDir := commitRefFromDirFunc
+	oldDetectCIEnv := detectCIEnvFunc
+	defer func() {
+		repoURLFromDirFunc = oldRepoURLFromDir
+		branchNameFromDirFunc = oldBranchNameFromDir
+		commitRefFromDirFunc = oldCommitRefFromDir
+		detectCIEnvFunc = oldDetectCIEnv
+	}()
+
+	ciEnv := &ci.Environment{
+		Provider:   ci.GitHubActions,
+		Branch:     "feature",
+		CommitSHA:  "ci-sha",
+		RepoURL:    "https://github.com/snyk/ci-repo",
+		PRNumber:   "42",
+		BaseBranch: "main",
+	}
+	detectCIEnvFunc = func() *ci.Environment { return ciEnv }
+
+	inputPath := t.TempDir()
+	logger := zerolog.Nop()
+
+	t.Run("detached HEAD falls back to CI values", func(t *testing.T) {
+		repoURLFromDirFunc = func(_, _ string) (string, error) { return "", errors.New("remote not found") }
+		branchNameFromDirFunc = func(_ string) (string, error) { return "", nil }
+		commitRefFromDirFunc = func(_ string) (string, error) { return "", errors.New("reference not found") }
+
+		repoCtx := resolveGitContext(inputPath, inputPath, "", "", &logger)
+
+		assert.Equal(t, "https://github.com/snyk/ci-repo.git", repoCtx.repoURL)
+		assert.Equal(t, "feature", repoCtx.branch)
+		assert.Equal(t, "ci-sha", repoCtx.commitRef)
+		assert.Equal(t, ci.GitHubActions, repoCtx.ciProvider)
+		assert.Equal(t, "42", repoCtx.prNumber)
+		assert.Equal(t, "main", repoCtx.baseBranch)
+	})
+
+	t.Run("git values take precedence over CI values", func(t *testing.T) {
+		repoURLFromDirFunc = func(_, _ string) (string, error) { return "git@github.com:snyk/git-repo.git", nil }
+		branchNameFromDirFunc = func(_ string) (string, error) { return "local", nil }
+		commitRefFromDirFunc = func(_ string) (string, error) { return "git-sha", nil }
+
+		repoCtx := resolveGitContext(inputPath, inputPath, "", "", &logger)
+
+		assert.Equal(t, "https://github.com/snyk/git-repo.git", repoCtx.repoURL)
+		assert.Equal(t, "local", repoCtx.branch)
+		assert.Equal(t, "git-sha", repoCtx.commitRef)
+		assert.Equal(t, "42", repoCtx.prNumber)
+	})
+
+	t.Run("no CI environment leaves values empty", func(t *testing.T) {
+		detectCIEnvFunc = func() *ci.Environment { return nil }
+		repoURLFromDirFunc = func(_, _ string) (string, error) { return "", errors.New("remote not found") }
+		branchNameFromDirFunc = func(_ string) (string, error) { return "", nil }
+		commitRefFromDirFunc = func(_ string) (string, error) { return "", errors.New("reference not found") }
+
+		repoCtx := resolveGitContext(inputPath, inputPath, "", "", &logger)
+
+		assert.Empty(t, repoCtx.repoURL)
+		assert.Empty(t, repoCtx.branch)
+		assert.Empty(t, repoCtx.commitRef)
+		assert.Empty(t, repoCtx.ciProvider)
+	})
+}
+
+func TestRepoURLFromDir(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/snyk/origin-repo.git"}})
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "fork", URLs: []string{"git@github.com:me/fork-repo.git"}})
+	require.NoError(t, err)
+
+	t.Run("defaults to origin when the branch has no upstream", func(t *testing.T) {
+		repoURL, err := repoURLFromDir(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/snyk/origin-repo.git", repoURL)
+	})
+
+	t.Run("uses the named remote", func(t *testing.T) {
+		repoURL, err := repoURLFromDir(dir, "fork")
+		require.NoError(t, err)
+		assert.Equal(t, "git@github.com:me/fork-repo.git", repoURL)
+	})
+
+	t.Run("unknown remote returns an error", func(t *testing.T) {
+		_, err := repoURLFromDir(dir, "missing")
+		assert.ErrorContains(t, err, `failed to get remote "missing"`)
+	})
+
+	t.Run("falls back to the upstream of the current branch", func(t *testing.T) {
+		commitEmpty(t, repo)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		branchName := head.Name().Short()
+
+		cfg, err := repo.Config()
+		require.NoError(t, err)
+		cfg.Branches[branchName] = &config.Branch{Name: branchName, Remote: "fork", Merge: head.Name()}
+		require.NoError(t, repo.SetConfig(cfg))
+
+		repoURL, err := repoURLFromDir(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "git@github.com:me/fork-repo.git", repoURL)
+	})
+}
+
+func commitEmpty(t *testing.T, repo *gogit.Repository) {
+	t.Helper()
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Commit("initial commit", &gogit.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+package secretstest
+
+import (
+	"context"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/history"
+	"github.com/snyk/cli-extension-secrets/internal/instrumentation"
+)
+
+// historyFailureMsg is the message of errors appending to the scan history.
+const historyFailureMsg = "failed to write the scan history"
+
+// withRunInstrumentation adds the instrumentation of a test run to ctx. With --history-dir the recorded values are
+// kept, for the statistics of the history record.
+func withRunInstrumentation(ctx context.Context, ictx workflow.InvocationContext) context.Context {
+	var i instrumentation.Instrumentation = instrumentation.NewGAFInstrumentation(ictx.GetAnalytics())
+	if ictx.GetConfiguration().GetString(FlagHistoryDir) != "" {
+		i = instrumentation.NewRecorder(i)
+	}
+	return cmdctx.WithInstrumentation(ctx, i)
+}
+
+// appendHistory appends the record of the run, with all findings of testResult, to the scan history in
+// --history-dir, if set.
+func (c *Command) appendHistory(ctx context.Context, testResult testapi.TestResult) error {
+	if c.HistoryDir == "" {
+		return nil
+	}
+
+	findings, err := c.reportFindings(ctx, testResult)
+	if err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, historyFailureMsg)
+	}
+
+	record := history.NewRecord(c.startedAt, findings)
+	record.RepoURL = c.RepoURL
+	record.Branch = c.Branch
+	record.Commit = c.CommitRef
+	record.RootFolder = c.RootFolderID
+	if testID := testResult.GetTestID(); testID != nil {
+		record.TestID = testID.String()
+	}
+	_, record.Incomplete = testResult.(*partialTestResult)
+	record.Stats = c.historyStats(ctx)
+
+	if err := history.Append(c.HistoryDir, record); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, historyFailureMsg)
+	}
+	c.Logger.Info().Str("path", c.HistoryDir).Msg("Scan history appended")
+	return nil
+}
+
+// historyStats returns the filtering and upload statistics of the run. The durations and filtered files are only
+// known if the instrumentation of the run kept them, see withRunInstrumentation.
+func (c *Command) historyStats(ctx context.Context) history.Stats {
+	stats := history.Stats{
+		FilesWalked:   int(c.progress.filesWalked.Load()),
+		FilesKept:     int(c.progress.filesFiltered.Load()),
+		FilesUploaded: int(c.progress.filesUploaded.Load()),
+	}
+	if c.Clients != nil {
+		stats.Retries = c.Clients.Retries.Load()
+	}
+	if recorder, ok := cmdctx.Instrumentation(ctx).(*instrumentation.Recorder); ok {
+		stats.SizeFiltered = recorder.Value(instrumentation.SecretsSizeFiltered)
+		stats.KeywordFiltered = recorder.Value(instrumentation.SecretsKeywordFiltered)
+		stats.FilterMs = recorder.Value(instrumentation.SecretsFileFilterTimeMs)
+		stats.UploadMs = recorder.Value(instrumentation.SecretsFileUploadTimeMs)
+		stats.AnalysisMs = recorder.Value(instrumentation.SecretsAnalysisTimeMs)
+	}
+	return stats
+}
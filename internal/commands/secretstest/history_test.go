@@ -0,0 +1,103 @@
+package secretstest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/analytics"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/history"
+	"github.com/snyk/cli-extension-secrets/internal/instrumentation"
+)
+
+func TestCommand_AppendHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	cmd.HistoryDir = filepath.Join(t.TempDir(), "history")
+	cmd.RepoURL = "https://github.com/snyk/example"
+	cmd.Branch = "main"
+	cmd.CommitRef = "4f2a9c1e"
+	cmd.startedAt = time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC)
+	cmd.progress.FileChecked(true)
+	cmd.progress.FileChecked(false)
+	cmd.progress.filesUploaded.Store(1)
+
+	recorder := instrumentation.NewRecorder(instrumentation.NewGAFInstrumentation(analytics.New()))
+	recorder.RecordSizeFiltered(1)
+	recorder.RecordFileUploadTimeMs(time.Now().Add(-2 * time.Second))
+	ctx := cmdctx.WithInstrumentation(t.Context(), recorder)
+
+	testID := uuid.New()
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	testResult.EXPECT().GetTestID().Return(&testID)
+	require.NoError(t, cmd.appendHistory(ctx, testResult))
+
+	records, skipped, err := history.Load(cmd.HistoryDir)
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, testID.String(), record.TestID)
+	assert.Equal(t, "https://github.com/snyk/example", record.RepoURL)
+	assert.Equal(t, "main", record.Branch)
+	assert.Equal(t, "4f2a9c1e", record.Commit)
+	assert.True(t, cmd.startedAt.Equal(record.Timestamp))
+	assert.False(t, record.Incomplete)
+	assert.Equal(t, 1, record.Total)
+	assert.Equal(t, map[string]int{"critical": 1}, record.BySeverity)
+	require.Len(t, record.Findings, 1)
+	assert.NotEmpty(t, record.Findings[0].Key)
+	assert.GreaterOrEqual(t, record.Stats.UploadMs, 2000)
+	record.Stats.UploadMs = 0
+	assert.Equal(t, history.Stats{FilesWalked: 2, FilesKept: 1, FilesUploaded: 1, SizeFiltered: 1}, record.Stats)
+}
+
+func TestCommand_AppendHistory_Unwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	// the history file cannot be created below a file
+	cmd.HistoryDir = filepath.Join(t.TempDir(), "history")
+	require.NoError(t, history.Append(cmd.HistoryDir, history.Record{}))
+	cmd.HistoryDir = filepath.Join(cmd.HistoryDir, history.FileName)
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return([]testapi.FindingData{}, true, nil)
+	testResult.EXPECT().GetTestID().Return(nil)
+
+	err := cmd.appendHistory(t.Context(), testResult)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, historyFailureMsg)
+}
+
+func TestWithRunInstrumentation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, historyDir := range []string{"", ".snyk-history"} {
+		config := configuration.New()
+		config.Set(FlagHistoryDir, historyDir)
+		mockIctx := mocks.NewMockInvocationContext(ctrl)
+		mockIctx.EXPECT().GetConfiguration().Return(config)
+		mockIctx.EXPECT().GetAnalytics().Return(analytics.New())
+
+		ctx := withRunInstrumentation(t.Context(), mockIctx)
+
+		_, isRecorder := cmdctx.Instrumentation(ctx).(*instrumentation.Recorder)
+		assert.Equal(t, historyDir != "", isRecorder, "history dir %q", historyDir)
+	}
+}
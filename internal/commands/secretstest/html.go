@@ -0,0 +1,38 @@
+package secretstest
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const htmlReportFailureMsg = "failed to write HTML report"
+
+// writeHTMLReport writes findings as a self-contained HTML report, if requested. Snippets are only shown when the
+// tested files are available locally.
+func (c *Command) writeHTMLReport(findings []report.Finding, testResult testapi.TestResult) error {
+	if c.HTMLOutputPath == "" {
+		return nil
+	}
+
+	opts := report.HTMLOptions{
+		SourceDir:    c.sourceDir,
+		ContextLines: report.DefaultContextLines,
+		SCM:          c.scmContext(),
+		ReportURL:    reportURL(testResult),
+		GeneratedAt:  time.Now(),
+	}
+
+	var b bytes.Buffer
+	if err := report.RenderHTML(&b, findings, opts); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, htmlReportFailureMsg)
+	}
+	if err := writeFileAtomic(c.HTMLOutputPath, b.Bytes()); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, htmlReportFailureMsg)
+	}
+	c.Logger.Info().Str("path", c.HTMLOutputPath).Msg("HTML report written")
+	return nil
+}
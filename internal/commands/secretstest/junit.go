@@ -0,0 +1,68 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const junitReportFailureMsg = "failed to write JUnit report"
+
+// scannedFilesRecorder records the files handed over for upload, which the JUnit report lists as passing
+// test cases unless they have findings. It is safe for concurrent use.
+type scannedFilesRecorder struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+// record forwards paths, relative to dir, recording the slash-separated path of every file handed over.
+func (r *scannedFilesRecorder) record(ctx context.Context, paths <-chan string, dir string) <-chan string {
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		for path := range paths {
+			select {
+			case out <- path:
+				if rel, err := filepath.Rel(dir, path); err == nil {
+					r.mu.Lock()
+					r.paths = append(r.paths, filepath.ToSlash(rel))
+					r.mu.Unlock()
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func (r *scannedFilesRecorder) list() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.paths...)
+}
+
+// writeJUnitReport writes findings as a JUnit report, if requested.
+func (c *Command) writeJUnitReport(findings []report.Finding) error {
+	if c.JUnitOutputPath == "" {
+		return nil
+	}
+
+	var b bytes.Buffer
+	if err := report.RenderJUnit(&b, findings, report.JUnitOptions{PassingFiles: c.scannedFiles.list()}); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, junitReportFailureMsg)
+	}
+	if err := writeFileAtomic(c.JUnitOutputPath, b.Bytes()); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, junitReportFailureMsg)
+	}
+	c.Logger.Info().Str("path", c.JUnitOutputPath).Msg("JUnit report written")
+	return nil
+}
@@ -0,0 +1,79 @@
+package secretstest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+)
+
+func TestCommand_WriteJUnitReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	cmd.JUnitOutputPath = filepath.Join(t.TempDir(), "reports", "junit.xml")
+	cmd.scannedFiles = &scannedFilesRecorder{}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"gcp-credentials.json": `{"private_key": "secret"}`, "src/app.go": "package main"})
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), dir).DoAndReturn(
+		func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			uploaded := 0
+			for range paths {
+				uploaded++
+			}
+			return fileupload.UploadResult{RevisionID: uuid.New(), UploadedFilesCount: uploaded}, nil
+		},
+	)
+	_, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	require.NoError(t, err)
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	require.NoError(t, cmd.writeReports(t.Context(), testResult))
+
+	content, err := os.ReadFile(cmd.JUnitOutputPath)
+	require.NoError(t, err)
+	xml := string(content)
+	assert.Contains(t, xml, `<testsuites name="Snyk Secrets" tests="2" failures="1">`)
+	assert.Contains(t, xml, `<testcase classname="secrets" name="gcp-credentials.json" file="gcp-credentials.json">`)
+	assert.Contains(t, xml, `<failure message="[critical] Private Key at gcp-credentials.json:7:20" type="private-key">`)
+	assert.Contains(t, xml, `<testcase classname="secrets.src" name="src/app.go" file="src/app.go"></testcase>`)
+}
+
+func TestCommand_WriteReportFiles_NotRequested(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	// the findings are not read without --junit-file-output or --gitlab-file-output
+	assert.NoError(t, cmd.writeReports(t.Context(), gafclientmocks.NewMockTestResult(ctrl)))
+}
+
+func TestCommand_WriteJUnitReport_Unwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	// a directory cannot be replaced by the report
+	cmd.JUnitOutputPath = t.TempDir()
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+
+	err := cmd.writeReports(t.Context(), testResult)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, junitReportFailureMsg)
+}
@@ -0,0 +1,89 @@
+package secretstest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/snyk/cli-extension-secrets/internal/redact"
+	ff "github.com/snyk/cli-extension-secrets/pkg/filefilter"
+)
+
+// minimalUploadFilterName names the staging of the minimal upload in the filter decisions of upload manifests, for
+// the files it drops.
+const minimalUploadFilterName = "minimal-upload"
+
+// stageMinimalUpload writes a copy of every file at paths, relative to dir, into a staging directory, with all
+// lines blanked out except the candidate secret lines and their context. Files without candidate lines are not
+// staged. It returns the paths of the copies, the staging directory they are relative to, and a function removing it.
+// The copies have the same relative paths and line numbers as the files, so that findings map back to the files.
+func (c *Command) stageMinimalUpload(ctx context.Context, paths <-chan string, dir string) (<-chan string, string, func(), error) {
+	stagingDir, err := os.MkdirTemp("", "snyk-secrets-minimal-upload-")
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to create minimal upload directory: %w", err)
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(stagingDir); err != nil {
+			c.Logger.Warn().Err(err).Msg("Failed to remove minimal upload directory")
+		}
+	}
+
+	matcher := ff.NewCandidateMatcher()
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		for path := range paths {
+			staged, ok := c.stageRedactedFile(matcher, path, dir, stagingDir)
+			if !ok {
+				if c.uploadManifest != nil {
+					c.uploadManifest.fileDropped(path, minimalUploadFilterName)
+				}
+				continue
+			}
+			select {
+			case out <- staged:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, stagingDir, cleanup, nil
+}
+
+// stageRedactedFile writes the redacted copy of the file at path into stagingDir and returns its path.
+// A file that cannot be redacted is not uploaded, as its content must not leave the machine in full.
+func (c *Command) stageRedactedFile(matcher *ff.CandidateMatcher, path, dir, stagingDir string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		c.Logger.Warn().Err(err).Str("path", path).Msg("Skipping file outside of the tested directory")
+		return "", false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		c.Logger.Warn().Err(err).Str("path", path).Msg("Skipping file that could not be read for minimal upload")
+		return "", false
+	}
+
+	result := redact.Lines(content, redact.DefaultContextLines, matcher.Match)
+	if result.KeptLines == 0 {
+		c.Logger.Debug().Str("path", rel).Msg("No candidate lines, file not uploaded")
+		return "", false
+	}
+
+	staged := filepath.Join(stagingDir, rel)
+	if err := os.MkdirAll(filepath.Dir(staged), 0o700); err != nil {
+		c.Logger.Warn().Err(err).Str("path", rel).Msg("Skipping file that could not be staged for minimal upload")
+		return "", false
+	}
+	if err := os.WriteFile(staged, result.Content, 0o600); err != nil {
+		c.Logger.Warn().Err(err).Str("path", rel).Msg("Skipping file that could not be staged for minimal upload")
+		return "", false
+	}
+
+	c.Logger.Debug().Str("path", rel).Int("keptLines", result.KeptLines).Int("totalLines", result.TotalLines).
+		Msg("Staged candidate lines for minimal upload")
+	return staged, true
+}
@@ -0,0 +1,98 @@
+package secretstest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+)
+
+func TestCommand_StageMinimalUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	_, _, cmd := setupTestCommand(t, ctrl)
+
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = "x := value + index"
+	}
+	lines[10] = `password := "hunter2"`
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"src/config.go": strings.Join(lines, "\n") + "\n",
+		"src/main.go":   "package main\n\nfunc main() {}\n",
+	})
+
+	paths := make(chan string, 2)
+	paths <- filepath.Join(dir, "src", "config.go")
+	paths <- filepath.Join(dir, "src", "main.go")
+	close(paths)
+
+	staged, stagingDir, cleanup, err := cmd.stageMinimalUpload(t.Context(), paths, dir)
+	require.NoError(t, err)
+
+	var stagedPaths []string
+	for path := range staged {
+		stagedPaths = append(stagedPaths, path)
+	}
+	require.Equal(t, []string{filepath.Join(stagingDir, "src", "config.go")}, stagedPaths, "files without candidates are not uploaded")
+
+	content, err := os.ReadFile(stagedPaths[0])
+	require.NoError(t, err)
+	stagedLines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, stagedLines, len(lines), "line numbers must not change")
+	for i, line := range stagedLines {
+		if i >= 10-3 && i <= 10+3 {
+			assert.Equal(t, lines[i], line, "line %d", i+1)
+		} else {
+			assert.Empty(t, line, "line %d", i+1)
+		}
+	}
+
+	cleanup()
+	assert.NoDirExists(t, stagingDir)
+}
+
+func TestCommand_FilterAndUploadFiles_MinimalUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	cmd.MinimalUpload = true
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"config.env": "DEBUG=true\nDB_PASSWORD=hunter2\n",
+		"main.go":    "package main\n\nfunc main() {}\n",
+	})
+
+	revisionID := uuid.New()
+	var uploadDir string
+	var uploaded []string
+	mockUpload := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUpload.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, paths <-chan string, baseDir string) (fileupload.UploadResult, error) {
+			uploadDir = baseDir
+			for path := range paths {
+				rel, err := filepath.Rel(baseDir, path)
+				require.NoError(t, err)
+				uploaded = append(uploaded, rel)
+			}
+			return fileupload.UploadResult{RevisionID: revisionID, UploadedFilesCount: len(uploaded)}, nil
+		})
+
+	revision, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, revisionID.String(), revision)
+	assert.Equal(t, []string{"config.env"}, uploaded)
+	assert.NotEqual(t, dir, uploadDir, "the redacted copies are uploaded")
+	assert.NoDirExists(t, uploadDir, "the redacted copies are removed after the upload")
+}
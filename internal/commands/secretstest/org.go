@@ -0,0 +1,36 @@
+package secretstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/orgs"
+)
+
+// resolveOrgID returns the ID of org, which validateOrg accepted, resolving it through the API if it is a slug.
+func resolveOrgID(ctx context.Context, ictx workflow.InvocationContext, org string, errorFactory *ErrorFactory) (string, error) {
+	if _, err := uuid.Parse(org); err == nil {
+		return org, nil
+	}
+
+	orgID, err := orgs.NewClient(ictx, buildRetryConfig(ictx.GetConfiguration())).ResolveSlug(ctx, org)
+	switch {
+	case errors.Is(err, orgs.ErrOrgNotFound):
+		return "", errorFactory.NewValidationFailureError(fmt.Sprintf(
+			"Invalid --%s: no organization with the slug %q is accessible with the current credentials. "+
+				"Check the slug, or pass the organization ID instead.", configuration.ORGANIZATION, org))
+	case errors.Is(err, orgs.ErrOrgAmbiguous):
+		return "", errorFactory.NewValidationFailureError(fmt.Sprintf(
+			"Invalid --%s: more than one organization has the slug %q. Pass the organization ID instead.", configuration.ORGANIZATION, org))
+	case err != nil:
+		return "", errorFactory.NewGeneralSecretsFailureError(err, fmt.Sprintf("could not resolve the organization slug %q", org))
+	}
+
+	ictx.GetEnhancedLogger().Debug().Str("slug", org).Str("orgID", orgID.String()).Msg("Resolved organization slug")
+	return orgID.String(), nil
+}
@@ -0,0 +1,153 @@
+//nolint:testpackage // whitebox testing the organization resolution
+package secretstest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOrgsAPI stubs the list organizations endpoint with a single organization, counting the requests.
+func setupOrgsAPI(t *testing.T, config configuration.Configuration, orgID uuid.UUID, slug string) *atomic.Int32 {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "/rest/orgs", r.URL.Path)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		_, _ = fmt.Fprintf(w, `{"data":[{"id":%q,"type":"org","attributes":{"slug":%q,"name":"Org"}}]}`, orgID, slug)
+	}))
+	t.Cleanup(server.Close)
+
+	config.Set(configuration.API_URL, server.URL)
+	config.Set(configuration.CACHE_PATH, t.TempDir())
+	return &requests
+}
+
+func setupOrgsIctx(ctrl *gomock.Controller, config configuration.Configuration) workflow.InvocationContext {
+	logger := zerolog.Nop()
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockNetworkAccess := mocks.NewMockNetworkAccess(ctrl)
+	mockIctx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	mockIctx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	mockIctx.EXPECT().GetNetworkAccess().Return(mockNetworkAccess).AnyTimes()
+	mockNetworkAccess.EXPECT().GetHttpClient().Return(&http.Client{}).AnyTimes()
+	return mockIctx
+}
+
+func TestResolveOrgID(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("org ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		requests := setupOrgsAPI(t, config, orgID, "my-org")
+
+		resolved, err := resolveOrgID(t.Context(), setupOrgsIctx(ctrl, config), orgID.String(), NewErrorFactory(nil))
+		require.NoError(t, err)
+		assert.Equal(t, orgID.String(), resolved)
+		assert.Zero(t, requests.Load(), "org IDs are not resolved")
+	})
+
+	t.Run("org slug", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		requests := setupOrgsAPI(t, config, orgID, "my-org")
+		ictx := setupOrgsIctx(ctrl, config)
+
+		resolved, err := resolveOrgID(t.Context(), ictx, "my-org", NewErrorFactory(nil))
+		require.NoError(t, err)
+		assert.Equal(t, orgID.String(), resolved)
+
+		resolved, err = resolveOrgID(t.Context(), ictx, "my-org", NewErrorFactory(nil))
+		require.NoError(t, err)
+		assert.Equal(t, orgID.String(), resolved)
+		assert.Equal(t, int32(1), requests.Load(), "resolved slugs are cached")
+	})
+
+	t.Run("unknown org slug", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		setupOrgsAPI(t, config, orgID, "my-org")
+		logger := zerolog.Nop()
+
+		_, err := resolveOrgID(t.Context(), setupOrgsIctx(ctrl, config), "other-org", NewErrorFactory(&logger))
+		catalogErr := requireCatalogError(t, err)
+		assert.Equal(t, "SNYK-CLI-0010", catalogErr.ErrorCode)
+		assert.Contains(t, catalogErr.Detail, `no organization with the slug "other-org"`)
+	})
+
+	t.Run("ambiguous org slug", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			_, _ = fmt.Fprintf(w, `{"data":[{"id":%q,"attributes":{"slug":"my-org"}},{"id":%q,"attributes":{"slug":"my-org"}}]}`, uuid.New(), uuid.New())
+		}))
+		t.Cleanup(server.Close)
+		config.Set(configuration.API_URL, server.URL)
+		logger := zerolog.Nop()
+
+		_, err := resolveOrgID(t.Context(), setupOrgsIctx(ctrl, config), "my-org", NewErrorFactory(&logger))
+		catalogErr := requireCatalogError(t, err)
+		assert.Equal(t, "SNYK-CLI-0010", catalogErr.ErrorCode)
+		assert.Contains(t, catalogErr.Detail, `more than one organization has the slug "my-org"`)
+	})
+
+	t.Run("unresolved org slug", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		config := configuration.New()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+		config.Set(configuration.API_URL, server.URL)
+		config.Set(FlagMaxRetries, 0)
+		logger := zerolog.Nop()
+
+		_, err := resolveOrgID(t.Context(), setupOrgsIctx(ctrl, config), "my-org", NewErrorFactory(&logger))
+		catalogErr := requireCatalogError(t, err)
+		assert.NotEqual(t, "SNYK-CLI-0010", catalogErr.ErrorCode, "the slug may be valid")
+		assert.Contains(t, catalogErr.Detail, `could not resolve the organization slug "my-org"`)
+	})
+}
+
+func TestSecretsWorkflow_InvalidOrg(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockConfig := configuration.New()
+	mockConfig.Set(FeatureFlagIsSecretsEnabled, true)
+	mockConfig.Set(configuration.ORGANIZATION, "my org/with spaces")
+	mockIctx := setupMockIctx(ctrl, mockConfig)
+
+	_, err := SecretsWorkflow(mockIctx, []workflow.Data{})
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "is neither an organization ID nor an organization slug")
+}
+
+func TestSecretsWorkflow_UnknownOrgSlug(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockConfig := configuration.New()
+	mockConfig.Set(FeatureFlagIsSecretsEnabled, true)
+	mockConfig.Set(configuration.ORGANIZATION, "other-org")
+	mockConfig.Set(configuration.INPUT_DIRECTORY, []string{t.TempDir()})
+	setupOrgsAPI(t, mockConfig, uuid.New(), "my-org")
+	mockIctx := setupMockIctxWithNetworkAccess(ctrl, mockConfig)
+
+	_, err := SecretsWorkflow(mockIctx, []workflow.Data{})
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "or pass the organization ID instead.")
+}
@@ -0,0 +1,178 @@
+package secretstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/codeowners"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+// Ownership metadata keys and --group-by options.
+const (
+	OwnersMetadata       = "owners"
+	OwnerSummaryMetadata = "owner-summary"
+	GroupByOwner         = "owner"
+	UnownedGroup         = "(unowned)"
+)
+
+// ownerSummary counts the findings owned by a single owner.
+type ownerSummary struct {
+	Owner      string         `json:"owner"`
+	Count      int            `json:"count"`
+	Severities map[string]int `json:"severities"`
+}
+
+// addOwnerInfo attaches the CODEOWNERS owners of each finding's locations to the finding, and keeps them for the
+// reports.
+// With --group-by=owner it also attaches a per-owner summary to the test result.
+func (c *Command) addOwnerInfo(ctx context.Context, testResult testapi.TestResult) []ownerSummary {
+	ruleset := c.loadCodeowners()
+	groupByOwner := c.GroupBy == GroupByOwner
+	if ruleset == nil && !groupByOwner {
+		return nil
+	}
+
+	findings, _, err := testResult.Findings(ctx)
+	if err != nil {
+		c.Logger.Warn().Err(err).Msg("could not read findings for ownership")
+		return nil
+	}
+
+	summaries := map[string]*ownerSummary{}
+	for i := range findings {
+		var owners []string
+		if ruleset != nil {
+			owners = c.findingOwners(ruleset, &findings[i])
+		}
+		if len(owners) > 0 {
+			if c.owners == nil {
+				c.owners = map[string][]string{}
+			}
+			c.owners[findingID(&findings[i])] = owners
+			setFindingMetadata(testResult, findingID(&findings[i]), OwnersMetadata, owners)
+		}
+
+		if !groupByOwner {
+			continue
+		}
+		if len(owners) == 0 {
+			owners = []string{UnownedGroup}
+		}
+		severity := ""
+		if findings[i].Attributes != nil {
+			severity = string(findings[i].Attributes.Rating.Severity)
+		}
+		for _, owner := range owners {
+			summary, ok := summaries[owner]
+			if !ok {
+				summary = &ownerSummary{Owner: owner, Severities: map[string]int{}}
+				summaries[owner] = summary
+			}
+			summary.Count++
+			summary.Severities[severity]++
+		}
+	}
+
+	if !groupByOwner {
+		return nil
+	}
+
+	result := make([]ownerSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Owner < result[j].Owner
+	})
+	testResult.SetMetadata(OwnerSummaryMetadata, result)
+
+	return result
+}
+
+func (c *Command) loadCodeowners() *codeowners.Ruleset {
+	if c.GitRootDir == "" {
+		return nil
+	}
+
+	ruleset, filePath, err := codeowners.Load(c.GitRootDir)
+	if errors.Is(err, codeowners.ErrNotFound) {
+		c.Logger.Debug().Str("gitRootDir", c.GitRootDir).Msg("no CODEOWNERS file found")
+		return nil
+	}
+	if err != nil {
+		c.Logger.Warn().Err(err).Msg("could not load CODEOWNERS")
+		return nil
+	}
+
+	c.Logger.Debug().Str("codeowners", filePath).Msg("using CODEOWNERS for finding ownership")
+	return ruleset
+}
+
+// findingOwners returns the owners of all locations of a finding, without duplicates.
+func (c *Command) findingOwners(ruleset *codeowners.Ruleset, finding *testapi.FindingData) []string {
+	var owners []string
+	for _, loc := range sourceLocations(finding) {
+		// finding paths are relative to the upload root, CODEOWNERS paths to the git root
+		for _, owner := range ruleset.Owners(path.Join(c.RootFolderID, loc.FilePath)) {
+			if !slices.Contains(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// ownerSummaryOutput returns the per-owner summary as text, for the CLI to print after the findings it renders
+// for humans. Machine readable output carries the summary in the test result metadata instead, and the text report
+// includes it.
+func (c *Command) ownerSummaryOutput(ctx context.Context, summaries []ownerSummary) []workflow.Data {
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil || c.TextReport {
+		return nil
+	}
+
+	config := ictx.GetConfiguration()
+	if config.GetBool(FlagJSON) || config.GetBool(FlagSARIF) {
+		return nil
+	}
+
+	id := workflow.NewTypeIdentifier(ictx.GetWorkflowIdentifier(), "owner-summary")
+	return []workflow.Data{workflow.NewData(id, contentTypeText, []byte(renderOwnerSummary(summaries)))}
+}
+
+// renderOwnerSummary formats the per-owner summary for human readable output.
+func renderOwnerSummary(summaries []ownerSummary) string {
+	var b strings.Builder
+	b.WriteString("\nFindings by owner:\n")
+	if len(summaries) == 0 {
+		b.WriteString("  No findings.\n")
+		return b.String()
+	}
+
+	for _, summary := range summaries {
+		var severities []string
+		for _, severity := range []string{optionCritical, optionHigh, optionMedium, optionLow} {
+			if count := summary.Severities[severity]; count > 0 {
+				severities = append(severities, fmt.Sprintf("%d %s", count, severity))
+			}
+		}
+		fmt.Fprintf(&b, "  %s: %d", summary.Owner, summary.Count)
+		if len(severities) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(severities, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+//nolint:testpackage // whitebox testing the ownership attribution
+package secretstest
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+const fixtureFindingID = "bdaa4c47-9609-435c-80ef-317586c3a97a"
+
+func writeRepoCodeowners(t *testing.T, content string) string {
+	t.Helper()
+
+	gitRoot := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(gitRoot, ".github"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitRoot, ".github", "CODEOWNERS"), []byte(content), 0o600))
+	return gitRoot
+}
+
+func TestCommand_AddOwnerInfo(t *testing.T) {
+	codeownersContent := "* @org/everyone\n/services/api/*.json @org/api @org/security\n"
+
+	t.Run("attaches owners relative to the git root", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = writeRepoCodeowners(t, codeownersContent)
+		cmd.RootFolderID = "services/api"
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		summaries := cmd.addOwnerInfo(t.Context(), mockTestResult)
+
+		assert.Nil(t, summaries)
+		findingsMeta, ok := metadata[FindingsMetadata].(map[string]map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, []string{"@org/api", "@org/security"}, findingsMeta[fixtureFindingID][OwnersMetadata])
+		assert.NotContains(t, metadata, OwnerSummaryMetadata)
+	})
+
+	t.Run("groups findings by owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = writeRepoCodeowners(t, codeownersContent)
+		cmd.RootFolderID = "."
+		cmd.GroupBy = GroupByOwner
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		summaries := cmd.addOwnerInfo(t.Context(), mockTestResult)
+
+		expected := []ownerSummary{{Owner: "@org/everyone", Count: 1, Severities: map[string]int{optionCritical: 1}}}
+		assert.Equal(t, expected, summaries)
+		assert.Equal(t, expected, metadata[OwnerSummaryMetadata])
+	})
+
+	t.Run("groups findings without CODEOWNERS as unowned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = t.TempDir()
+		cmd.GroupBy = GroupByOwner
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		summaries := cmd.addOwnerInfo(t.Context(), mockTestResult)
+
+		require.Len(t, summaries, 1)
+		assert.Equal(t, UnownedGroup, summaries[0].Owner)
+		assert.NotContains(t, metadata, FindingsMetadata)
+	})
+
+	t.Run("skipped without CODEOWNERS and grouping", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+		cmd.GitRootDir = t.TempDir()
+
+		mockTestResult, metadata := mockTestResultWithMetadata(t, ctrl)
+		assert.Nil(t, cmd.addOwnerInfo(t.Context(), mockTestResult))
+		assert.Empty(t, metadata)
+	})
+}
+
+func TestRenderOwnerSummary(t *testing.T) {
+	summaries := []ownerSummary{
+		{Owner: "@org/api", Count: 3, Severities: map[string]int{optionHigh: 1, optionCritical: 2}},
+		{Owner: UnownedGroup, Count: 1, Severities: map[string]int{"": 1}},
+	}
+
+	expected := "\nFindings by owner:\n" +
+		"  @org/api: 3 (2 critical, 1 high)\n" +
+		"  (unowned): 1\n"
+	assert.Equal(t, expected, renderOwnerSummary(summaries))
+	assert.Contains(t, renderOwnerSummary(nil), "No findings.")
+}
+
+func TestCommand_OwnerSummaryOutput(t *testing.T) {
+	t.Run("human output", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+
+		mockIctx := mocks.NewMockInvocationContext(ctrl)
+		mockIctx.EXPECT().GetConfiguration().Return(configuration.New())
+		mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{})
+
+		output := cmd.ownerSummaryOutput(cmdctx.WithIctx(t.Context(), mockIctx), nil)
+		require.Len(t, output, 1)
+		assert.Equal(t, contentTypeText, output[0].GetContentType())
+		assert.Equal(t, []byte(renderOwnerSummary(nil)), output[0].GetPayload())
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+
+		config := configuration.New()
+		config.Set(FlagJSON, true)
+		mockIctx := mocks.NewMockInvocationContext(ctrl)
+		mockIctx.EXPECT().GetConfiguration().Return(config)
+
+		assert.Empty(t, cmd.ownerSummaryOutput(cmdctx.WithIctx(t.Context(), mockIctx), nil))
+	})
+}
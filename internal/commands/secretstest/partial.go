@@ -0,0 +1,74 @@
+package secretstest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+// MetadataIncomplete is the test result metadata key marking results whose findings were only retrieved in part.
+const MetadataIncomplete = "incomplete"
+
+// ExitCodePartialResults is the exit code of a run reporting partial results with --allow-partial. It is distinct
+// from the exit codes of the Snyk CLI for no issues (0), issues (1), failures (2) and unsupported projects (3).
+const ExitCodePartialResults = 4
+
+// MetaExitCode is the error meta key holding the exit code of a run, which pipelines read from the partial results
+// warning. The CLI process itself exits with the code derived from the test summary.
+const MetaExitCode = "exitCode"
+
+// partialResultsDetail describes the partial results of a run with the given number of findings retrieved.
+func partialResultsDetail(retrieved int) string {
+	return fmt.Sprintf("Not all findings could be retrieved, the results are incomplete and only show %d findings.", retrieved)
+}
+
+// partialTestResult is a test result reporting the findings retrieved before retrieval failed or stopped.
+type partialTestResult struct {
+	testapi.TestResult
+	findings []testapi.FindingData
+}
+
+// Findings returns the findings retrieved so far, flagged as incomplete.
+func (r *partialTestResult) Findings(context.Context) ([]testapi.FindingData, bool, error) {
+	return r.findings, false, nil
+}
+
+// partialResult marks testResult as incomplete and limits its findings to those retrieved.
+//
+//nolint:ireturn // supposed to return interface.
+func (c *Command) partialResult(testResult testapi.TestResult, findings []testapi.FindingData) testapi.TestResult {
+	c.Logger.Warn().Int(LogFieldCount, len(findings)).Msg("Reporting partial findings as --allow-partial is set")
+	testResult.SetMetadata(MetadataIncomplete, true)
+	return &partialTestResult{TestResult: testResult, findings: findings}
+}
+
+// flagPartialResults attaches a warning with the partial results exit code to the output of an incomplete
+// test result, and shows it to human readers.
+func (c *Command) flagPartialResults(ctx context.Context, output []workflow.Data, testResult testapi.TestResult) {
+	partial, ok := testResult.(*partialTestResult)
+	if !ok {
+		return
+	}
+
+	partialErr := c.ErrorFactory.NewPartialResultsError(len(partial.findings))
+	for _, data := range output {
+		data.AddError(partialErr)
+	}
+
+	// the text report ends with the warning itself
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil || c.TextReport {
+		return
+	}
+	config := ictx.GetConfiguration()
+	if config.GetBool(FlagJSON) || config.GetBool(FlagSARIF) {
+		return
+	}
+	if err := ictx.GetUserInterface().Output(fmt.Sprintf("\nWarning: %s\n", partialErr.Detail)); err != nil {
+		c.Logger.Warn().Err(err).Msg("could not output partial results warning")
+	}
+}
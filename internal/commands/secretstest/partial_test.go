@@ -0,0 +1,182 @@
+//nolint:testpackage // whitebox testing the partial results mode
+package secretstest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/json_schemas"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+func TestCommand_CheckTestResult_AllowPartial(t *testing.T) {
+	testCases := []struct {
+		findingsErr error
+		desc        string
+	}{
+		{findingsErr: nil, desc: "incomplete findings"},
+		{findingsErr: errors.New("page 3 failed"), desc: "findings retrieval error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			_, _, cmd := setupTestCommand(t, ctrl)
+			cmd.AllowPartial = true
+
+			retrieved := []testapi.FindingData{{}, {}}
+			testResult := gafclientmocks.NewMockTestResult(ctrl)
+			testResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesFinished)
+			testResult.EXPECT().Findings(gomock.Any()).Return(retrieved, false, tc.findingsErr)
+			testResult.EXPECT().SetMetadata(MetadataIncomplete, true)
+
+			result, err := cmd.checkTestResult(t.Context(), testResult)
+			require.NoError(t, err)
+
+			findings, complete, err := result.Findings(t.Context())
+			require.NoError(t, err)
+			assert.False(t, complete)
+			assert.Equal(t, retrieved, findings)
+		})
+	}
+}
+
+// runExitCode returns the exit code of a run: 2 if the workflow failed, the exit code in the meta of an error of
+// its output if any, and otherwise 1 if its test summary counts open findings, 0 if not.
+func runExitCode(t *testing.T, output []workflow.Data, err error) int {
+	t.Helper()
+
+	if err != nil {
+		return 2
+	}
+	for _, data := range output {
+		for _, dataErr := range data.GetErrorList() {
+			if exitCode, ok := dataErr.Meta[MetaExitCode].(int); ok {
+				return exitCode
+			}
+		}
+	}
+	for _, data := range output {
+		if data.GetContentType() != content_type.TEST_SUMMARY {
+			continue
+		}
+		payload, ok := data.GetPayload().([]byte)
+		require.True(t, ok)
+		var summary json_schemas.TestSummary
+		require.NoError(t, json.Unmarshal(payload, &summary))
+		for _, result := range summary.Results {
+			if result.Open > 0 {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+func TestCommand_PartialResults_ExitCode(t *testing.T) {
+	testCases := []struct {
+		allowPartial bool
+		findings     bool
+		expected     int
+		desc         string
+	}{
+		{allowPartial: false, findings: true, expected: 2, desc: "incomplete findings fail the run"},
+		{allowPartial: true, findings: true, expected: ExitCodePartialResults, desc: "partial results with findings"},
+		{allowPartial: true, findings: false, expected: ExitCodePartialResults, desc: "partial results without findings"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			_, _, cmd := setupTestCommand(t, ctrl)
+			cmd.AllowPartial = tc.allowPartial
+			cmd.TextReport = true
+
+			retrieved := []testapi.FindingData{}
+			if tc.findings {
+				retrieved = loadTestFindings(t)
+			}
+			testResult := gafclientmocks.NewMockTestResult(ctrl)
+			testResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesFinished)
+			testResult.EXPECT().Findings(gomock.Any()).Return(retrieved, false, nil)
+			testResult.EXPECT().SetMetadata(MetadataIncomplete, true).AnyTimes()
+			testResult.EXPECT().GetMetadataValue(ReportURL).Return(nil).AnyTimes()
+
+			mockIctx := mocks.NewMockInvocationContext(ctrl)
+			mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{}).AnyTimes()
+			ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+			var output []workflow.Data
+			result, err := cmd.checkTestResult(ctx, testResult)
+			if err == nil {
+				output, err = cmd.prepareTextReport(ctx, result, nil)
+				cmd.flagPartialResults(ctx, output, result)
+			}
+			assert.Equal(t, tc.expected, runExitCode(t, output, err))
+		})
+	}
+}
+
+func TestCommand_FlagPartialResults(t *testing.T) {
+	partial := &partialTestResult{findings: []testapi.FindingData{{}, {}, {}}}
+
+	t.Run("human output", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+
+		mockIctx := mocks.NewMockInvocationContext(ctrl)
+		mockUserInterface := mocks.NewMockUserInterface(ctrl)
+		mockIctx.EXPECT().GetConfiguration().Return(configuration.New())
+		mockIctx.EXPECT().GetUserInterface().Return(mockUserInterface)
+		mockUserInterface.EXPECT().Output(gomock.Any()).DoAndReturn(func(output ...any) error {
+			assert.Contains(t, output[0], "Warning: Not all findings could be retrieved")
+			assert.Contains(t, output[0], "only show 3 findings")
+			return nil
+		})
+
+		data := workflow.NewData(workflow.NewTypeIdentifier(WorkflowID, "TestResult"), "application/json", []byte("[]"))
+		cmd.flagPartialResults(cmdctx.WithIctx(t.Context(), mockIctx), []workflow.Data{data}, partial)
+
+		require.Len(t, data.GetErrorList(), 1)
+		partialErr := data.GetErrorList()[0]
+		assert.Equal(t, "warning", partialErr.Level)
+		assert.Equal(t, ExitCodePartialResults, partialErr.Meta[MetaExitCode])
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+
+		config := configuration.New()
+		config.Set(FlagJSON, true)
+		mockIctx := mocks.NewMockInvocationContext(ctrl)
+		mockIctx.EXPECT().GetConfiguration().Return(config)
+
+		data := workflow.NewData(workflow.NewTypeIdentifier(WorkflowID, "TestResult"), "application/json", []byte("[]"))
+		cmd.flagPartialResults(cmdctx.WithIctx(t.Context(), mockIctx), []workflow.Data{data}, partial)
+
+		assert.Len(t, data.GetErrorList(), 1)
+	})
+
+	t.Run("complete results", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		_, _, cmd := setupTestCommand(t, ctrl)
+
+		data := workflow.NewData(workflow.NewTypeIdentifier(WorkflowID, "TestResult"), "application/json", []byte("[]"))
+		cmd.flagPartialResults(t.Context(), []workflow.Data{data}, gafclientmocks.NewMockTestResult(ctrl))
+
+		assert.Empty(t, data.GetErrorList())
+	})
+}
@@ -0,0 +1,264 @@
+package secretstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/upload"
+)
+
+// progressInterval is how often the progress of a running phase is reported.
+const progressInterval = 250 * time.Millisecond
+
+// ScanStateStarted is the scan state reported until the test API returns the execution state of a test.
+const ScanStateStarted = "started"
+
+// phaseTitles are the progress bar titles of the phases the reporter owns the title of.
+var phaseTitles = map[string]string{
+	PhaseFiltering: TitleFiltering,
+	PhaseUpload:    TitleUploading,
+}
+
+// workflowProgress records how far a run got, to be reported while it runs and when it is interrupted.
+type workflowProgress struct {
+	filesWalked   atomic.Int64
+	filesFiltered atomic.Int64
+	// filesUploaded counts the files handed to the uploader, and the files it accepted once the revision is sealed.
+	filesUploaded  atomic.Int64
+	filteringDone  atomic.Bool
+	phase          atomic.Value
+	uploadRevision atomic.Value
+	scanState      atomic.Value
+	findings       atomic.Int64
+}
+
+// FileChecked counts a file the filter pipeline walked, and whether it passed filtering.
+func (p *workflowProgress) FileChecked(kept bool) {
+	p.filesWalked.Add(1)
+	if kept {
+		p.filesFiltered.Add(1)
+	}
+}
+
+// forwardToUpload forwards the filtered paths to the upload, counting the files handed over.
+func (p *workflowProgress) forwardToUpload(ctx context.Context, paths <-chan string) <-chan string {
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		for path := range paths {
+			select {
+			case out <- path:
+				p.filesUploaded.Add(1)
+			case <-ctx.Done():
+				return
+			}
+		}
+		// the pipeline also closes its channel when the context ends before the walk completes
+		if ctx.Err() == nil {
+			p.filteringDone.Store(true)
+		}
+	}()
+
+	return out
+}
+
+// uploadPhase returns the phase an interrupted upload was in.
+func (p *workflowProgress) uploadPhase() string {
+	if p.filteringDone.Load() {
+		return PhaseUpload
+	}
+	return PhaseFiltering
+}
+
+// currentPhase returns the phase set last with setPhase.
+func (p *workflowProgress) currentPhase() string {
+	phase, _ := p.phase.Load().(string) //nolint:errcheck // only strings are stored
+	return phase
+}
+
+func (p *workflowProgress) setPhase(phase string) {
+	p.phase.Store(phase)
+}
+
+func (p *workflowProgress) String() string {
+	var parts []string
+
+	if n := p.filesFiltered.Load(); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d files passed filtering", n))
+	}
+	if revision, ok := p.uploadRevision.Load().(string); ok && revision != "" {
+		parts = append(parts, fmt.Sprintf("upload revision %s was created", revision))
+	}
+	if n := p.findings.Load(); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d findings were retrieved", n))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// ProgressEvent is a snapshot of the progress of a run, written as one line of the --progress-json stream.
+type ProgressEvent struct {
+	Time          time.Time `json:"time"`
+	Phase         string    `json:"phase"`
+	FilesWalked   int64     `json:"filesWalked"`
+	FilesFiltered int64     `json:"filesFiltered"`
+	FilesUploaded int64     `json:"filesUploaded"`
+	BytesSent     int64     `json:"bytesSent"`
+	ScanState     string    `json:"scanState,omitempty"`
+	Findings      int64     `json:"findings"`
+	// Progress is the completed fraction of the phase, if it is known.
+	Progress *float64 `json:"progress,omitempty"`
+}
+
+// sameAs reports whether e and other report the same progress, regardless of when.
+func (e *ProgressEvent) sameAs(other *ProgressEvent) bool {
+	a, b := *e, *other
+	a.Time, b.Time = time.Time{}, time.Time{}
+	if a.Progress != nil && b.Progress != nil && *a.Progress == *b.Progress {
+		a.Progress, b.Progress = nil, nil
+	}
+	return a == b
+}
+
+// counts describes the progress of the phase for the progress bar.
+func (e *ProgressEvent) counts() string {
+	switch e.Phase {
+	case PhaseFiltering:
+		return fmt.Sprintf("%d files checked, %d kept", e.FilesWalked, e.FilesFiltered)
+	case PhaseUpload:
+		return fmt.Sprintf("%d of %d files uploaded, %s sent", e.FilesUploaded, e.FilesFiltered, formatBytes(e.BytesSent))
+	case PhaseScan:
+		if e.ScanState == "" {
+			return ""
+		}
+		return "test " + e.ScanState
+	case PhaseResults:
+		return fmt.Sprintf("%d findings retrieved", e.Findings)
+	default:
+		return ""
+	}
+}
+
+// progressReporter shows the progress of a run on the progress bar and, if enabled, streams it as NDJSON.
+type progressReporter struct {
+	ui        UserInterface
+	stream    io.Writer
+	progress  *workflowProgress
+	bytesSent *upload.ByteCounter
+	logger    *zerolog.Logger
+	interval  time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	phase string
+	last  *ProgressEvent
+}
+
+// start reports the progress of a phase until the returned function is called, which reports it a last time.
+// phase returns the phase the run is in, as it may move on while it is reported.
+func (r *progressReporter) start(phase func() string) func() {
+	if r == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				r.report(phase())
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		r.report(phase())
+	}
+}
+
+// report emits the progress of phase if it changed since the last report.
+func (r *progressReporter) report(phase string) {
+	event := r.snapshot(phase)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last != nil && r.last.sameAs(&event) {
+		return
+	}
+	r.last = &event
+
+	if r.ui != nil {
+		if title, ok := phaseTitles[phase]; ok && phase != r.phase {
+			r.ui.SetTitle(title)
+		}
+		if counts := event.counts(); counts != "" {
+			r.ui.SetCounts(counts)
+		}
+		if event.Progress != nil {
+			r.ui.SetProgress(*event.Progress)
+		}
+	}
+	r.phase = phase
+
+	if r.stream != nil {
+		if err := json.NewEncoder(r.stream).Encode(&event); err != nil {
+			r.logger.Debug().Err(err).Msg("Failed to write progress event")
+		}
+	}
+}
+
+func (r *progressReporter) snapshot(phase string) ProgressEvent {
+	p := r.progress
+	event := ProgressEvent{
+		Time:          r.now().UTC(),
+		Phase:         phase,
+		FilesWalked:   p.filesWalked.Load(),
+		FilesFiltered: p.filesFiltered.Load(),
+		FilesUploaded: p.filesUploaded.Load(),
+		BytesSent:     r.bytesSent.Load(),
+		Findings:      p.findings.Load(),
+	}
+	event.ScanState, _ = p.scanState.Load().(string) //nolint:errcheck // only strings are stored
+
+	// the number of files to upload is only known once filtering is done
+	if phase == PhaseUpload && event.FilesFiltered > 0 {
+		fraction := min(float64(event.FilesUploaded)/float64(event.FilesFiltered), 1)
+		event.Progress = &fraction
+	}
+
+	return event
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. 1.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,190 @@
+//nolint:testpackage // whitebox testing the progress reporting
+package secretstest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/ui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	mock_secretstest "github.com/snyk/cli-extension-secrets/internal/commands/secretstest/testdata/mocks"
+)
+
+var progressTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestReporter(userInterface UserInterface, stream *bytes.Buffer, progress *workflowProgress) *progressReporter {
+	logger := zerolog.Nop()
+	return &progressReporter{
+		ui:       userInterface,
+		stream:   stream,
+		progress: progress,
+		logger:   &logger,
+		interval: time.Hour,
+		now:      func() time.Time { return progressTime },
+	}
+}
+
+func readProgressEvents(t *testing.T, stream *bytes.Buffer) []ProgressEvent {
+	t.Helper()
+
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		var event ProgressEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event), scanner.Text())
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestProgressReporter_Report(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockUI := mock_secretstest.NewMockUserInterface(ctrl)
+	var stream bytes.Buffer
+	progress := &workflowProgress{}
+	reporter := newTestReporter(mockUI, &stream, progress)
+
+	progress.FileChecked(true)
+	progress.FileChecked(false)
+	progress.FileChecked(true)
+	gomock.InOrder(
+		mockUI.EXPECT().SetTitle(TitleFiltering),
+		mockUI.EXPECT().SetCounts("3 files checked, 2 kept"),
+	)
+	reporter.report(PhaseFiltering)
+	// an unchanged progress is not reported again
+	reporter.report(PhaseFiltering)
+
+	progress.filesUploaded.Store(1)
+	gomock.InOrder(
+		mockUI.EXPECT().SetTitle(TitleUploading),
+		mockUI.EXPECT().SetCounts("1 of 2 files uploaded, 0 B sent"),
+		mockUI.EXPECT().SetProgress(0.5),
+	)
+	reporter.report(PhaseUpload)
+
+	progress.scanState.Store(ScanStateStarted)
+	mockUI.EXPECT().SetCounts("test started")
+	reporter.report(PhaseScan)
+
+	events := readProgressEvents(t, &stream)
+	require.Len(t, events, 3)
+	assert.Equal(t, ProgressEvent{
+		Time:          progressTime,
+		Phase:         PhaseFiltering,
+		FilesWalked:   3,
+		FilesFiltered: 2,
+	}, events[0])
+	require.NotNil(t, events[1].Progress)
+	assert.InDelta(t, 0.5, *events[1].Progress, 0)
+	assert.Equal(t, PhaseScan, events[2].Phase)
+	assert.Equal(t, ScanStateStarted, events[2].ScanState)
+	assert.Nil(t, events[2].Progress)
+}
+
+func TestProgressReporter_StartReportsUntilStopped(t *testing.T) {
+	var stream bytes.Buffer
+	progress := &workflowProgress{}
+	reporter := newTestReporter(nil, &stream, progress)
+	reporter.interval = time.Millisecond
+
+	stop := reporter.start(func() string { return PhaseFiltering })
+	progress.FileChecked(true)
+	assert.Eventually(t, func() bool {
+		reporter.mu.Lock()
+		defer reporter.mu.Unlock()
+		return reporter.last != nil && reporter.last.FilesWalked == 1
+	}, time.Second, time.Millisecond)
+	progress.FileChecked(false)
+	stop()
+
+	events := readProgressEvents(t, &stream)
+	require.NotEmpty(t, events)
+	assert.Equal(t, int64(2), events[len(events)-1].FilesWalked, "stopping reports the final progress")
+}
+
+func TestProgressReporter_Nil(t *testing.T) {
+	var reporter *progressReporter
+
+	assert.NotPanics(t, func() { reporter.start(func() string { return PhaseScan })() })
+}
+
+func TestCommand_RunWorkflow_StreamsUploadProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	var stream bytes.Buffer
+	cmd.reporter = newTestReporter(nil, &stream, &cmd.progress)
+	ctx := cmdctx.WithIctx(t.Context(), mocks.NewMockInvocationContext(ctrl))
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content"), 0o600))
+	}
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			for range paths {
+			}
+			return fileupload.UploadResult{RevisionID: uuid.New(), UploadedFilesCount: 2}, nil
+		})
+
+	_, err := cmd.filterAndUploadFiles(ctx, dir)
+	require.NoError(t, err)
+
+	events := readProgressEvents(t, &stream)
+	require.NotEmpty(t, events)
+	last := events[len(events)-1]
+	assert.Equal(t, PhaseUpload, last.Phase)
+	assert.Equal(t, int64(2), last.FilesWalked)
+	assert.Equal(t, int64(2), last.FilesFiltered)
+	assert.Equal(t, int64(2), last.FilesUploaded)
+	require.NotNil(t, last.Progress)
+	assert.InDelta(t, 1.0, *last.Progress, 0)
+}
+
+func TestCLIUserInterface_Progress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockProgressBar := mocks.NewMockProgressBar(ctrl)
+	logger := zerolog.Nop()
+	u := &CLIUserInterface{logger: &logger, progressbar: mockProgressBar}
+
+	gomock.InOrder(
+		mockProgressBar.EXPECT().SetTitle(TitleUploading),
+		mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress),
+		mockProgressBar.EXPECT().SetTitle(TitleUploading+" 1 of 4 files uploaded"),
+		mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress),
+		mockProgressBar.EXPECT().UpdateProgress(0.25),
+		mockProgressBar.EXPECT().SetTitle(TitleScanning),
+		mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress),
+	)
+
+	u.SetTitle(TitleUploading)
+	u.SetCounts("1 of 4 files uploaded")
+	u.SetProgress(0.25)
+	u.SetTitle(TitleScanning)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", formatBytes(0))
+	assert.Equal(t, "1023 B", formatBytes(1023))
+	assert.Equal(t, "1.0 KiB", formatBytes(1024))
+	assert.Equal(t, "1.5 MiB", formatBytes(3<<19))
+	assert.Equal(t, "2.0 GiB", formatBytes(2<<30))
+}
@@ -0,0 +1,40 @@
+package secretstest
+
+import (
+	"context"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// writeReports writes the findings of testResult at the severity threshold or above to the report files requested
+// with --junit-file-output, --gitlab-file-output, --html-file-output and --template-file-output, and as the
+// annotations requested with --annotations.
+// The CLI writes the JSON and SARIF files itself.
+func (c *Command) writeReports(ctx context.Context, testResult testapi.TestResult) error {
+	if c.JUnitOutputPath == "" && c.GitLabOutputPath == "" && c.HTMLOutputPath == "" && c.Annotations == "" && c.TemplateOutputPath == "" {
+		return nil
+	}
+
+	findings, err := c.reportFindings(ctx, testResult)
+	if err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to write reports")
+	}
+	findings = report.FilterBySeverity(findings, c.SeverityThreshold)
+
+	if err := c.writeJUnitReport(findings); err != nil {
+		return err
+	}
+	if err := c.writeGitLabReport(ctx, findings); err != nil {
+		return err
+	}
+	if err := c.writeHTMLReport(findings, testResult); err != nil {
+		return err
+	}
+	if err := c.writeTemplateFile(findings, testResult); err != nil {
+		return err
+	}
+	c.writeAnnotations(ctx, findings)
+	return nil
+}
@@ -0,0 +1,55 @@
+package secretstest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/snyk/go-application-framework/pkg/utils/git"
+)
+
+var errRepoURLNotScrubbed = errors.New("repository URL still contains userinfo, query or fragment")
+
+// scrubRepoURL normalizes a git remote URL and removes everything that may carry
+// secrets: userinfo (user, password or token), query string and fragment.
+// The result is verified so that a URL which cannot be scrubbed is never returned.
+func scrubRepoURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", nil
+	}
+
+	// NormalizeGitURL converts SSH and SCP-style URLs to https and strips userinfo
+	normalized := git.NormalizeGitURL(rawURL)
+
+	parsedURL, err := url.Parse(normalized)
+	if err != nil {
+		// the parse error may echo the URL, so it is not wrapped
+		return "", fmt.Errorf("could not parse repository URL")
+	}
+
+	parsedURL.User = nil
+	parsedURL.RawQuery = ""
+	parsedURL.ForceQuery = false
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+
+	scrubbed := parsedURL.String()
+	if err := verifyScrubbedURL(scrubbed); err != nil {
+		return "", err
+	}
+
+	return scrubbed, nil
+}
+
+func verifyScrubbedURL(scrubbedURL string) error {
+	parsedURL, err := url.Parse(scrubbedURL)
+	if err != nil {
+		return fmt.Errorf("could not parse scrubbed repository URL")
+	}
+
+	if parsedURL.User != nil || parsedURL.RawQuery != "" || parsedURL.ForceQuery || parsedURL.Fragment != "" {
+		return errRepoURLNotScrubbed
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package secretstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/instrumentation"
+)
+
+// FlagTestID is the flag of the secrets result command selecting the test to retrieve.
+const FlagTestID = "test-id"
+
+// Content types of the test submission output.
+const (
+	contentTypeJSON = "application/json"
+	contentTypeText = "text/plain"
+)
+
+// ResultWorkflowID is the unique identifier for the secrets result workflow.
+var ResultWorkflowID = workflow.NewWorkflowIdentifier("secrets.result")
+
+// testSubmission identifies a test started with --no-wait.
+type testSubmission struct {
+	TestID         string `json:"testId"`
+	UploadRevision string `json:"uploadRevision"`
+}
+
+func (s testSubmission) toWorkflowData(id workflow.Identifier, jsonOutput bool) (workflow.Data, error) {
+	typeID := workflow.NewTypeIdentifier(id, "submission")
+
+	if jsonOutput {
+		payload, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal test submission: %w", err)
+		}
+		return workflow.NewData(typeID, contentTypeJSON, payload), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Test submitted, results are not awaited.\n\n")
+	fmt.Fprintf(&b, "Test ID:         %s\n", s.TestID)
+	fmt.Fprintf(&b, "Upload revision: %s\n\n", s.UploadRevision)
+	fmt.Fprintf(&b, "Retrieve the results with: snyk secrets result --%s=%s\n", FlagTestID, s.TestID)
+	return workflow.NewData(typeID, contentTypeText, []byte(b.String())), nil
+}
+
+// GetSecretsResultFlagSet returns the flag set for the secrets result command.
+func GetSecretsResultFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet("snyk-cli-extension-secrets-result", pflag.ExitOnError)
+
+	flagSet.String(FlagTestID, "", "The ID of a test started with --no-wait.")
+	flagSet.Bool(FlagJSON, false, "Print results on the console as a JSON data structure.")
+	flagSet.Bool(FlagSARIF, false, "Return results in SARIF format.")
+	flagSet.String(FlagJSONFileOutput, "",
+		"Save test output as a JSON data structure directly to the specified file, regardless of whether or not you use the --json option.")
+	flagSet.String(FlagSARIFFileOutput, "",
+		"Save test output in SARIF format directly to the specified file, regardless of whether or not you use the --sarif option.")
+	addJUnitFileOutputFlag(flagSet)
+	addGitLabFileOutputFlag(flagSet)
+	addHTMLFileOutputFlag(flagSet)
+	addAnnotationsFlag(flagSet)
+	addTemplateFlags(flagSet)
+	flagSet.Int(FlagMaxRetries, retry.DefaultMaxRetries, "The number of times a request failing transiently is retried.")
+	addAllowPartialFlag(flagSet)
+	addPollFlags(flagSet)
+	flagSet.String(FlagTimeout, "", "Abort if the results are not available within the given duration, e.g. 600 (seconds) or 10m.")
+	addScanTimeoutFlags(flagSet)
+
+	return flagSet
+}
+
+// ResultWorkflow is the entry point for the secrets result workflow.
+// It retrieves the results of a test started with --no-wait and renders them like a synchronous run.
+func ResultWorkflow(
+	ictx workflow.InvocationContext,
+	_ []workflow.Data,
+) ([]workflow.Data, error) {
+	config := ictx.GetConfiguration()
+	logger := ictx.GetEnhancedLogger()
+	errorFactory := NewErrorFactory(logger)
+
+	u := NewUI(ictx)
+	u.SetTitle(TitleValidating)
+	defer u.Clear()
+
+	orgID, testID, err := validateResultInput(config, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseDurationFlag(config, FlagTimeout)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	phaseTimeouts, err := parsePhaseTimeouts(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	formatTemplate, err := loadFormatTemplate(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	ctx, cancel := newWorkflowContext(timeout)
+	defer cancel()
+	ctx = cmdctx.WithIctx(ctx, ictx)
+	ctx = cmdctx.WithInstrumentation(ctx, instrumentation.NewGAFInstrumentation(ictx.GetAnalytics()))
+
+	orgID, err = resolveOrgID(ctx, ictx, orgID, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	args := &CommandArgs{
+		InvocationContext:  ictx,
+		UserInterface:      u,
+		OrgID:              orgID,
+		GetClients:         newResultClients,
+		ErrorFactory:       errorFactory,
+		ReportConfig:       ReportConfig{ProjectPageURL: buildProjectPageURL(config)},
+		AllowPartial:       config.GetBool(FlagAllowPartial),
+		TextReport:         isTextReport(config),
+		SARIFReport:        isSARIFReport(config),
+		Color:              useColor(),
+		Timeouts:           phaseTimeouts,
+		JUnitOutputPath:    config.GetString(FlagJUnitFileOutput),
+		GitLabOutputPath:   config.GetString(FlagGitLabFileOutput),
+		HTMLOutputPath:     config.GetString(FlagHTMLFileOutput),
+		FormatTemplate:     formatTemplate,
+		TemplateOutputPath: config.GetString(FlagTemplateFileOutput),
+	}
+	applyAnnotationArgs(args, config)
+	c, err := NewCommand(args)
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnableToInitializeMsg)
+	}
+
+	logger.Info().Str("testID", testID.String()).Msg("Retrieving secrets test results...")
+	output, err := c.RetrieveResults(ctx, testID)
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnexpectedErrorMsg)
+	}
+
+	return output, nil
+}
+
+// RetrieveResults waits for a previously submitted test and returns its formatted results.
+func (c *Command) RetrieveResults(ctx context.Context, testID uuid.UUID) ([]workflow.Data, error) {
+	defer c.recordRetries(ctx)
+
+	c.UserInterface.SetTitle(TitleRetrievingResults)
+
+	testResult, err := c.fetchTestResult(ctx, testID)
+	if err != nil {
+		return nil, c.ErrorFactory.NewExecuteTestError(err)
+	}
+
+	// the report URL is only available if the test was started with --report, and the output only shows the
+	// findings at the severity threshold it was started with, as a synchronous run does
+	if cfg := testResult.GetTestConfiguration(); cfg != nil {
+		if cfg.PublishReport != nil {
+			c.ReportConfig.Report = *cfg.PublishReport
+		}
+		if cfg.LocalPolicy != nil && cfg.LocalPolicy.SeverityThreshold != nil {
+			c.SeverityThreshold = string(*cfg.LocalPolicy.SeverityThreshold)
+		}
+	}
+
+	c.addFingerprints(ctx, testResult)
+	output, err := c.prepareOutputForRun(ctx, testResult, nil)
+	if err != nil {
+		return nil, c.ErrorFactory.NewPrepareOutputError(err)
+	}
+	if err := c.writeReports(ctx, testResult); err != nil {
+		return nil, err
+	}
+	c.flagPartialResults(ctx, output, testResult)
+	return output, nil
+}
+
+// fetchTestResult waits for a previously submitted test and returns its result.
+//
+//nolint:ireturn // supposed to return interface.
+func (c *Command) fetchTestResult(ctx context.Context, testID uuid.UUID) (testapi.TestResult, error) {
+	scanCtx, cancelScan := withDeadline(ctx, "the scan deadline", c.Timeouts.Scan)
+	defer cancelScan()
+
+	testResult, err := c.Clients.TestAPIShim.GetTestResult(scanCtx, c.OrgID, testID)
+	if err != nil {
+		return nil, c.interrupted(scanCtx, PhaseScan, fmt.Errorf("failed to retrieve test: %w", err))
+	}
+	return c.retrieveTestResult(ctx, testResult)
+}
+
+func validateResultInput(config configuration.Configuration, errorFactory *ErrorFactory) (string, uuid.UUID, error) {
+	if !config.GetBool(FeatureFlagIsSecretsEnabled) {
+		return "", uuid.Nil, errorFactory.NewFeatureNotEnabledError(FeatureNotEnabledMsg)
+	}
+
+	orgID := config.GetString(configuration.ORGANIZATION)
+	if orgID == "" {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(NoOrgProvidedMsg)
+	}
+	if err := validateOrg(orgID); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	rawTestID := strings.TrimSpace(config.GetString(FlagTestID))
+	if rawTestID == "" {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(fmt.Sprintf("Missing --%s.", FlagTestID))
+	}
+	testID, err := uuid.Parse(rawTestID)
+	if err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(fmt.Sprintf("Invalid --%s: %s is not a valid test ID.", FlagTestID, rawTestID))
+	}
+
+	if err := validateFileOutputPaths(config); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	if err := validateAnnotations(config); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	if err := validateTemplateFlags(config); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	if err := validateMaxRetries(config); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	if err := validatePollIntervals(config); err != nil {
+		return "", uuid.Nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	return orgID, testID, nil
+}
+
+func newResultClients(ictx workflow.InvocationContext, _ string) (*WorkflowClients, error) {
+	retryConfig := buildRetryConfig(ictx.GetConfiguration())
+
+	testShimClient, err := testshim.NewClient(ictx, retryConfig, buildPollConfig(ictx.GetConfiguration()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create test shim client: %w", err)
+	}
+
+	return &WorkflowClients{TestAPIShim: testShimClient, Retries: retryConfig.Counter}, nil
+}
@@ -0,0 +1,255 @@
+//nolint:testpackage // whitebox testing the asynchronous test flow
+package secretstest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/snyk/error-catalog-golang-public/errorcodes"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/utils/ufm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
+	mock_testshim "github.com/snyk/cli-extension-secrets/internal/clients/testshim/mocks"
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+func TestCommand_RunWorkflow_NoWait(t *testing.T) {
+	testCases := []struct {
+		jsonOutput  bool
+		contentType string
+		desc        string
+	}{
+		{jsonOutput: false, contentType: contentTypeText, desc: "human output"},
+		{jsonOutput: true, contentType: contentTypeJSON, desc: "json output"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+			cmd.NoWait = true
+
+			config := configuration.New()
+			config.Set(FlagJSON, tc.jsonOutput)
+			mockIctx := mocks.NewMockInvocationContext(ctrl)
+			mockIctx.EXPECT().GetConfiguration().Return(config)
+			mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{})
+			ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+			revisionID := uuid.New()
+			mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+			mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(fileupload.UploadResult{RevisionID: revisionID}, nil)
+
+			testID := uuid.New()
+			mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+			mockTestShimClient.EXPECT().StartTest(gomock.Any(), gomock.Any()).Times(0)
+			mockTestShimClient.EXPECT().SubmitTest(gomock.Any(), gomock.Any()).Return(testID, nil)
+			mockUI.EXPECT().SetTitle(gomock.Any()).Times(0)
+
+			output, err := cmd.RunWorkflow(ctx, ".")
+			require.NoError(t, err)
+			require.Len(t, output, 1)
+			assert.Equal(t, tc.contentType, output[0].GetContentType())
+
+			payload, ok := output[0].GetPayload().([]byte)
+			require.True(t, ok)
+			if tc.jsonOutput {
+				var submission testSubmission
+				require.NoError(t, json.Unmarshal(payload, &submission))
+				assert.Equal(t, testSubmission{TestID: testID.String(), UploadRevision: revisionID.String()}, submission)
+			} else {
+				assert.Contains(t, string(payload), testID.String())
+				assert.Contains(t, string(payload), revisionID.String())
+			}
+		})
+	}
+}
+
+func TestCommand_RunWorkflow_NoWait_SubmitFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	cmd.NoWait = true
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fileupload.UploadResult{RevisionID: uuid.New()}, nil)
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().SubmitTest(gomock.Any(), gomock.Any()).Return(uuid.Nil, errors.New("forbidden"))
+
+	_, err := cmd.RunWorkflow(t.Context(), ".")
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "error executing test")
+}
+
+func TestCommand_RetrieveResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{})
+	ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+	findingContent, err := os.ReadFile("./testdata/finding.json")
+	require.NoError(t, err)
+	var expectedFindings []testapi.FindingData
+	require.NoError(t, json.Unmarshal(findingContent, &expectedFindings))
+
+	mockTestResult := gafclientmocks.NewMockTestResult(ctrl)
+	setupMockTestResultForPrepareOutput(mockTestResult)
+	mockTestResult.EXPECT().Findings(gomock.Any()).Return(expectedFindings, true, nil).AnyTimes()
+	mockTestResult.EXPECT().Get(testapi.TestResultComponents).Return(&[]testapi.TestComponent{}).AnyTimes()
+
+	testID := uuid.New()
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), cmd.OrgID, testID).Return(mockTestResult, nil)
+	mockUI.EXPECT().SetTitle(TitleRetrievingResults)
+
+	output, err := cmd.RetrieveResults(ctx, testID)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+
+	results := ufm.GetTestResultsFromWorkflowData(output[0])
+	require.Len(t, results, 1)
+	findings, _, err := results[0].Findings(ctx)
+	require.NoError(t, err)
+	assert.Len(t, findings, 1)
+}
+
+func TestCommand_RetrieveResults_SeverityThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{}).AnyTimes()
+	ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+	threshold := testapi.SeverityCritical
+	mockTestResult := gafclientmocks.NewMockTestResult(ctrl)
+	// registered first, so that it takes precedence over the empty test configuration
+	mockTestResult.EXPECT().GetTestConfiguration().
+		Return(&testapi.TestConfiguration{LocalPolicy: &testapi.LocalPolicy{SeverityThreshold: &threshold}}).AnyTimes()
+	setupMockTestResultForPrepareOutput(mockTestResult)
+	mockTestResult.EXPECT().Findings(gomock.Any()).Return([]testapi.FindingData{}, true, nil).AnyTimes()
+	mockTestResult.EXPECT().Get(testapi.TestResultComponents).Return(&[]testapi.TestComponent{}).AnyTimes()
+
+	mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+	mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockTestResult, nil)
+	mockUI.EXPECT().SetTitle(TitleRetrievingResults)
+
+	_, err := cmd.RetrieveResults(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, string(testapi.SeverityCritical), cmd.SeverityThreshold)
+}
+
+func TestCommand_RetrieveResults_Errors(t *testing.T) {
+	t.Run("test cannot be retrieved", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+		mockUI.EXPECT().SetTitle(gomock.Any())
+
+		mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+		mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("not found"))
+
+		_, err := cmd.RetrieveResults(t.Context(), uuid.New())
+		catalogErr := requireCatalogError(t, err)
+		assert.Contains(t, catalogErr.Detail, "error executing test")
+	})
+
+	t.Run("test not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+		mockUI.EXPECT().SetTitle(gomock.Any())
+
+		mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+		mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, &testshim.StatusError{StatusCode: http.StatusNotFound, Resource: testshim.ResourceTest})
+
+		_, err := cmd.RetrieveResults(t.Context(), uuid.New())
+		catalogErr := requireCatalogError(t, err)
+		assert.Equal(t, errorcodes.OpenAPI.NotFoundError, catalogErr.ErrorCode)
+		assert.Contains(t, catalogErr.Detail, "The test was not found")
+	})
+
+	t.Run("test errored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClients, mockUI, cmd := setupTestCommand(t, ctrl)
+		mockUI.EXPECT().SetTitle(gomock.Any())
+
+		mockTestResult := gafclientmocks.NewMockTestResult(ctrl)
+		mockTestResult.EXPECT().GetExecutionState().Return(testapi.TestExecutionStatesErrored)
+		mockTestResult.EXPECT().GetErrors().Return(&[]testapi.IoSnykApiCommonError{{Detail: "scan failed"}})
+		mockTestShimClient := mockClients.TestAPIShim.(*mock_testshim.MockClient)
+		mockTestShimClient.EXPECT().GetTestResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockTestResult, nil)
+
+		_, err := cmd.RetrieveResults(t.Context(), uuid.New())
+		catalogErr := requireCatalogError(t, err)
+		assert.Contains(t, catalogErr.Detail, "error executing test")
+	})
+}
+
+func TestValidateResultInput(t *testing.T) {
+	testID := uuid.New()
+	testCases := []struct {
+		in     map[string]any
+		hasErr bool
+		desc   string
+	}{
+		{
+			in:     map[string]any{FeatureFlagIsSecretsEnabled: true, configuration.ORGANIZATION: "org", FlagTestID: testID.String()},
+			hasErr: false,
+			desc:   "valid test ID",
+		},
+		{
+			in:     map[string]any{FeatureFlagIsSecretsEnabled: false, configuration.ORGANIZATION: "org", FlagTestID: testID.String()},
+			hasErr: true,
+			desc:   "feature flag disabled",
+		},
+		{
+			in:     map[string]any{FeatureFlagIsSecretsEnabled: true, FlagTestID: testID.String()},
+			hasErr: true,
+			desc:   "no org",
+		},
+		{
+			in:     map[string]any{FeatureFlagIsSecretsEnabled: true, configuration.ORGANIZATION: "org"},
+			hasErr: true,
+			desc:   "missing test ID",
+		},
+		{
+			in:     map[string]any{FeatureFlagIsSecretsEnabled: true, configuration.ORGANIZATION: "org", FlagTestID: "not-a-uuid"},
+			hasErr: true,
+			desc:   "invalid test ID",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := setupMockConfig(tc.in)
+
+			orgID, parsedTestID, err := validateResultInput(config, NewErrorFactory(nil))
+			if tc.hasErr {
+				requireCatalogError(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "org", orgID)
+			assert.Equal(t, testID, parsedTestID)
+		})
+	}
+}
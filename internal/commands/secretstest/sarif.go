@@ -0,0 +1,67 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// contentTypeSARIF is the content type of the SARIF output, which the CLI prints with --sarif and writes to
+// --sarif-file-output.
+const contentTypeSARIF = "application/sarif+json"
+
+// isSARIFReport reports whether the SARIF output is rendered here rather than by the CLI, which leaves out the
+// fingerprints of findings: when SARIF is printed or written to a file, but JSON is not. The CLI renders both from
+// the test result data, so with JSON output the fingerprints are only part of the JSON findings.
+func isSARIFReport(config configuration.Configuration) bool {
+	if config.GetBool(FlagJSON) || config.GetString(FlagJSONFileOutput) != "" {
+		return false
+	}
+	return config.GetBool(FlagSARIF) || config.GetString(FlagSARIFFileOutput) != ""
+}
+
+// prepareSARIFOutput renders the findings of testResult at the severity threshold or above as SARIF, for the CLI
+// to print or write to --sarif-file-output in place of the test result data.
+func (c *Command) prepareSARIFOutput(ctx context.Context, testResult testapi.TestResult) ([]workflow.Data, error) {
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil {
+		return nil, fmt.Errorf("invocation context is nil")
+	}
+
+	findings, err := c.reportFindings(ctx, testResult)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := report.SARIFOptions{}
+	if info := ictx.GetRuntimeInfo(); info != nil {
+		opts.ToolVersion = info.GetVersion()
+	}
+	if _, ok := testResult.(*partialTestResult); ok {
+		opts.Incomplete = true
+	}
+
+	var b bytes.Buffer
+	if err := report.RenderSARIF(&b, report.FilterBySeverity(findings, c.SeverityThreshold), opts); err != nil {
+		return nil, err
+	}
+
+	id := ictx.GetWorkflowIdentifier()
+	output := []workflow.Data{workflow.NewData(workflow.NewTypeIdentifier(id, "sarif"), contentTypeSARIF, b.Bytes())}
+	if c.TextReport {
+		// the text report carries the test summary
+		return output, nil
+	}
+	summary, err := c.testSummaryData(id, findings)
+	if err != nil {
+		return nil, err
+	}
+	return append(output, summary), nil
+}
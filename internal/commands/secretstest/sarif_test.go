@@ -0,0 +1,117 @@
+package secretstest
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/runtimeinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/blame"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+func TestIsSARIFReport(t *testing.T) {
+	testCases := []struct {
+		config map[string]any
+		want   bool
+		desc   string
+	}{
+		{config: map[string]any{}, want: false, desc: "human output"},
+		{config: map[string]any{FlagSARIF: true}, want: true, desc: "sarif"},
+		{config: map[string]any{FlagSARIFFileOutput: "results.sarif"}, want: true, desc: "sarif file output"},
+		{config: map[string]any{FlagSARIF: true, FlagJSONFileOutput: "results.json"}, want: false, desc: "sarif and json file output"},
+		{config: map[string]any{FlagJSON: true, FlagSARIFFileOutput: "results.sarif"}, want: false, desc: "json and sarif file output"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, isSARIFReport(setupMockConfig(tc.config)))
+		})
+	}
+}
+
+func TestCommand_PrepareOutputForRun_SARIF(t *testing.T) {
+	testCases := []struct {
+		textReport   bool
+		contentTypes []string
+		desc         string
+	}{
+		{textReport: false, contentTypes: []string{contentTypeSARIF, content_type.TEST_SUMMARY}, desc: "printed"},
+		{textReport: true, contentTypes: []string{contentTypeText, content_type.TEST_SUMMARY, contentTypeSARIF}, desc: "written to a file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			_, _, cmd := setupTestCommand(t, ctrl)
+			cmd.SARIFReport = true
+			cmd.TextReport = tc.textReport
+			cmd.fingerprints = map[string]string{"bdaa4c47-9609-435c-80ef-317586c3a97a": "fp-1"}
+			cmd.owners = map[string][]string{"bdaa4c47-9609-435c-80ef-317586c3a97a": {"@security"}}
+			blamedAt := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+			cmd.blames = map[string][]blameLocation{"bdaa4c47-9609-435c-80ef-317586c3a97a": {
+				{FilePath: "gcp-credentials.json", FromLine: 7, Info: blame.Info{CommitSHA: "4f2a9c1e", AuthorEmail: "dev@example.com", Timestamp: blamedAt}},
+			}}
+
+			testResult := gafclientmocks.NewMockTestResult(ctrl)
+			setupMockTestResultForPrepareOutput(testResult)
+			testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil).AnyTimes()
+			testResult.EXPECT().Get(gomock.Any()).Return(nil).AnyTimes()
+			testResult.EXPECT().GetMetadataValue(ReportURL).Return(nil).AnyTimes()
+
+			mockIctx := mocks.NewMockInvocationContext(ctrl)
+			mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{}).AnyTimes()
+			mockIctx.EXPECT().GetRuntimeInfo().Return(runtimeinfo.New(runtimeinfo.WithVersion("1.1300.0"))).AnyTimes()
+			ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+			output, err := cmd.prepareOutputForRun(ctx, testResult, nil)
+			require.NoError(t, err)
+
+			contentTypes := make([]string, 0, len(output))
+			for _, data := range output {
+				contentTypes = append(contentTypes, data.GetContentType())
+			}
+			assert.Equal(t, tc.contentTypes, contentTypes, "the SARIF output replaces the test result data")
+
+			sarif, ok := output[len(output)-1].GetPayload().([]byte)
+			if !tc.textReport {
+				sarif, ok = output[0].GetPayload().([]byte)
+			}
+			require.True(t, ok)
+			var log struct {
+				Runs []struct {
+					Results []struct {
+						PartialFingerprints map[string]string `json:"partialFingerprints"`
+						Properties          struct {
+							Owners []string `json:"owners"`
+							Blame  []struct {
+								CommitSHA   string    `json:"commitSha"`
+								AuthorEmail string    `json:"authorEmail"`
+								Timestamp   time.Time `json:"timestamp"`
+							} `json:"blame"`
+						} `json:"properties"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			require.NoError(t, json.Unmarshal(sarif, &log))
+			require.Len(t, log.Runs, 1)
+			require.Len(t, log.Runs[0].Results, 1)
+			result := log.Runs[0].Results[0]
+			assert.Equal(t, map[string]string{report.FingerprintKey: "fp-1"}, result.PartialFingerprints)
+			assert.Equal(t, []string{"@security"}, result.Properties.Owners, "the owners are properties of the result")
+			require.Len(t, result.Properties.Blame, 1, "the blame is a property of the result")
+			assert.Equal(t, "4f2a9c1e", result.Properties.Blame[0].CommitSHA)
+			assert.Equal(t, "dev@example.com", result.Properties.Blame[0].AuthorEmail)
+			assert.True(t, blamedAt.Equal(result.Properties.Blame[0].Timestamp))
+		})
+	}
+}
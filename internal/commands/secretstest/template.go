@@ -0,0 +1,100 @@
+package secretstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const templateFailureMsg = "failed to render --format-template"
+
+// loadFormatTemplate parses the template of --format-template, if set, so that errors in it fail the run before
+// any file is uploaded.
+func loadFormatTemplate(config configuration.Configuration) (*template.Template, error) {
+	nameOrPath := config.GetString(FlagFormatTemplate)
+	if nameOrPath == "" {
+		return nil, nil //nolint:nilnil // no template requested
+	}
+
+	tmpl, err := report.LoadTemplate(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid --%s: %w", FlagFormatTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// templateData returns the data --format-template is executed with: findings, which are at the severity threshold
+// or above, and the context of the test.
+func (c *Command) templateData(findings []report.Finding, testResult testapi.TestResult) report.TemplateData {
+	return report.TemplateData{
+		Findings:  findings,
+		Summary:   report.Summarize(findings),
+		SCM:       c.scmContext(),
+		ReportURL: reportURL(testResult),
+	}
+}
+
+// scmContext returns the git context of the tested files, as shown by the template and HTML output.
+func (c *Command) scmContext() report.SCMContext {
+	return report.SCMContext{
+		RepoURL:    c.RepoURL,
+		Branch:     c.Branch,
+		Commit:     c.CommitRef,
+		RootFolder: c.RootFolderID,
+	}
+}
+
+// reportURL returns the project page the results of testResult were shared to with --report, if any.
+func reportURL(testResult testapi.TestResult) string {
+	if value, ok := testResult.GetMetadataValue(ReportURL).(string); ok {
+		return value
+	}
+	return ""
+}
+
+// prepareTemplateOutput renders the findings of testResult through --format-template for the CLI to print, along
+// with the test summary the CLI derives its exit code from.
+func (c *Command) prepareTemplateOutput(ctx context.Context, testResult testapi.TestResult) ([]workflow.Data, error) {
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil {
+		return nil, fmt.Errorf("invocation context is nil")
+	}
+
+	findings, err := c.reportFindings(ctx, testResult)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	data := c.templateData(report.FilterBySeverity(findings, c.SeverityThreshold), testResult)
+	if err := report.RenderTemplate(&b, c.FormatTemplate, data); err != nil {
+		return nil, err
+	}
+	return c.textOutput(ictx, b.String(), findings)
+}
+
+// writeTemplateFile renders findings through --format-template to --template-file-output, if requested.
+func (c *Command) writeTemplateFile(findings []report.Finding, testResult testapi.TestResult) error {
+	if c.FormatTemplate == nil || c.TemplateOutputPath == "" {
+		return nil
+	}
+
+	var b bytes.Buffer
+	if err := report.RenderTemplate(&b, c.FormatTemplate, c.templateData(findings, testResult)); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, templateFailureMsg)
+	}
+	if err := writeFileAtomic(c.TemplateOutputPath, b.Bytes()); err != nil {
+		return c.ErrorFactory.NewGeneralSecretsFailureError(err, templateFailureMsg)
+	}
+	c.Logger.Info().Str("path", c.TemplateOutputPath).Msg("Template output written")
+	return nil
+}
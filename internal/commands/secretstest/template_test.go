@@ -0,0 +1,104 @@
+package secretstest
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	gafclientmocks "github.com/snyk/go-application-framework/pkg/apiclients/mocks"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+func TestLoadFormatTemplate(t *testing.T) {
+	tmpl, err := loadFormatTemplate(setupMockConfig(map[string]any{}))
+	require.NoError(t, err)
+	assert.Nil(t, tmpl)
+
+	tmpl, err = loadFormatTemplate(setupMockConfig(map[string]any{FlagFormatTemplate: "csv"}))
+	require.NoError(t, err)
+	assert.NotNil(t, tmpl)
+
+	path := filepath.Join(t.TempDir(), "broken.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{ range .Findings }}"), 0o600))
+	_, err = loadFormatTemplate(setupMockConfig(map[string]any{FlagFormatTemplate: path}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid --format-template")
+}
+
+func TestCommand_PrepareOutputForRun_Template(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	_, _, cmd := setupTestCommand(t, ctrl)
+	cmd.TextReport = true
+	cmd.RepoURL = "https://github.com/acme/app.git"
+	cmd.Branch = "main"
+
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"{{ .SCM.RepoURL }}@{{ .SCM.Branch }}: {{ .Summary.Total }}\n"+
+			"{{ range .Findings }}{{ range .Locations }}{{ .FilePath }}:{{ .FromLine }}\n{{ end }}{{ end }}"), 0o600))
+	tmpl, err := loadFormatTemplate(setupMockConfig(map[string]any{FlagFormatTemplate: path}))
+	require.NoError(t, err)
+	cmd.FormatTemplate = tmpl
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil).AnyTimes()
+	testResult.EXPECT().GetMetadataValue(ReportURL).Return(nil)
+
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{})
+	ctx := cmdctx.WithIctx(t.Context(), mockIctx)
+
+	output, err := cmd.prepareTemplateOutput(ctx, testResult)
+	require.NoError(t, err)
+	require.Len(t, output, 2)
+	text, ok := output[0].GetPayload().([]byte)
+	require.True(t, ok)
+	assert.Equal(t, "https://github.com/acme/app.git@main: 1\ngcp-credentials.json:7\ngcp-credentials.json:13\n", string(text))
+	assert.Equal(t, content_type.TEST_SUMMARY, output[1].GetContentType())
+}
+
+func TestCommand_WriteTemplateFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	_, _, cmd := setupTestCommand(t, ctrl)
+
+	tmpl, err := loadFormatTemplate(setupMockConfig(map[string]any{FlagFormatTemplate: "csv"}))
+	require.NoError(t, err)
+	cmd.FormatTemplate = tmpl
+	cmd.TemplateOutputPath = filepath.Join(t.TempDir(), "secrets.csv")
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	testResult.EXPECT().GetMetadataValue(ReportURL).Return(nil)
+	require.NoError(t, cmd.writeReports(t.Context(), testResult))
+
+	content, err := os.ReadFile(cmd.TemplateOutputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "severity,rule_id,title,file,line,column,ignored,key\n")
+	assert.Contains(t, string(content), "critical,private-key,Private Key,gcp-credentials.json,7,20,false,")
+}
+
+func TestCommand_WriteTemplateFile_Unwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	_, _, cmd := setupTestCommand(t, ctrl)
+
+	tmpl, err := loadFormatTemplate(setupMockConfig(map[string]any{FlagFormatTemplate: "markdown"}))
+	require.NoError(t, err)
+	cmd.FormatTemplate = tmpl
+	// a directory cannot be replaced by the output
+	cmd.TemplateOutputPath = t.TempDir()
+
+	testResult := gafclientmocks.NewMockTestResult(ctrl)
+	testResult.EXPECT().Findings(gomock.Any()).Return(loadTestFindings(t), true, nil)
+	testResult.EXPECT().GetMetadataValue(ReportURL).Return(nil)
+
+	err = cmd.writeReports(t.Context(), testResult)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, templateFailureMsg)
+}
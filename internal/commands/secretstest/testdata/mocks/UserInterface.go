@@ -45,6 +45,30 @@ func (mr *MockUserInterfaceMockRecorder) Clear() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockUserInterface)(nil).Clear))
 }
 
+// SetCounts mocks base method.
+func (m *MockUserInterface) SetCounts(counts string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetCounts", counts)
+}
+
+// SetCounts indicates an expected call of SetCounts.
+func (mr *MockUserInterfaceMockRecorder) SetCounts(counts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCounts", reflect.TypeOf((*MockUserInterface)(nil).SetCounts), counts)
+}
+
+// SetProgress mocks base method.
+func (m *MockUserInterface) SetProgress(progress float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetProgress", progress)
+}
+
+// SetProgress indicates an expected call of SetProgress.
+func (mr *MockUserInterfaceMockRecorder) SetProgress(progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProgress", reflect.TypeOf((*MockUserInterface)(nil).SetProgress), progress)
+}
+
 // SetTitle mocks base method.
 func (m *MockUserInterface) SetTitle(title string) {
 	m.ctrl.T.Helper()
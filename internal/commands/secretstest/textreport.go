@@ -0,0 +1,157 @@
+package secretstest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/content_type"
+	"github.com/snyk/go-application-framework/pkg/local_workflows/json_schemas"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+// testSummaryType is the type of the test summary the CLI derives its exit code from.
+const testSummaryType = "secrets"
+
+// isTextReport reports whether the results are rendered as the text report: when neither JSON nor SARIF is printed,
+// and JSON is not written to a file. The CLI renders JSON written to a file from the test result data, which it
+// would then also print instead of the text report. SARIF written to a file is rendered here, see isSARIFReport.
+func isTextReport(config configuration.Configuration) bool {
+	for _, flag := range []string{FlagJSON, FlagSARIF} {
+		if config.GetBool(flag) {
+			return false
+		}
+	}
+	return config.GetString(FlagJSONFileOutput) == ""
+}
+
+// useColor reports whether the text report is colored, which the NO_COLOR environment variable turns off.
+func useColor() bool {
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// prepareOutputForRun returns the text report of testResult or the output of --format-template, or else the test
+// result data for the CLI to render. The SARIF output, if rendered here, replaces the test result data.
+func (c *Command) prepareOutputForRun(ctx context.Context, testResult testapi.TestResult, ownerSummaries []ownerSummary) ([]workflow.Data, error) {
+	output, err := c.prepareOutput(ctx, testResult)
+	if err != nil {
+		return nil, err
+	}
+	if c.SARIFReport {
+		if output, err = c.prepareSARIFOutput(ctx, testResult); err != nil {
+			return nil, err
+		}
+	}
+	if !c.TextReport {
+		return output, nil
+	}
+
+	var text []workflow.Data
+	if c.FormatTemplate != nil && c.TemplateOutputPath == "" {
+		text, err = c.prepareTemplateOutput(ctx, testResult)
+	} else {
+		text, err = c.prepareTextReport(ctx, testResult, ownerSummaries)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.SARIFReport {
+		return append(text, output...), nil
+	}
+	return text, nil
+}
+
+// prepareTextReport renders the findings of testResult as the text report, along with the test summary the CLI
+// derives its exit code from. The report only shows the findings at the severity threshold or above.
+func (c *Command) prepareTextReport(ctx context.Context, testResult testapi.TestResult, ownerSummaries []ownerSummary) ([]workflow.Data, error) {
+	ictx := cmdctx.Ictx(ctx)
+	if ictx == nil {
+		return nil, fmt.Errorf("invocation context is nil")
+	}
+
+	findings, err := c.reportFindings(ctx, testResult)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := report.TextOptions{
+		SourceDir:    c.sourceDir,
+		ContextLines: report.DefaultContextLines,
+		Color:        c.Color,
+	}
+	if reportURL, ok := testResult.GetMetadataValue(ReportURL).(string); ok {
+		opts.ReportURL = reportURL
+	}
+
+	var b strings.Builder
+	if err := report.RenderText(&b, report.FilterBySeverity(findings, c.SeverityThreshold), opts); err != nil {
+		return nil, err
+	}
+	if c.GroupBy == GroupByOwner {
+		b.WriteString(renderOwnerSummary(ownerSummaries))
+	}
+	if partial, ok := testResult.(*partialTestResult); ok {
+		fmt.Fprintf(&b, "\nWarning: %s\n", partialResultsDetail(len(partial.findings)))
+	}
+
+	return c.textOutput(ictx, b.String(), findings)
+}
+
+// textOutput returns text for the CLI to print, along with the test summary of findings the CLI derives its exit
+// code from.
+func (c *Command) textOutput(ictx workflow.InvocationContext, text string, findings []report.Finding) ([]workflow.Data, error) {
+	id := ictx.GetWorkflowIdentifier()
+	summary, err := c.testSummaryData(id, findings)
+	if err != nil {
+		return nil, err
+	}
+	return []workflow.Data{workflow.NewData(workflow.NewTypeIdentifier(id, "report"), contentTypeText, []byte(text)), summary}, nil
+}
+
+// testSummaryData returns the test summary of findings the CLI derives its exit code from.
+func (c *Command) testSummaryData(id workflow.Identifier, findings []report.Finding) (workflow.Data, error) {
+	summary, err := buildTestSummary(findings, c.sourceDir, int(c.progress.filesUploaded.Load()))
+	if err != nil {
+		return nil, err
+	}
+	return workflow.NewData(workflow.NewTypeIdentifier(id, "test-summary"), content_type.TEST_SUMMARY, summary), nil
+}
+
+// buildTestSummary counts the findings by severity for the CLI to derive its exit code and apply the severity threshold.
+func buildTestSummary(findings []report.Finding, path string, files int) ([]byte, error) {
+	summary := json_schemas.NewTestSummary(testSummaryType, path)
+	summary.Artifacts = files
+
+	results := map[string]*json_schemas.TestSummaryResult{}
+	for i := range findings {
+		result, ok := results[findings[i].Severity]
+		if !ok {
+			result = &json_schemas.TestSummaryResult{Severity: findings[i].Severity}
+			results[findings[i].Severity] = result
+		}
+		result.Total++
+		if findings[i].Ignored {
+			result.Ignored++
+		} else {
+			result.Open++
+		}
+	}
+	for _, severity := range report.Severities {
+		if result, ok := results[severity]; ok {
+			summary.Results = append(summary.Results, *result)
+		}
+	}
+
+	payload, err := json.Marshal(summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal test summary: %w", err)
+	}
+	return payload, nil
+}
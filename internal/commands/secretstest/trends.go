@@ -0,0 +1,162 @@
+package secretstest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-secrets/internal/history"
+)
+
+// FlagWeeks is the flag of the secrets trends command setting the number of weeks summarized.
+const FlagWeeks = "weeks"
+
+// defaultTrendsWeeks is the number of weeks summarized by default.
+const defaultTrendsWeeks = 12
+
+// TrendsWorkflowID is the unique identifier for the secrets trends workflow.
+var TrendsWorkflowID = workflow.NewWorkflowIdentifier("secrets.trends")
+
+// trends is the JSON output of the secrets trends command, see history.Trends.
+type trends struct {
+	From                       time.Time      `json:"from"`
+	To                         time.Time      `json:"to"`
+	Runs                       int            `json:"runs"`
+	Targets                    int            `json:"targets"`
+	Weeks                      []trendsWeek   `json:"weeks"`
+	Open                       int            `json:"open"`
+	OpenBySeverity             map[string]int `json:"openBySeverity"`
+	Introduced                 int            `json:"introduced"`
+	Fixed                      int            `json:"fixed"`
+	MeanTimeToRemediateSeconds int64          `json:"meanTimeToRemediateSeconds"`
+	TopRules                   []trendsRule   `json:"topRules"`
+}
+
+type trendsWeek struct {
+	Start string `json:"start"`
+	New   int    `json:"new"`
+	Fixed int    `json:"fixed"`
+}
+
+type trendsRule struct {
+	RuleID     string `json:"ruleId"`
+	Open       int    `json:"open"`
+	Introduced int    `json:"introduced"`
+	Fixed      int    `json:"fixed"`
+}
+
+// GetSecretsTrendsFlagSet returns the flag set for the secrets trends command.
+func GetSecretsTrendsFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet("snyk-cli-extension-secrets-trends", pflag.ExitOnError)
+
+	flagSet.String(FlagHistoryDir, "", "The directory of the scan history written by snyk secrets test --history-dir.")
+	flagSet.Int(FlagWeeks, defaultTrendsWeeks, "The number of weeks summarized, up to the current one. Use 0 to summarize the whole history.")
+	flagSet.Bool(FlagJSON, false, "Print the trends on the console as a JSON data structure.")
+
+	return flagSet
+}
+
+// TrendsWorkflow is the entry point for the secrets trends workflow.
+// It summarizes the local scan history written with --history-dir: the findings introduced and fixed every week,
+// the mean time to remediate and the rules with the most findings. It does not need the API.
+func TrendsWorkflow(
+	ictx workflow.InvocationContext,
+	_ []workflow.Data,
+) ([]workflow.Data, error) {
+	config := ictx.GetConfiguration()
+	logger := ictx.GetEnhancedLogger()
+	errorFactory := NewErrorFactory(logger)
+
+	dir, weeks, err := validateTrendsInput(config, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	records, skipped, err := history.Load(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errorFactory.NewValidationFailureError(
+			fmt.Sprintf("No scan history found in %s. Record one with snyk secrets test --%s.", dir, FlagHistoryDir))
+	}
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, "failed to read the scan history")
+	}
+	if skipped > 0 {
+		logger.Warn().Int("skipped", skipped).Str("path", dir).Msg("Skipped unreadable scan history records")
+	}
+
+	summary := history.Summarize(records, history.TrendsOptions{Weeks: weeks})
+	logger.Info().Int("runs", summary.Runs).Int("weeks", len(summary.Weeks)).Msg("Summarized scan history")
+
+	output, err := prepareTrendsOutput(ictx, config, &summary)
+	if err != nil {
+		return nil, errorFactory.NewPrepareOutputError(err)
+	}
+	return output, nil
+}
+
+func validateTrendsInput(config configuration.Configuration, errorFactory *ErrorFactory) (string, int, error) {
+	dir := config.GetString(FlagHistoryDir)
+	if strings.TrimSpace(dir) == "" {
+		errMsg := fmt.Sprintf("Pass the directory of the scan history to summarize with --%s.", FlagHistoryDir)
+		return "", 0, errorFactory.NewValidationFailureError(errMsg)
+	}
+
+	weeks := defaultTrendsWeeks
+	if config.IsSet(FlagWeeks) {
+		weeks = config.GetInt(FlagWeeks)
+	}
+	if weeks < 0 {
+		errMsg := fmt.Sprintf("Invalid --%s: %d, it must be 0 or more", FlagWeeks, weeks)
+		return "", 0, errorFactory.NewValidationFailureError(errMsg)
+	}
+	return dir, weeks, nil
+}
+
+// prepareTrendsOutput renders the trends as text, or as JSON with --json.
+func prepareTrendsOutput(ictx workflow.InvocationContext, config configuration.Configuration, t *history.Trends) ([]workflow.Data, error) {
+	id := ictx.GetWorkflowIdentifier()
+
+	if config.GetBool(FlagJSON) {
+		payload, err := json.MarshalIndent(newTrends(t), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal trends: %w", err)
+		}
+		return []workflow.Data{workflow.NewData(workflow.NewTypeIdentifier(id, "trends"), contentTypeJSON, payload)}, nil
+	}
+
+	var b strings.Builder
+	if err := history.RenderText(&b, t); err != nil {
+		return nil, err
+	}
+	return []workflow.Data{workflow.NewData(workflow.NewTypeIdentifier(id, "report"), contentTypeText, []byte(b.String()))}, nil
+}
+
+func newTrends(t *history.Trends) trends {
+	result := trends{
+		From:                       t.From,
+		To:                         t.To,
+		Runs:                       t.Runs,
+		Targets:                    t.Targets,
+		Weeks:                      make([]trendsWeek, 0, len(t.Weeks)),
+		Open:                       t.Open,
+		OpenBySeverity:             t.OpenBySeverity,
+		Introduced:                 t.Introduced,
+		Fixed:                      t.Fixed,
+		MeanTimeToRemediateSeconds: int64(t.MeanTimeToRemediate / time.Second),
+		TopRules:                   make([]trendsRule, 0, len(t.TopRules)),
+	}
+	for _, w := range t.Weeks {
+		result.Weeks = append(result.Weeks, trendsWeek{Start: w.Start.Format(time.DateOnly), New: w.New, Fixed: w.Fixed})
+	}
+	for _, r := range t.TopRules {
+		result.TopRules = append(result.TopRules, trendsRule(r))
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package secretstest
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/mocks"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/history"
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+func setupTrendsIctx(ctrl *gomock.Controller, config configuration.Configuration) workflow.InvocationContext {
+	logger := zerolog.Nop()
+	mockIctx := mocks.NewMockInvocationContext(ctrl)
+	mockIctx.EXPECT().GetConfiguration().Return(config).AnyTimes()
+	mockIctx.EXPECT().GetEnhancedLogger().Return(&logger).AnyTimes()
+	mockIctx.EXPECT().GetWorkflowIdentifier().Return(&url.URL{}).AnyTimes()
+	return mockIctx
+}
+
+// writeHistory records a run a week ago with a finding fixed by a run today.
+func writeHistory(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	now := time.Now()
+	findings := []report.Finding{{Fingerprint: "fp1", RuleID: "aws-access-token", Severity: report.SeverityHigh}}
+	require.NoError(t, history.Append(dir, history.NewRecord(now.Add(-7*24*time.Hour), findings)))
+	require.NoError(t, history.Append(dir, history.NewRecord(now, nil)))
+	return dir
+}
+
+func TestTrendsWorkflow_JSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	config := configuration.New()
+	config.Set(FlagHistoryDir, writeHistory(t))
+	config.Set(FlagWeeks, 4)
+	config.Set(FlagJSON, true)
+
+	output, err := TrendsWorkflow(setupTrendsIctx(ctrl, config), nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	assert.Equal(t, contentTypeJSON, output[0].GetContentType())
+
+	var result trends
+	payload, ok := output[0].GetPayload().([]byte)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal(payload, &result))
+	assert.Equal(t, 2, result.Runs)
+	assert.Len(t, result.Weeks, 4)
+	assert.Equal(t, 1, result.Fixed)
+	assert.Equal(t, int64(7*24*60*60), result.MeanTimeToRemediateSeconds)
+	assert.Equal(t, []trendsRule{{RuleID: "aws-access-token", Fixed: 1}}, result.TopRules)
+}
+
+func TestTrendsWorkflow_Text(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	config := configuration.New()
+	config.Set(FlagHistoryDir, writeHistory(t))
+
+	output, err := TrendsWorkflow(setupTrendsIctx(ctrl, config), nil)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	assert.Equal(t, contentTypeText, output[0].GetContentType())
+
+	payload, ok := output[0].GetPayload().([]byte)
+	require.True(t, ok)
+	assert.Contains(t, string(payload), "2 runs of 1 target")
+	assert.Contains(t, string(payload), "Mean time to remediate: 7d 0h")
+}
+
+func TestTrendsWorkflow_InvalidInput(t *testing.T) {
+	testCases := []struct {
+		config      map[string]any
+		expectedErr string
+		desc        string
+	}{
+		{
+			config:      map[string]any{},
+			expectedErr: "Pass the directory of the scan history to summarize with --history-dir.",
+			desc:        "no history dir",
+		},
+		{
+			config:      map[string]any{FlagHistoryDir: ".", FlagWeeks: -1},
+			expectedErr: "Invalid --weeks: -1, it must be 0 or more",
+			desc:        "negative weeks",
+		},
+		{
+			config:      map[string]any{FlagHistoryDir: "testdata"},
+			expectedErr: "No scan history found in testdata.",
+			desc:        "no history",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			_, err := TrendsWorkflow(setupTrendsIctx(ctrl, setupMockConfig(tc.config)), nil)
+			catalogErr := requireCatalogError(t, err)
+			assert.Contains(t, catalogErr.Detail, tc.expectedErr)
+		})
+	}
+}
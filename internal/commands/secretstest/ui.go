@@ -1,6 +1,9 @@
 package secretstest
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/rs/zerolog"
 	"github.com/snyk/go-application-framework/pkg/ui"
 	"github.com/snyk/go-application-framework/pkg/workflow"
@@ -10,33 +13,76 @@ import (
 const (
 	TitleScanning          = "Scanning..."
 	TitleValidating        = "Validating configuration..."
+	TitleFiltering         = "Filtering files..."
+	TitleUploading         = "Uploading files..."
 	TitleRetrievingResults = "Retrieving results..."
+	TitleBlaming           = "Resolving git blame..."
 )
 
 // UserInterface abstracts progress-bar operations for the secrets workflow.
 type UserInterface interface {
+	// SetTitle starts a phase, showing an indeterminate progress bar without counts.
 	SetTitle(title string)
+	// SetProgress shows the completed fraction of the phase, between 0 and 1.
+	SetProgress(progress float64)
+	// SetCounts shows what the phase processed so far next to its title.
+	SetCounts(counts string)
 	Clear()
 }
 
 // CLIUserInterface implements UserInterface using the GAF progress bar.
+// It is safe for concurrent use, as progress is reported in the background.
 type CLIUserInterface struct {
 	logger      *zerolog.Logger
+	ui          ui.UserInterface
 	progressbar ui.ProgressBar
+
+	mu       sync.Mutex
+	title    string
+	progress float64
 }
 
 // NewUI creates a CLIUserInterface from the given invocation context.
 func NewUI(ictx workflow.InvocationContext) *CLIUserInterface {
+	userInterface := ictx.GetUserInterface()
 	return &CLIUserInterface{
 		logger:      ictx.GetEnhancedLogger(),
-		progressbar: ictx.GetUserInterface().NewProgressBar(),
+		ui:          userInterface,
+		progressbar: userInterface.NewProgressBar(),
 	}
 }
 
 // SetTitle updates the progress bar title and triggers a render.
 func (u *CLIUserInterface) SetTitle(title string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.title = title
+	u.progress = ui.InfiniteProgress
 	u.progressbar.SetTitle(title)
-	err := u.progressbar.UpdateProgress(ui.InfiniteProgress)
+	u.render()
+}
+
+// SetProgress replaces the indeterminate progress of the phase with the given fraction.
+func (u *CLIUserInterface) SetProgress(progress float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.progress = min(max(progress, 0), 1)
+	u.render()
+}
+
+// SetCounts appends counts to the title of the phase.
+func (u *CLIUserInterface) SetCounts(counts string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.progressbar.SetTitle(u.title + " " + counts)
+	u.render()
+}
+
+func (u *CLIUserInterface) render() {
+	err := u.progressbar.UpdateProgress(u.progress)
 	if err != nil {
 		u.logger.Err(err).Msg("Failed to update progress")
 		return
@@ -45,9 +91,21 @@ func (u *CLIUserInterface) SetTitle(title string) {
 
 // Clear removes the progress bar from the terminal.
 func (u *CLIUserInterface) Clear() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
 	err := u.progressbar.Clear()
 	if err != nil {
 		u.logger.Err(err).Msg("Failed to clear progress")
 		return
 	}
 }
+
+// Warn shows msg on stderr, where it does not mix with the output of the workflow.
+func (u *CLIUserInterface) Warn(msg string) {
+	u.logger.Warn().Msg(msg)
+	err := u.ui.OutputError(errors.New(msg))
+	if err != nil {
+		u.logger.Err(err).Msg("Failed to show warning")
+	}
+}
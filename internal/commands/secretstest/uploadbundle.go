@@ -0,0 +1,229 @@
+package secretstest
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/snyk/go-application-framework/pkg/configuration"
+	"github.com/snyk/go-application-framework/pkg/workflow"
+	"github.com/spf13/pflag"
+
+	"github.com/snyk/cli-extension-secrets/internal/bundle"
+	"github.com/snyk/cli-extension-secrets/internal/clients/retry"
+	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
+)
+
+// FlagBundleVerifyKey is the flag of the secrets upload-bundle command requiring the bundle to be signed.
+const FlagBundleVerifyKey = "bundle-verify-key"
+
+// UnverifiedBundleMsg warns that a bundle is tested without checking who wrote it.
+const UnverifiedBundleMsg = "WARNING: the bundle %s is not verified, as --%s is not set. " +
+	"Its files are tested whoever wrote them; sign bundles and verify them to make sure they were not tampered with."
+
+// UploadBundleWorkflowID is the unique identifier for the secrets upload-bundle workflow.
+var UploadBundleWorkflowID = workflow.NewWorkflowIdentifier("secrets.upload-bundle")
+
+// GetSecretsUploadBundleFlagSet returns the flag set for the secrets upload-bundle command.
+func GetSecretsUploadBundleFlagSet() *pflag.FlagSet {
+	flagSet := pflag.NewFlagSet("snyk-cli-extension-secrets-upload-bundle", pflag.ExitOnError)
+
+	flagSet.String(FlagBundleVerifyKey, "",
+		"Only test the bundle if it is signed with the private key matching the ed25519 public key in the specified PEM file.")
+	flagSet.Bool(FlagJSON, false, "Print results on the console as a JSON data structure.")
+	flagSet.Bool(FlagSARIF, false, "Return results in SARIF format.")
+	flagSet.String(FlagJSONFileOutput, "",
+		"Save test output as a JSON data structure directly to the specified file, regardless of whether or not you use the --json option.")
+	flagSet.String(FlagSARIFFileOutput, "",
+		"Save test output in SARIF format directly to the specified file, regardless of whether or not you use the --sarif option.")
+	addJUnitFileOutputFlag(flagSet)
+	addJUnitIncludePassingFlag(flagSet)
+	addGitLabFileOutputFlag(flagSet)
+	addHTMLFileOutputFlag(flagSet)
+	addAnnotationsFlag(flagSet)
+	addTemplateFlags(flagSet)
+	flagSet.Bool(FlagNoWait, false, "Start the test without waiting for results. Retrieve them later with snyk secrets result --test-id.")
+	flagSet.Int(FlagMaxRetries, retry.DefaultMaxRetries, "The number of times a request failing transiently is retried.")
+	addAllowPartialFlag(flagSet)
+	addPollFlags(flagSet)
+	flagSet.String(FlagTimeout, "", "Abort the test if it does not complete within the given duration, e.g. 600 (seconds) or 10m.")
+	addUploadTimeoutFlag(flagSet)
+	addScanTimeoutFlags(flagSet)
+	flagSet.Bool(FlagProgressJSON, false, "Write the progress of the test to stderr as newline-delimited JSON events.")
+	addUploadManifestFlag(flagSet)
+	addHistoryDirFlag(flagSet)
+
+	return flagSet
+}
+
+// UploadBundleWorkflow is the entry point for the secrets upload-bundle workflow.
+// It tests the files of a bundle written with --bundle-output like the secrets test run that wrote it.
+func UploadBundleWorkflow(
+	ictx workflow.InvocationContext,
+	_ []workflow.Data,
+) ([]workflow.Data, error) {
+	config := ictx.GetConfiguration()
+	logger := ictx.GetEnhancedLogger()
+	errorFactory := NewErrorFactory(logger)
+
+	u := NewUI(ictx)
+	u.SetTitle(TitleValidating)
+	defer u.Clear()
+
+	orgID, bundlePath, verifyKey, err := validateUploadBundleInput(config, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout, err := parseDurationFlag(config, FlagTimeout)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	phaseTimeouts, err := parsePhaseTimeouts(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	formatTemplate, err := loadFormatTemplate(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	ctx, cancel := newWorkflowContext(timeout)
+	defer cancel()
+	ctx = cmdctx.WithIctx(ctx, ictx)
+	ctx = withRunInstrumentation(ctx, ictx)
+
+	orgID, err = resolveOrgID(ctx, ictx, orgID, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "snyk-secrets-bundle-")
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, "failed to create bundle directory")
+	}
+	defer os.RemoveAll(dir)
+
+	manifest, bc, err := extractBundle(bundlePath, dir, verifyKey, u, errorFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	reportConfig := bc.ReportConfig
+	if reportConfig.Report {
+		reportConfig.ProjectPageURL = buildProjectPageURL(config)
+	}
+
+	args := &CommandArgs{
+		InvocationContext:   ictx,
+		UserInterface:       u,
+		OrgID:               orgID,
+		RootFolderID:        bc.RootFolderID,
+		RepoURL:             bc.RepoURL,
+		Branch:              bc.Branch,
+		CommitRef:           bc.CommitRef,
+		GetClients:          NewWorkflowClients,
+		ErrorFactory:        errorFactory,
+		SeverityThreshold:   bc.SeverityThreshold,
+		ReportConfig:        reportConfig,
+		NoWait:              config.GetBool(FlagNoWait),
+		AllowPartial:        config.GetBool(FlagAllowPartial),
+		TextReport:          isTextReport(config),
+		SARIFReport:         isSARIFReport(config),
+		Color:               useColor(),
+		Timeouts:            phaseTimeouts,
+		UploadManifestPath:  config.GetString(FlagUploadManifest),
+		JUnitOutputPath:     config.GetString(FlagJUnitFileOutput),
+		JUnitIncludePassing: config.GetBool(FlagJUnitIncludePassing),
+		GitLabOutputPath:    config.GetString(FlagGitLabFileOutput),
+		HTMLOutputPath:      config.GetString(FlagHTMLFileOutput),
+		FormatTemplate:      formatTemplate,
+		TemplateOutputPath:  config.GetString(FlagTemplateFileOutput),
+		HistoryDir:          config.GetString(FlagHistoryDir),
+	}
+	if config.GetBool(FlagProgressJSON) {
+		args.ProgressStream = os.Stderr
+	}
+	applyAnnotationArgs(args, config)
+	c, err := NewCommand(args)
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnableToInitializeMsg)
+	}
+
+	logger.Info().Str("bundle", bundlePath).Int(LogFieldCount, len(manifest.Files)).Msg("Testing scan bundle...")
+	output, err := c.RunBundle(ctx, dir, manifest.Files)
+	if err != nil {
+		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnexpectedErrorMsg)
+	}
+
+	return output, nil
+}
+
+func validateUploadBundleInput(
+	config configuration.Configuration,
+	errorFactory *ErrorFactory,
+) (orgID, bundlePath string, verifyKey ed25519.PublicKey, err error) {
+	if !config.GetBool(FeatureFlagIsSecretsEnabled) {
+		return "", "", nil, errorFactory.NewFeatureNotEnabledError(FeatureNotEnabledMsg)
+	}
+
+	orgID = config.GetString(configuration.ORGANIZATION)
+	if orgID == "" {
+		return "", "", nil, errorFactory.NewValidationFailureError(NoOrgProvidedMsg)
+	}
+	if e := validateOrg(orgID); e != nil {
+		return "", "", nil, errorFactory.NewValidationFailureError(e.Error())
+	}
+
+	inputPaths := config.GetStringSlice(configuration.INPUT_DIRECTORY)
+	if len(inputPaths) != 1 {
+		return "", "", nil, errorFactory.NewValidationFailureError("Pass the path of exactly one bundle written with --bundle-output.")
+	}
+	bundlePath = inputPaths[0]
+
+	if keyPath := config.GetString(FlagBundleVerifyKey); keyPath != "" {
+		verifyKey, err = bundle.LoadVerifyKey(keyPath)
+		if err != nil {
+			return "", "", nil, errorFactory.NewValidationFailureError(fmt.Sprintf("Invalid --%s: %s", FlagBundleVerifyKey, err))
+		}
+	}
+
+	for _, validate := range []func(configuration.Configuration) error{validateFileOutputPaths, validateReportFlags, validateAnnotations, validateTemplateFlags, validateMaxRetries, validatePollIntervals} {
+		if e := validate(config); e != nil {
+			return "", "", nil, errorFactory.NewValidationFailureError(e.Error())
+		}
+	}
+
+	return orgID, bundlePath, verifyKey, nil
+}
+
+// extractBundle verifies and extracts the bundle into dir, returning its manifest and the context of the test.
+// Without verifyKey, anyone able to replace the bundle chooses what is tested, which u is warned about.
+func extractBundle(
+	bundlePath, dir string,
+	verifyKey ed25519.PublicKey,
+	u *CLIUserInterface,
+	errorFactory *ErrorFactory,
+) (*bundle.Manifest, *bundleContext, error) {
+	if verifyKey == nil {
+		u.Warn(fmt.Sprintf(UnverifiedBundleMsg, bundlePath, FlagBundleVerifyKey))
+	}
+
+	manifest, err := bundle.Extract(bundlePath, dir, verifyKey)
+	switch {
+	case errors.Is(err, bundle.ErrNotSigned), errors.Is(err, bundle.ErrInvalidSignature):
+		return nil, nil, errorFactory.NewValidationFailureError(
+			fmt.Sprintf("The bundle %s cannot be tested: %s. It must be signed with the key matching --%s.", bundlePath, err, FlagBundleVerifyKey))
+	case errors.Is(err, bundle.ErrInvalidBundle), errors.Is(err, bundle.ErrHashMismatch), errors.Is(err, bundle.ErrUnsupportedFormat):
+		return nil, nil, errorFactory.NewValidationFailureError(fmt.Sprintf("The bundle %s cannot be tested: %s.", bundlePath, err))
+	case err != nil:
+		return nil, nil, errorFactory.NewGeneralSecretsFailureError(err, "failed to extract bundle")
+	}
+
+	var bc bundleContext
+	if err := json.Unmarshal(manifest.Context, &bc); err != nil {
+		return nil, nil, errorFactory.NewValidationFailureError(fmt.Sprintf("The bundle %s cannot be tested: malformed context: %s.", bundlePath, err))
+	}
+	return manifest, &bc, nil
+}
@@ -0,0 +1,224 @@
+package secretstest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UploadManifestVersion is the version of the upload manifest format.
+const UploadManifestVersion = 1
+
+// Outcomes of the upload recorded in an upload manifest.
+const (
+	UploadStatusCompleted = "completed"
+	UploadStatusFailed    = "failed"
+)
+
+// UploadManifest is the audit record of the files a run transmitted, written with --upload-manifest.
+type UploadManifest struct {
+	Version    int       `json:"version"`
+	OrgID      string    `json:"orgId"`
+	APIURL     string    `json:"apiUrl"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	RevisionID string    `json:"revisionId,omitempty"`
+	// Files are the files handed to the upload, in the order they were handed over.
+	Files []UploadedFile `json:"files"`
+	// FilterDecisions are the decisions of the filters on every file walked, sorted by path.
+	// Files excluded by ignore files or --exclude are not walked.
+	FilterDecisions []FilterDecision `json:"filterDecisions"`
+}
+
+// UploadedFile is a file handed to the upload.
+type UploadedFile struct {
+	// Path is the path of the file as handed to the upload.
+	Path string `json:"path"`
+	// UploadPath is the slash-separated path of the file in the upload revision.
+	UploadPath string `json:"uploadPath"`
+	Size       int64  `json:"size"`
+	SHA256     string `json:"sha256,omitempty"`
+	// Error is why the file could not be hashed, if it could not.
+	Error string `json:"error,omitempty"`
+}
+
+// FilterDecision is the decision of the filters on a file.
+type FilterDecision struct {
+	Path      string `json:"path"`
+	Kept      bool   `json:"kept"`
+	DroppedBy string `json:"droppedBy,omitempty"`
+}
+
+// uploadManifestRecorder records the upload of a run for its upload manifest. It is safe for concurrent use.
+type uploadManifestRecorder struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	manifest UploadManifest
+	// decided indexes the filter decisions by path.
+	decided map[string]int
+}
+
+func newUploadManifestRecorder(path, orgID, apiURL string) *uploadManifestRecorder {
+	r := &uploadManifestRecorder{path: path, now: time.Now, decided: map[string]int{}}
+	r.manifest = UploadManifest{
+		Version:         UploadManifestVersion,
+		OrgID:           orgID,
+		APIURL:          apiURL,
+		StartedAt:       r.now().UTC(),
+		Files:           []UploadedFile{},
+		FilterDecisions: []FilterDecision{},
+	}
+	return r
+}
+
+// FileDecided records the decision of the filter pipeline on a file.
+func (r *uploadManifestRecorder) FileDecided(path, droppedBy string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decided[path] = len(r.manifest.FilterDecisions)
+	r.manifest.FilterDecisions = append(r.manifest.FilterDecisions, FilterDecision{Path: path, Kept: droppedBy == "", DroppedBy: droppedBy})
+}
+
+// fileDropped records that a file the filter pipeline kept was dropped by droppedBy before the upload.
+func (r *uploadManifestRecorder) fileDropped(path, droppedBy string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	decision := FilterDecision{Path: path, DroppedBy: droppedBy}
+	if i, ok := r.decided[path]; ok {
+		r.manifest.FilterDecisions[i] = decision
+		return
+	}
+	r.decided[path] = len(r.manifest.FilterDecisions)
+	r.manifest.FilterDecisions = append(r.manifest.FilterDecisions, decision)
+}
+
+// record forwards paths, relative to dir, recording the size and hash of every file handed over.
+func (r *uploadManifestRecorder) record(ctx context.Context, paths <-chan string, dir string) <-chan string {
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		for path := range paths {
+			file := hashFile(path)
+			if rel, err := filepath.Rel(dir, path); err == nil {
+				file.UploadPath = filepath.ToSlash(rel)
+			}
+			select {
+			case out <- path:
+				r.mu.Lock()
+				r.manifest.Files = append(r.manifest.Files, file)
+				r.mu.Unlock()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func hashFile(path string) UploadedFile {
+	file := UploadedFile{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	file.Size, err = io.Copy(hash, f)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file
+}
+
+// write writes the manifest with the outcome of the upload, atomically replacing the file at its path.
+func (r *uploadManifestRecorder) write(revisionID string, uploadErr error) error {
+	r.mu.Lock()
+	manifest := r.manifest
+	manifest.Files = append([]UploadedFile{}, r.manifest.Files...)
+	manifest.FilterDecisions = append([]FilterDecision{}, r.manifest.FilterDecisions...)
+	r.mu.Unlock()
+
+	manifest.FinishedAt = r.now().UTC()
+	manifest.RevisionID = revisionID
+	manifest.Status = UploadStatusCompleted
+	if uploadErr != nil {
+		manifest.Status = UploadStatusFailed
+		manifest.Error = uploadErr.Error()
+	}
+	sort.Slice(manifest.FilterDecisions, func(i, j int) bool {
+		return manifest.FilterDecisions[i].Path < manifest.FilterDecisions[j].Path
+	})
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upload manifest: %w", err)
+	}
+	return writeFileAtomic(r.path, data)
+}
+
+// writeFileAtomic replaces the file at path with data, so that the file is never left partially written.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory of %s: %w", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeUploadManifest writes the upload manifest, if requested, with the outcome of the upload.
+// A manifest that cannot be written fails the run, unless the upload failed already.
+func (c *Command) writeUploadManifest(revisionID string, uploadErr error) error {
+	if c.uploadManifest == nil {
+		return nil
+	}
+
+	err := c.uploadManifest.write(revisionID, uploadErr)
+	if err == nil {
+		c.Logger.Info().Str("path", c.uploadManifest.path).Msg("Upload manifest written")
+		return nil
+	}
+	if uploadErr != nil {
+		c.Logger.Error().Err(err).Msg("failed to write upload manifest")
+		return nil
+	}
+	return c.ErrorFactory.NewGeneralSecretsFailureError(err, "failed to write upload manifest")
+}
@@ -0,0 +1,180 @@
+package secretstest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/snyk/go-application-framework/pkg/apiclients/fileupload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockupload "github.com/snyk/cli-extension-secrets/internal/clients/upload/mocks"
+)
+
+func readUploadManifest(t *testing.T, path string) UploadManifest {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var manifest UploadManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return manifest
+}
+
+func TestCommand_FilterAndUploadFiles_WritesUploadManifest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	manifestPath := filepath.Join(t.TempDir(), "audit", "upload.json")
+	cmd.uploadManifest = newUploadManifestRecorder(manifestPath, cmd.OrgID, "https://api.snyk.io")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"src/config.env": "TOKEN=abc", "empty.txt": ""})
+
+	revisionID := uuid.New()
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), dir).DoAndReturn(
+		func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			uploaded := 0
+			for range paths {
+				uploaded++
+			}
+			return fileupload.UploadResult{RevisionID: revisionID, UploadedFilesCount: uploaded}, nil
+		},
+	)
+
+	uploadRevision, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, revisionID.String(), uploadRevision)
+
+	manifest := readUploadManifest(t, manifestPath)
+	assert.Equal(t, UploadManifestVersion, manifest.Version)
+	assert.Equal(t, cmd.OrgID, manifest.OrgID)
+	assert.Equal(t, "https://api.snyk.io", manifest.APIURL)
+	assert.Equal(t, UploadStatusCompleted, manifest.Status)
+	assert.Equal(t, revisionID.String(), manifest.RevisionID)
+	assert.False(t, manifest.FinishedAt.Before(manifest.StartedAt))
+
+	hash := sha256.Sum256([]byte("TOKEN=abc"))
+	assert.Equal(t, []UploadedFile{{
+		Path:       filepath.Join(dir, "src", "config.env"),
+		UploadPath: "src/config.env",
+		Size:       int64(len("TOKEN=abc")),
+		SHA256:     hex.EncodeToString(hash[:]),
+	}}, manifest.Files)
+	assert.Equal(t, []FilterDecision{
+		{Path: filepath.Join(dir, "empty.txt"), DroppedBy: "file-size"},
+		{Path: filepath.Join(dir, "src", "config.env"), Kept: true},
+	}, manifest.FilterDecisions)
+}
+
+func TestCommand_FilterAndUploadFiles_WritesUploadManifestOnFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	manifestPath := filepath.Join(t.TempDir(), "upload.json")
+	cmd.uploadManifest = newUploadManifestRecorder(manifestPath, cmd.OrgID, "https://api.snyk.io")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), dir).DoAndReturn(
+		func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			<-paths
+			return fileupload.UploadResult{}, errors.New("connection reset")
+		},
+	)
+
+	_, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	requireCatalogError(t, err)
+
+	manifest := readUploadManifest(t, manifestPath)
+	assert.Equal(t, UploadStatusFailed, manifest.Status)
+	assert.Equal(t, "connection reset", manifest.Error)
+	assert.Empty(t, manifest.RevisionID)
+	assert.Len(t, manifest.Files, 1, "only the file handed to the upload is recorded")
+}
+
+func TestCommand_WriteUploadManifest_Unwritable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	// a directory cannot be replaced by the manifest
+	cmd.uploadManifest = newUploadManifestRecorder(t.TempDir(), cmd.OrgID, "https://api.snyk.io")
+
+	err := cmd.writeUploadManifest(uuid.New().String(), nil)
+	catalogErr := requireCatalogError(t, err)
+	assert.Contains(t, catalogErr.Detail, "failed to write upload manifest")
+
+	assert.NoError(t, cmd.writeUploadManifest("", errors.New("upload failed")), "the upload error takes precedence")
+}
+
+func TestCommand_FilterAndUploadFiles_MinimalUpload_WritesUploadManifest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients, _, cmd := setupTestCommand(t, ctrl)
+	cmd.MinimalUpload = true
+	manifestPath := filepath.Join(t.TempDir(), "upload.json")
+	cmd.uploadManifest = newUploadManifestRecorder(manifestPath, cmd.OrgID, "https://api.snyk.io")
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"config.env": "DB_PASSWORD=hunter2\n", "main.go": "package main\n"})
+
+	mockUploadClient := mockClients.FileUpload.(*mockupload.MockClient)
+	mockUploadClient.EXPECT().CreateRevisionFromChan(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, paths <-chan string, _ string) (fileupload.UploadResult, error) {
+			uploaded := 0
+			for range paths {
+				uploaded++
+			}
+			return fileupload.UploadResult{RevisionID: uuid.New(), UploadedFilesCount: uploaded}, nil
+		},
+	)
+
+	_, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	require.NoError(t, err)
+
+	manifest := readUploadManifest(t, manifestPath)
+	require.Len(t, manifest.Files, 1)
+	assert.Equal(t, "config.env", manifest.Files[0].UploadPath)
+	assert.Equal(t, []FilterDecision{
+		{Path: filepath.Join(dir, "config.env"), Kept: true},
+		{Path: filepath.Join(dir, "main.go"), DroppedBy: minimalUploadFilterName},
+	}, manifest.FilterDecisions, "files without candidate lines are dropped by the minimal upload")
+}
+
+func TestCommand_FilterAndUploadFiles_MinimalUpload_WritesUploadManifestOnStagingFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cmd := setupTestCommand(t, ctrl)
+	cmd.MinimalUpload = true
+	manifestPath := filepath.Join(t.TempDir(), "upload.json")
+	cmd.uploadManifest = newUploadManifestRecorder(manifestPath, cmd.OrgID, "https://api.snyk.io")
+	// the staging directory cannot be created
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "missing"))
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"config.env": "DB_PASSWORD=hunter2\n"})
+
+	_, err := cmd.filterAndUploadFiles(t.Context(), dir)
+	requireCatalogError(t, err)
+
+	manifest := readUploadManifest(t, manifestPath)
+	assert.Equal(t, UploadStatusFailed, manifest.Status)
+	assert.Contains(t, manifest.Error, "failed to create minimal upload directory")
+	assert.Empty(t, manifest.Files)
+}
@@ -7,12 +7,17 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
+	"github.com/google/uuid"
 	cli_errors "github.com/snyk/error-catalog-golang-public/cli"
 	"github.com/snyk/go-application-framework/pkg/configuration"
 
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
+	"github.com/snyk/cli-extension-secrets/internal/report"
 	ff "github.com/snyk/cli-extension-secrets/pkg/filefilter"
 )
 
@@ -21,6 +26,7 @@ const (
 	MaxTargetNameLength      = 256
 	MaxTargetReferenceLength = 256
 	MaxFileOutputPathLength  = 4096
+	MaxOrgSlugLength         = 256
 
 	optionCritical = "critical"
 	optionHigh     = "high"
@@ -42,6 +48,12 @@ var (
 	validOptionsProjectLifecycle = map[string]struct{}{
 		optionProduction: {}, "development": {}, "sandbox": {},
 	}
+	validOptionsGroupBy = map[string]struct{}{
+		GroupByOwner: {},
+	}
+	validOptionsAnnotations = map[string]struct{}{
+		report.AnnotationsGitHub: {}, report.AnnotationsAzure: {}, AnnotationsAuto: {},
+	}
 )
 
 type flagWithOptions struct {
@@ -55,13 +67,19 @@ func validateAndPrepareInput(
 	config configuration.Configuration,
 	errorFactory *ErrorFactory,
 ) (orgID, inputPath string, err error) {
-	if !config.GetBool(FeatureFlagIsSecretsEnabled) {
-		return "", "", errorFactory.NewFeatureNotEnabledError(FeatureNotEnabledMsg)
-	}
+	// a bundle is written without contacting the API, the host uploading it checks the feature flag and organization
+	if !isBundleExport(config) {
+		if !config.GetBool(FeatureFlagIsSecretsEnabled) {
+			return "", "", errorFactory.NewFeatureNotEnabledError(FeatureNotEnabledMsg)
+		}
 
-	orgID = config.GetString(configuration.ORGANIZATION)
-	if orgID == "" {
-		return "", "", errorFactory.NewValidationFailureError(NoOrgProvidedMsg)
+		orgID = config.GetString(configuration.ORGANIZATION)
+		if orgID == "" {
+			return "", "", errorFactory.NewValidationFailureError(NoOrgProvidedMsg)
+		}
+		if e := validateOrg(orgID); e != nil {
+			return "", "", errorFactory.NewValidationFailureError(e.Error())
+		}
 	}
 
 	if e := validateFlagsConfig(config); e != nil {
@@ -102,13 +120,149 @@ func validateFlagsConfig(config configuration.Configuration) error {
 		}
 	}
 
+	if config.IsSet(FlagGroupBy) {
+		flag := flagWithOptions{
+			name:         FlagGroupBy,
+			allowEmpty:   false,
+			singleChoice: true,
+			validOptions: validOptionsGroupBy,
+		}
+		if err := validateFlagValue(config, flag); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAnnotations(config); err != nil {
+		return err
+	}
+
+	if err := validateTemplateFlags(config); err != nil {
+		return err
+	}
+
 	if err := validateRemoteRepoURL(config); err != nil {
 		return err
 	}
 
+	if err := validateRemoteName(config); err != nil {
+		return err
+	}
+
+	if err := validateMaxRetries(config); err != nil {
+		return err
+	}
+
+	if err := validatePollIntervals(config); err != nil {
+		return err
+	}
+
+	if err := validateBundleFlags(config); err != nil {
+		return err
+	}
+
+	if err := validateReportFlags(config); err != nil {
+		return err
+	}
+
 	return validateFileOutputPaths(config)
 }
 
+// isBundleExport reports whether the files are to be written to a bundle instead of being tested.
+func isBundleExport(config configuration.Configuration) bool {
+	return config.GetString(FlagBundleOutput) != ""
+}
+
+// validateBundleFlags checks --bundle-output and the flags it cannot be combined with, as they need the
+// repository or the API on the host uploading the bundle.
+func validateBundleFlags(config configuration.Configuration) error {
+	if !isBundleExport(config) {
+		if config.GetString(FlagBundleSigningKey) != "" {
+			errMsg := fmt.Sprintf("Invalid use of --%s, it can only be used in combination with the --%s option", FlagBundleSigningKey, FlagBundleOutput)
+			return errors.New(errMsg)
+		}
+		return nil
+	}
+
+	conflictingFlags := []struct {
+		name string
+		set  bool
+	}{
+		{name: FlagBlame, set: config.GetBool(FlagBlame)},
+		{name: FlagGroupBy, set: config.GetString(FlagGroupBy) != ""},
+		{name: FlagNoWait, set: config.GetBool(FlagNoWait)},
+		{name: FlagUploadManifest, set: config.GetString(FlagUploadManifest) != ""},
+		{name: FlagMinimalUpload, set: config.GetBool(FlagMinimalUpload)},
+		{name: FlagJUnitFileOutput, set: config.GetString(FlagJUnitFileOutput) != ""},
+		{name: FlagGitLabFileOutput, set: config.GetString(FlagGitLabFileOutput) != ""},
+		{name: FlagHTMLFileOutput, set: config.GetString(FlagHTMLFileOutput) != ""},
+		{name: FlagAnnotations, set: config.GetString(FlagAnnotations) != ""},
+		{name: FlagFormatTemplate, set: config.GetString(FlagFormatTemplate) != ""},
+		{name: FlagHistoryDir, set: config.GetString(FlagHistoryDir) != ""},
+	}
+	for _, flag := range conflictingFlags {
+		if flag.set {
+			errMsg := fmt.Sprintf("Invalid use of --%s, it cannot be combined with the --%s option", flag.name, FlagBundleOutput)
+			return errors.New(errMsg)
+		}
+	}
+
+	return validateOutputPath(config.GetString(FlagBundleOutput), FlagBundleOutput)
+}
+
+// validateReportFlags checks --junit-file-output, --gitlab-file-output, --html-file-output, --annotations,
+// --format-template, --history-dir and the flags depending on them. With --no-wait the results are not awaited, the
+// reports are written by the secrets result command instead.
+func validateReportFlags(config configuration.Configuration) error {
+	if config.GetBool(FlagJUnitIncludePassing) && config.GetString(FlagJUnitFileOutput) == "" {
+		errMsg := fmt.Sprintf("Invalid use of --%s, it can only be used in combination with the --%s option", FlagJUnitIncludePassing, FlagJUnitFileOutput)
+		return errors.New(errMsg)
+	}
+
+	if !config.GetBool(FlagNoWait) {
+		return nil
+	}
+	for _, flagName := range []string{FlagJUnitFileOutput, FlagGitLabFileOutput, FlagHTMLFileOutput, FlagAnnotations, FlagFormatTemplate, FlagHistoryDir} {
+		if config.GetString(flagName) != "" {
+			errMsg := fmt.Sprintf("Invalid use of --%s, it cannot be combined with the --%s option", flagName, FlagNoWait)
+			return errors.New(errMsg)
+		}
+	}
+	return nil
+}
+
+// validateTemplateFlags checks --format-template and --template-file-output. A template printed replaces the text
+// report, so it cannot be combined with the JSON and SARIF output.
+func validateTemplateFlags(config configuration.Configuration) error {
+	if config.GetString(FlagFormatTemplate) == "" {
+		if config.GetString(FlagTemplateFileOutput) != "" {
+			errMsg := fmt.Sprintf("Invalid use of --%s, it can only be used in combination with the --%s option", FlagTemplateFileOutput, FlagFormatTemplate)
+			return errors.New(errMsg)
+		}
+		return nil
+	}
+	if config.GetString(FlagTemplateFileOutput) != "" {
+		return nil
+	}
+	if !isTextReport(config) {
+		errMsg := fmt.Sprintf("Invalid use of --%s, it cannot be combined with JSON or SARIF output unless --%s is set", FlagFormatTemplate, FlagTemplateFileOutput)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+func validateAnnotations(config configuration.Configuration) error {
+	if !config.IsSet(FlagAnnotations) {
+		return nil
+	}
+	flag := flagWithOptions{
+		name:         FlagAnnotations,
+		allowEmpty:   false,
+		singleChoice: true,
+		validOptions: validOptionsAnnotations,
+	}
+	return validateFlagValue(config, flag)
+}
+
 /*
 This validates config flags that only work together with --report:
 --project-environment, --project-business-criticality, --project-lifecycle
@@ -286,6 +440,103 @@ func validateRemoteRepoURL(config configuration.Configuration) error {
 	return errors.New(errMsg)
 }
 
+func validateRemoteName(config configuration.Configuration) error {
+	if !config.IsSet(FlagRemoteName) {
+		return nil
+	}
+
+	remoteName := config.GetString(FlagRemoteName)
+	if strings.TrimSpace(remoteName) == "" || strings.ContainsAny(remoteName, " \t\n") {
+		errMsg := fmt.Sprintf("Invalid --%s: must be the name of a configured git remote, e.g. origin", FlagRemoteName)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func validateMaxRetries(config configuration.Configuration) error {
+	if !config.IsSet(FlagMaxRetries) {
+		return nil
+	}
+
+	if config.GetInt(FlagMaxRetries) < 0 {
+		errMsg := fmt.Sprintf("Invalid --%s: must be zero or a positive number", FlagMaxRetries)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+func validatePollIntervals(config configuration.Configuration) error {
+	interval, err := parseDurationFlag(config, FlagPollInterval)
+	if err != nil {
+		return err
+	}
+
+	maxInterval, err := parseDurationFlag(config, FlagMaxPollInterval)
+	if err != nil {
+		return err
+	}
+
+	// the test client does not poll more often
+	if interval > 0 && interval < testshim.MinPollInterval {
+		errMsg := fmt.Sprintf("Invalid --%s: must be at least %s", FlagPollInterval, testshim.MinPollInterval)
+		return errors.New(errMsg)
+	}
+	if maxInterval > 0 && maxInterval < testshim.MinPollInterval {
+		errMsg := fmt.Sprintf("Invalid --%s: must be at least %s", FlagMaxPollInterval, testshim.MinPollInterval)
+		return errors.New(errMsg)
+	}
+
+	if interval > 0 && maxInterval > 0 && interval > maxInterval {
+		errMsg := fmt.Sprintf("Invalid --%s: must not exceed --%s", FlagPollInterval, FlagMaxPollInterval)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
+// parseDurationFlag reads a flag given as a duration such as 10m, or as a number of seconds.
+// It returns zero if the flag is not set.
+func parseDurationFlag(config configuration.Configuration, flagName string) (time.Duration, error) {
+	if !config.IsSet(flagName) {
+		return 0, nil
+	}
+
+	raw := strings.TrimSpace(config.GetString(flagName))
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		seconds, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			errMsg := fmt.Sprintf("Invalid --%s: %q is not a duration, e.g. 30 (seconds) or 10m", flagName, raw)
+			return 0, errors.New(errMsg)
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+	if d <= 0 {
+		errMsg := fmt.Sprintf("Invalid --%s: must be greater than zero", flagName)
+		return 0, errors.New(errMsg)
+	}
+
+	return d, nil
+}
+
+var orgSlugRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// validateOrg checks that org is either an organization ID or an organization slug, to be resolved to its ID.
+func validateOrg(org string) error {
+	if _, err := uuid.Parse(org); err == nil {
+		return nil
+	}
+
+	if utf8.RuneCountInString(org) > MaxOrgSlugLength || !orgSlugRegexp.MatchString(org) {
+		errMsg := fmt.Sprintf("Invalid --%s: %q is neither an organization ID nor an organization slug", configuration.ORGANIZATION, org)
+		return errors.New(errMsg)
+	}
+
+	return nil
+}
+
 var scpURLRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._-]*@[a-zA-Z0-9.-]+:[^/].*$`)
 
 func isValidGitURL(rawURL string) bool {
@@ -345,7 +596,7 @@ func validateStringLengthLimits(config configuration.Configuration) error {
 }
 
 func validateFileOutputPaths(config configuration.Configuration) error {
-	outputFlags := []string{FlagJSONFileOutput, FlagSARIFFileOutput}
+	outputFlags := []string{FlagJSONFileOutput, FlagSARIFFileOutput, FlagJUnitFileOutput, FlagGitLabFileOutput, FlagHTMLFileOutput, FlagTemplateFileOutput, FlagUploadManifest, FlagHistoryDir}
 
 	for _, flagName := range outputFlags {
 		if !config.IsSet(flagName) {
@@ -357,17 +608,7 @@ func validateFileOutputPaths(config configuration.Configuration) error {
 			continue
 		}
 
-		if utf8.RuneCountInString(rawPath) > MaxFileOutputPathLength {
-			errMsg := fmt.Sprintf("Invalid --%s: path exceeds maximum length of %d characters", flagName, MaxFileOutputPathLength)
-			return errors.New(errMsg)
-		}
-
-		if strings.ContainsAny(rawPath, "\x00") {
-			errMsg := fmt.Sprintf("Invalid --%s: path contains invalid characters", flagName)
-			return errors.New(errMsg)
-		}
-
-		if err := validateOutputPathSafety(rawPath, flagName); err != nil {
+		if err := validateOutputPath(rawPath, flagName); err != nil {
 			return err
 		}
 	}
@@ -375,6 +616,20 @@ func validateFileOutputPaths(config configuration.Configuration) error {
 	return nil
 }
 
+func validateOutputPath(rawPath, flagName string) error {
+	if utf8.RuneCountInString(rawPath) > MaxFileOutputPathLength {
+		errMsg := fmt.Sprintf("Invalid --%s: path exceeds maximum length of %d characters", flagName, MaxFileOutputPathLength)
+		return errors.New(errMsg)
+	}
+
+	if strings.ContainsAny(rawPath, "\x00") {
+		errMsg := fmt.Sprintf("Invalid --%s: path contains invalid characters", flagName)
+		return errors.New(errMsg)
+	}
+
+	return validateOutputPathSafety(rawPath, flagName)
+}
+
 func validateOutputPathSafety(rawPath, flagName string) error {
 	absPath, err := filepath.Abs(rawPath)
 	if err != nil {
@@ -3,9 +3,13 @@ package secretstest
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/clients/testshim"
 )
 
 func TestValidateFlagValue(t *testing.T) {
@@ -340,6 +344,20 @@ func TestValidateFlagsConfig(t *testing.T) {
 			hasErr: false,
 			desc:   "valid --target-reference with --report",
 		},
+		{
+			in: map[string]any{
+				FlagGroupBy: GroupByOwner,
+			},
+			hasErr: false,
+			desc:   "valid --group-by",
+		},
+		{
+			in: map[string]any{
+				FlagGroupBy: "severity",
+			},
+			hasErr: true,
+			desc:   "invalid --group-by",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -609,6 +627,191 @@ func TestValidateRemoteRepoURL(t *testing.T) {
 	}
 }
 
+func TestValidateRemoteName(t *testing.T) {
+	testCases := []struct {
+		in     map[string]any
+		hasErr bool
+		desc   string
+	}{
+		{
+			in:     map[string]any{},
+			hasErr: false,
+			desc:   "no --remote-name set, no validation needed",
+		},
+		{
+			in: map[string]any{
+				FlagRemoteName: "upstream",
+			},
+			hasErr: false,
+			desc:   "valid remote name",
+		},
+		{
+			in: map[string]any{
+				FlagRemoteName: "",
+			},
+			hasErr: true,
+			desc:   "empty remote name",
+		},
+		{
+			in: map[string]any{
+				FlagRemoteName: "my remote",
+			},
+			hasErr: true,
+			desc:   "remote name with whitespace",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := setupMockConfig(tc.in)
+
+			err := validateRemoteName(config)
+			if tc.hasErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateMaxRetries(t *testing.T) {
+	testCases := []struct {
+		in     map[string]any
+		hasErr bool
+		desc   string
+	}{
+		{
+			in:     map[string]any{},
+			hasErr: false,
+			desc:   "no --max-retries set, no validation needed",
+		},
+		{
+			in: map[string]any{
+				FlagMaxRetries: 0,
+			},
+			hasErr: false,
+			desc:   "retries disabled",
+		},
+		{
+			in: map[string]any{
+				FlagMaxRetries: 5,
+			},
+			hasErr: false,
+			desc:   "positive number of retries",
+		},
+		{
+			in: map[string]any{
+				FlagMaxRetries: -1,
+			},
+			hasErr: true,
+			desc:   "negative number of retries",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := setupMockConfig(tc.in)
+
+			err := validateMaxRetries(config)
+			if tc.hasErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateOrg(t *testing.T) {
+	testCases := []struct {
+		org    string
+		hasErr bool
+		desc   string
+	}{
+		{org: "5ad5c3a8-2c58-4c29-a0c1-7e0e8f9b0c1d", hasErr: false, desc: "org ID"},
+		{org: "my-org", hasErr: false, desc: "org slug"},
+		{org: "my_org.2", hasErr: false, desc: "org slug with underscore and dot"},
+		{org: "-my-org", hasErr: true, desc: "slug starting with a dash"},
+		{org: "my org", hasErr: true, desc: "slug with a space"},
+		{org: "my/org", hasErr: true, desc: "slug with a slash"},
+		{org: strings.Repeat("a", MaxOrgSlugLength+1), hasErr: true, desc: "slug too long"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateOrg(tc.org)
+			if tc.hasErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestParseDurationFlag(t *testing.T) {
+	testCases := []struct {
+		in       map[string]any
+		expected time.Duration
+		hasErr   bool
+		desc     string
+	}{
+		{in: map[string]any{}, expected: 0, desc: "not set"},
+		{in: map[string]any{FlagTimeout: "10m"}, expected: 10 * time.Minute, desc: "duration"},
+		{in: map[string]any{FlagTimeout: "90"}, expected: 90 * time.Second, desc: "seconds"},
+		{in: map[string]any{FlagTimeout: "0"}, hasErr: true, desc: "zero"},
+		{in: map[string]any{FlagTimeout: "-5s"}, hasErr: true, desc: "negative"},
+		{in: map[string]any{FlagTimeout: "soon"}, hasErr: true, desc: "not a duration"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			timeout, err := parseDurationFlag(setupMockConfig(tc.in), FlagTimeout)
+			if tc.hasErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, timeout)
+		})
+	}
+}
+
+func TestValidatePollIntervals(t *testing.T) {
+	testCases := []struct {
+		in     map[string]any
+		hasErr bool
+		desc   string
+	}{
+		{in: map[string]any{}, hasErr: false, desc: "no overrides"},
+		{in: map[string]any{FlagPollInterval: "1s", FlagMaxPollInterval: "1m"}, hasErr: false, desc: "both set"},
+		{in: map[string]any{FlagPollInterval: "5s", FlagMaxPollInterval: "5s"}, hasErr: false, desc: "fixed interval"},
+		{in: map[string]any{FlagPollInterval: "1m", FlagMaxPollInterval: "1s"}, hasErr: true, desc: "interval above max"},
+		{in: map[string]any{FlagMaxPollInterval: "often"}, hasErr: true, desc: "invalid max"},
+		{in: map[string]any{FlagPollInterval: "500ms"}, hasErr: true, desc: "interval below the minimum"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validatePollIntervals(setupMockConfig(tc.in))
+			if tc.hasErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestBuildPollConfig(t *testing.T) {
+	assert.Equal(t, testshim.DefaultPollConfig(), buildPollConfig(setupMockConfig(map[string]any{})))
+	assert.Equal(t,
+		testshim.PollConfig{Interval: time.Minute, MaxInterval: time.Minute},
+		buildPollConfig(setupMockConfig(map[string]any{FlagPollInterval: "1m"})),
+		"the cap is raised to the interval")
+}
+
 func TestValidateStringLengthLimits(t *testing.T) {
 	longString := strings.Repeat("a", MaxTargetNameLength+1)
 
@@ -714,6 +917,41 @@ func TestValidateFileOutputPaths(t *testing.T) {
 			hasErr: false,
 			desc:   "valid --sarif-file-output with .json extension",
 		},
+		{
+			in: map[string]any{
+				FlagJUnitFileOutput: "/tmp/junit.xml",
+			},
+			hasErr: false,
+			desc:   "valid --junit-file-output path",
+		},
+		{
+			in: map[string]any{
+				FlagJUnitFileOutput: "junit\x00.xml",
+			},
+			hasErr: true,
+			desc:   "invalid --junit-file-output with null byte",
+		},
+		{
+			in: map[string]any{
+				FlagGitLabFileOutput: "gl-secret-detection-report.json",
+			},
+			hasErr: false,
+			desc:   "valid --gitlab-file-output path",
+		},
+		{
+			in: map[string]any{
+				FlagHTMLFileOutput: "secrets\x00.html",
+			},
+			hasErr: true,
+			desc:   "invalid --html-file-output with null byte",
+		},
+		{
+			in: map[string]any{
+				FlagTemplateFileOutput: "secrets\x00.csv",
+			},
+			hasErr: true,
+			desc:   "invalid --template-file-output with null byte",
+		},
 		{
 			in: map[string]any{
 				FlagJSONFileOutput: longPath,
@@ -757,3 +995,107 @@ func TestValidateFileOutputPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReportFlags(t *testing.T) {
+	testCases := []struct {
+		config      map[string]any
+		expectedErr string
+		desc        string
+	}{
+		{config: map[string]any{}, desc: "no report file flags"},
+		{config: map[string]any{FlagGitLabFileOutput: "gl-secret-detection-report.json"}, desc: "gitlab"},
+		{config: map[string]any{FlagJUnitFileOutput: "junit.xml", FlagJUnitIncludePassing: true}, desc: "include passing"},
+		{
+			config:      map[string]any{FlagJUnitIncludePassing: true},
+			expectedErr: "Invalid use of --junit-include-passing, it can only be used in combination with the --junit-file-output option",
+			desc:        "include passing without report",
+		},
+		{
+			config:      map[string]any{FlagJUnitFileOutput: "junit.xml", FlagNoWait: true},
+			expectedErr: "Invalid use of --junit-file-output, it cannot be combined with the --no-wait option",
+			desc:        "junit with no wait",
+		},
+		{
+			config:      map[string]any{FlagGitLabFileOutput: "gl-secret-detection-report.json", FlagNoWait: true},
+			expectedErr: "Invalid use of --gitlab-file-output, it cannot be combined with the --no-wait option",
+			desc:        "gitlab with no wait",
+		},
+		{
+			config:      map[string]any{FlagHTMLFileOutput: "secrets.html", FlagNoWait: true},
+			expectedErr: "Invalid use of --html-file-output, it cannot be combined with the --no-wait option",
+			desc:        "html with no wait",
+		},
+		{
+			config:      map[string]any{FlagAnnotations: "github", FlagNoWait: true},
+			expectedErr: "Invalid use of --annotations, it cannot be combined with the --no-wait option",
+			desc:        "annotations with no wait",
+		},
+		{
+			config:      map[string]any{FlagFormatTemplate: "csv", FlagNoWait: true},
+			expectedErr: "Invalid use of --format-template, it cannot be combined with the --no-wait option",
+			desc:        "format template with no wait",
+		},
+		{
+			config:      map[string]any{FlagHistoryDir: ".snyk-history", FlagNoWait: true},
+			expectedErr: "Invalid use of --history-dir, it cannot be combined with the --no-wait option",
+			desc:        "history with no wait",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateReportFlags(setupMockConfig(tc.config))
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateTemplateFlags(t *testing.T) {
+	testCases := []struct {
+		config      map[string]any
+		expectedErr string
+		desc        string
+	}{
+		{config: map[string]any{}, desc: "no template"},
+		{config: map[string]any{FlagFormatTemplate: "csv"}, desc: "template printed"},
+		{config: map[string]any{FlagFormatTemplate: "csv", FlagJSON: true, FlagTemplateFileOutput: "secrets.csv"}, desc: "template written with json"},
+		{
+			config:      map[string]any{FlagTemplateFileOutput: "secrets.csv"},
+			expectedErr: "Invalid use of --template-file-output, it can only be used in combination with the --format-template option",
+			desc:        "file output without template",
+		},
+		{
+			config:      map[string]any{FlagFormatTemplate: "csv", FlagJSONFileOutput: "secrets.json"},
+			expectedErr: "Invalid use of --format-template, it cannot be combined with JSON or SARIF output unless --template-file-output is set",
+			desc:        "template printed with json file",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := validateTemplateFlags(setupMockConfig(tc.config))
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	for _, value := range []string{"github", "azure", "auto"} {
+		assert.NoError(t, validateAnnotations(setupMockConfig(map[string]any{FlagAnnotations: value})), value)
+	}
+	assert.NoError(t, validateAnnotations(setupMockConfig(map[string]any{})))
+
+	err := validateAnnotations(setupMockConfig(map[string]any{FlagAnnotations: "jenkins"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid --annotations: jenkins.")
+
+	assert.Error(t, validateAnnotations(setupMockConfig(map[string]any{FlagAnnotations: "github,azure"})))
+}
@@ -2,12 +2,14 @@ package secretstest
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"net/url"
+	"os"
 	"path/filepath"
 
+	"github.com/snyk/cli-extension-secrets/internal/bundle"
 	"github.com/snyk/cli-extension-secrets/internal/commands/cmdctx"
-	"github.com/snyk/cli-extension-secrets/internal/instrumentation"
 
 	"github.com/snyk/go-application-framework/pkg/configuration"
 	"github.com/snyk/go-application-framework/pkg/local_workflows/config_utils"
@@ -23,7 +25,8 @@ const (
 // WorkflowID is the unique identifier for the secrets test workflow.
 var WorkflowID = workflow.NewWorkflowIdentifier("secrets.test")
 
-// RegisterWorkflows registers the secrets test workflow and its feature flag with the engine.
+// RegisterWorkflows registers the secrets test, result, upload-bundle, compare and trends workflows and their feature flag
+// with the engine.
 func RegisterWorkflows(e workflow.Engine) error {
 	flagSet := GetSecretsTestFlagSet()
 
@@ -33,6 +36,26 @@ func RegisterWorkflows(e workflow.Engine) error {
 		return fmt.Errorf("error while registering %s workflow: %w", WorkflowID, err)
 	}
 
+	resultConfig := workflow.ConfigurationOptionsFromFlagset(GetSecretsResultFlagSet())
+	if _, err := e.Register(ResultWorkflowID, resultConfig, ResultWorkflow); err != nil {
+		return fmt.Errorf("error while registering %s workflow: %w", ResultWorkflowID, err)
+	}
+
+	uploadBundleConfig := workflow.ConfigurationOptionsFromFlagset(GetSecretsUploadBundleFlagSet())
+	if _, err := e.Register(UploadBundleWorkflowID, uploadBundleConfig, UploadBundleWorkflow); err != nil {
+		return fmt.Errorf("error while registering %s workflow: %w", UploadBundleWorkflowID, err)
+	}
+
+	compareConfig := workflow.ConfigurationOptionsFromFlagset(GetSecretsCompareFlagSet())
+	if _, err := e.Register(CompareWorkflowID, compareConfig, CompareWorkflow); err != nil {
+		return fmt.Errorf("error while registering %s workflow: %w", CompareWorkflowID, err)
+	}
+
+	trendsConfig := workflow.ConfigurationOptionsFromFlagset(GetSecretsTrendsFlagSet())
+	if _, err := e.Register(TrendsWorkflowID, trendsConfig, TrendsWorkflow); err != nil {
+		return fmt.Errorf("error while registering %s workflow: %w", TrendsWorkflowID, err)
+	}
+
 	config_utils.AddFeatureFlagToConfig(e, FeatureFlagIsSecretsEnabled, "isSecretsEnabled")
 
 	return nil
@@ -43,10 +66,6 @@ func SecretsWorkflow(
 	ictx workflow.InvocationContext,
 	_ []workflow.Data,
 ) ([]workflow.Data, error) {
-	ctx := context.Background()
-	ctx = cmdctx.WithIctx(ctx, ictx)
-	ctx = cmdctx.WithInstrumentation(ctx, instrumentation.NewGAFInstrumentation(ictx.GetAnalytics()))
-
 	config := ictx.GetConfiguration()
 	logger := ictx.GetEnhancedLogger()
 	errorFactory := NewErrorFactory(logger)
@@ -75,7 +94,8 @@ func SecretsWorkflow(
 	}
 
 	remoteRepoURLFlag := config.GetString(FlagRemoteRepoURL)
-	repoContext := resolveGitContext(inputPath, gitRootDir, remoteRepoURLFlag, logger)
+	remoteNameFlag := config.GetString(FlagRemoteName)
+	repoContext := resolveGitContext(inputPath, gitRootDir, remoteRepoURLFlag, remoteNameFlag, logger)
 
 	// parse excludes
 	excludeGlobs, err := parseExcludeFlag(config)
@@ -86,25 +106,81 @@ func SecretsWorkflow(
 	// parse --report config
 	reportConfig := buildReportConfig(config)
 
+	timeout, err := parseDurationFlag(config, FlagTimeout)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	phaseTimeouts, err := parsePhaseTimeouts(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+	formatTemplate, err := loadFormatTemplate(config)
+	if err != nil {
+		return nil, errorFactory.NewValidationFailureError(err.Error())
+	}
+
+	// cancel on Ctrl-C or a CI job timeout, so that the deferred cleanup still runs
+	ctx, cancel := newWorkflowContext(timeout)
+	defer cancel()
+	ctx = cmdctx.WithIctx(ctx, ictx)
+	ctx = withRunInstrumentation(ctx, ictx)
+
+	bundlePath := config.GetString(FlagBundleOutput)
+	getClients := newBundleClients
+	if bundlePath == "" {
+		getClients = NewWorkflowClients
+		orgID, err = resolveOrgID(ctx, ictx, orgID, errorFactory)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	args := &CommandArgs{
-		InvocationContext: ictx,
-		UserInterface:     u,
-		OrgID:             orgID,
-		RootFolderID:      repoContext.inputPathRelativeToGitRoot,
-		RepoURL:           repoContext.repoURL,
-		Branch:            repoContext.branch,
-		CommitRef:         repoContext.commitRef,
-		GetClients:        NewWorkflowClients,
-		Excludes:          excludeGlobs,
-		ErrorFactory:      errorFactory,
-		SeverityThreshold: config.GetString(FlagSeverityThreshold),
-		ReportConfig:      reportConfig,
+		InvocationContext:   ictx,
+		UserInterface:       u,
+		OrgID:               orgID,
+		RootFolderID:        repoContext.inputPathRelativeToGitRoot,
+		RepoURL:             repoContext.repoURL,
+		Branch:              repoContext.branch,
+		CommitRef:           repoContext.commitRef,
+		GetClients:          getClients,
+		Excludes:            excludeGlobs,
+		ErrorFactory:        errorFactory,
+		SeverityThreshold:   config.GetString(FlagSeverityThreshold),
+		ReportConfig:        reportConfig,
+		GitRootDir:          repoContext.gitRootDir,
+		Blame:               config.GetBool(FlagBlame),
+		GroupBy:             config.GetString(FlagGroupBy),
+		NoWait:              config.GetBool(FlagNoWait),
+		AllowPartial:        config.GetBool(FlagAllowPartial),
+		KeywordPrefilter:    config.GetBool(FlagKeywordPrefilter),
+		MinimalUpload:       config.GetBool(FlagMinimalUpload),
+		TextReport:          isTextReport(config),
+		SARIFReport:         isSARIFReport(config),
+		Color:               useColor(),
+		Timeouts:            phaseTimeouts,
+		UploadManifestPath:  config.GetString(FlagUploadManifest),
+		JUnitOutputPath:     config.GetString(FlagJUnitFileOutput),
+		JUnitIncludePassing: config.GetBool(FlagJUnitIncludePassing),
+		GitLabOutputPath:    config.GetString(FlagGitLabFileOutput),
+		HTMLOutputPath:      config.GetString(FlagHTMLFileOutput),
+		FormatTemplate:      formatTemplate,
+		TemplateOutputPath:  config.GetString(FlagTemplateFileOutput),
+		HistoryDir:          config.GetString(FlagHistoryDir),
+	}
+	if config.GetBool(FlagProgressJSON) {
+		args.ProgressStream = os.Stderr
 	}
+	applyAnnotationArgs(args, config)
 	c, err := NewCommand(args)
 	if err != nil {
 		return nil, errorFactory.NewGeneralSecretsFailureError(err, UnableToInitializeMsg)
 	}
 
+	if bundlePath != "" {
+		return exportBundle(ctx, c, config, inputPath, bundlePath)
+	}
+
 	logger.Info().Str(InputPathKey, inputPath).Msg("Running secrets workflow...")
 	output, err := c.RunWorkflow(ctx, inputPath)
 	if err != nil {
@@ -130,13 +206,43 @@ func buildReportConfig(config configuration.Configuration) ReportConfig {
 	rc.ProjectEnvironment = config.GetString(FlagProjectEnvironment)
 	rc.ProjectLifecycle = config.GetString(FlagProjectLifecycle)
 
+	// the project page URL of a bundle is resolved by the host uploading it, which can reach the API
+	if !isBundleExport(config) {
+		rc.ProjectPageURL = buildProjectPageURL(config)
+	}
+
+	return rc
+}
+
+func buildProjectPageURL(config configuration.Configuration) *string {
 	orgName := config.GetString(configuration.ORGANIZATION_SLUG)
 	web := config.GetString(configuration.WEB_APP_URL)
-	if orgName != "" && web != "" {
-		if projectPageURL, err := url.JoinPath(web, "org", orgName, "project"); err == nil {
-			rc.ProjectPageURL = &projectPageURL
+	if orgName == "" || web == "" {
+		return nil
+	}
+
+	projectPageURL, err := url.JoinPath(web, "org", orgName, "project")
+	if err != nil {
+		return nil
+	}
+	return &projectPageURL
+}
+
+func exportBundle(ctx context.Context, c *Command, config configuration.Configuration, inputPath, bundlePath string) ([]workflow.Data, error) {
+	var signingKey ed25519.PrivateKey
+	if keyPath := config.GetString(FlagBundleSigningKey); keyPath != "" {
+		key, err := bundle.LoadSigningKey(keyPath)
+		if err != nil {
+			return nil, c.ErrorFactory.NewValidationFailureError(fmt.Sprintf("Invalid --%s: %s", FlagBundleSigningKey, err))
 		}
+		signingKey = key
 	}
 
-	return rc
+	c.Logger.Info().Str(InputPathKey, inputPath).Str("bundle", bundlePath).Msg("Writing scan bundle...")
+	output, err := c.ExportBundle(ctx, inputPath, bundlePath, signingKey)
+	if err != nil {
+		return nil, c.ErrorFactory.NewGeneralSecretsFailureError(err, UnexpectedErrorMsg)
+	}
+
+	return output, nil
 }
@@ -209,6 +209,14 @@ func TestBuildReportConfig_ReportFalse_IgnoresAllFields(t *testing.T) {
 
 // setupMockIctx sets expectations on the mock invocation context when the workflow fails during the validation step.
 func setupMockIctx(ctrl *gomock.Controller, mockConfig configuration.Configuration) *mocks.MockInvocationContext {
+	mockIctx, _ := setupMockIctxWithProgressBar(ctrl, mockConfig)
+	return mockIctx
+}
+
+func setupMockIctxWithProgressBar(
+	ctrl *gomock.Controller,
+	mockConfig configuration.Configuration,
+) (*mocks.MockInvocationContext, *mocks.MockProgressBar) {
 	logger := zerolog.Nop()
 	mockIctx := mocks.NewMockInvocationContext(ctrl)
 	mockUserInterface := mocks.NewMockUserInterface(ctrl)
@@ -225,11 +233,16 @@ func setupMockIctx(ctrl *gomock.Controller, mockConfig configuration.Configurati
 	mockProgressBar.EXPECT().UpdateProgress(ui.InfiniteProgress)
 	mockProgressBar.EXPECT().Clear()
 
-	return mockIctx
+	return mockIctx, mockProgressBar
 }
 
+// setupMockIctxWithNetworkAccess sets expectations on the mock invocation context when the workflow gets to upload files.
 func setupMockIctxWithNetworkAccess(ctrl *gomock.Controller, mockConfig configuration.Configuration) *mocks.MockInvocationContext {
-	mockIctx := setupMockIctx(ctrl, mockConfig)
+	mockIctx, mockProgressBar := setupMockIctxWithProgressBar(ctrl, mockConfig)
+
+	// the progress of the filtering and upload phases
+	mockProgressBar.EXPECT().SetTitle(gomock.Any()).AnyTimes()
+	mockProgressBar.EXPECT().UpdateProgress(gomock.Any()).AnyTimes()
 
 	mockNetworkAccess := mocks.NewMockNetworkAccess(ctrl)
 	mockIctx.EXPECT().GetNetworkAccess().Return(mockNetworkAccess).AnyTimes()
@@ -0,0 +1,163 @@
+// Package history keeps a local record of the secrets tests run on a repository and summarizes how their findings
+// evolve over time.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const (
+	// FileName is the name of the file the records are appended to, in the history directory.
+	FileName = "secrets-history.jsonl"
+	// RecordVersion is the version of the records written. Records of a later version are skipped when loading.
+	RecordVersion = 1
+
+	// maxRecordSize bounds the size of a single record, which grows with the number of findings.
+	maxRecordSize = 64 << 20
+)
+
+// Record is the compact record of a test run kept in the history.
+type Record struct {
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	TestID    string    `json:"testId,omitempty"`
+	RepoURL   string    `json:"repoUrl,omitempty"`
+	Branch    string    `json:"branch,omitempty"`
+	Commit    string    `json:"commit,omitempty"`
+	// RootFolder is the tested directory, relative to the root of the repository.
+	RootFolder string `json:"rootFolder,omitempty"`
+	// Incomplete is set for runs that only retrieved part of the findings. They are not compared with other runs.
+	Incomplete bool `json:"incomplete,omitempty"`
+	Total      int  `json:"total"`
+	Ignored    int  `json:"ignored"`
+	// BySeverity and ByRule count the findings that are not ignored.
+	BySeverity map[string]int `json:"bySeverity"`
+	ByRule     map[string]int `json:"byRule"`
+	Findings   []Finding      `json:"findings"`
+	Stats      Stats          `json:"stats"`
+}
+
+// Finding is a finding of a recorded run, identified across runs by its fingerprint, or its key if it has none.
+type Finding struct {
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Key         string `json:"key,omitempty"`
+	RuleID      string `json:"ruleId,omitempty"`
+	Severity    string `json:"severity"`
+	Ignored     bool   `json:"ignored,omitempty"`
+}
+
+// Stats are the filtering and upload statistics of a recorded run.
+type Stats struct {
+	FilesWalked     int `json:"filesWalked"`
+	FilesKept       int `json:"filesKept"`
+	FilesUploaded   int `json:"filesUploaded"`
+	SizeFiltered    int `json:"sizeFiltered"`
+	KeywordFiltered int `json:"keywordFiltered"`
+	FilterMs        int `json:"filterMs"`
+	UploadMs        int `json:"uploadMs"`
+	AnalysisMs      int `json:"analysisMs"`
+	Retries         int `json:"retries"`
+}
+
+// id returns the identity of a finding across runs.
+func (f *Finding) id() string {
+	if f.Fingerprint != "" {
+		return f.Fingerprint
+	}
+	return "key:" + f.Key
+}
+
+// NewRecord returns the record of a run with findings, counting them by severity and rule.
+func NewRecord(timestamp time.Time, findings []report.Finding) Record {
+	record := Record{
+		Version:    RecordVersion,
+		Timestamp:  timestamp.UTC(),
+		Total:      len(findings),
+		BySeverity: map[string]int{},
+		ByRule:     map[string]int{},
+		Findings:   make([]Finding, 0, len(findings)),
+	}
+	for i := range findings {
+		finding := &findings[i]
+		record.Findings = append(record.Findings, Finding{
+			Fingerprint: finding.Fingerprint,
+			Key:         finding.Key,
+			RuleID:      finding.RuleID,
+			Severity:    finding.Severity,
+			Ignored:     finding.Ignored,
+		})
+		if finding.Ignored {
+			record.Ignored++
+			continue
+		}
+		record.BySeverity[finding.Severity]++
+		if finding.RuleID != "" {
+			record.ByRule[finding.RuleID]++
+		}
+	}
+	return record
+}
+
+// Append appends record to the history in dir, creating the directory if needed.
+func Append(dir string, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	// the record is written at once, so that runs appending concurrently do not interleave
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append to history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to append to history: %w", err)
+	}
+	return nil
+}
+
+// Load returns the records of the history in dir, in the order they were appended, and the number of lines skipped
+// because they do not hold a record of a known version, e.g. one cut short by an interrupted run.
+func Load(dir string) ([]Record, int, error) {
+	f, err := os.Open(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	skipped := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxRecordSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil || record.Version < 1 || record.Version > RecordVersion {
+			skipped++
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read history: %w", err)
+	}
+	return records, skipped, nil
+}
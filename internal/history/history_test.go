@@ -0,0 +1,76 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+func TestNewRecord(t *testing.T) {
+	timestamp := time.Date(2026, 10, 14, 9, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+	findings := []report.Finding{
+		{Key: "k1", Fingerprint: "fp1", RuleID: "aws-access-token", Severity: report.SeverityCritical},
+		{Key: "k2", Fingerprint: "fp2", RuleID: "aws-access-token", Severity: report.SeverityHigh},
+		{Key: "k3", RuleID: "generic-api-key", Severity: report.SeverityHigh, Ignored: true},
+	}
+
+	record := NewRecord(timestamp, findings)
+
+	assert.Equal(t, RecordVersion, record.Version)
+	assert.Equal(t, time.UTC, record.Timestamp.Location())
+	assert.True(t, timestamp.Equal(record.Timestamp))
+	assert.Equal(t, 3, record.Total)
+	assert.Equal(t, 1, record.Ignored)
+	assert.Equal(t, map[string]int{report.SeverityCritical: 1, report.SeverityHigh: 1}, record.BySeverity)
+	assert.Equal(t, map[string]int{"aws-access-token": 2}, record.ByRule)
+	require.Len(t, record.Findings, 3)
+	assert.Equal(t, Finding{Key: "k3", RuleID: "generic-api-key", Severity: report.SeverityHigh, Ignored: true}, record.Findings[2])
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	first := NewRecord(time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), []report.Finding{{Fingerprint: "fp1", Severity: report.SeverityLow}})
+	first.Branch = "main"
+	first.Stats = Stats{FilesWalked: 10, FilesKept: 4, FilesUploaded: 4, SizeFiltered: 1, UploadMs: 120, Retries: 2}
+	second := NewRecord(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), nil)
+	second.Incomplete = true
+
+	require.NoError(t, Append(dir, first))
+	require.NoError(t, Append(dir, second))
+
+	records, skipped, err := Load(dir)
+	require.NoError(t, err)
+	assert.Zero(t, skipped)
+	require.Len(t, records, 2)
+	assert.Equal(t, "main", records[0].Branch)
+	assert.Equal(t, first.Stats, records[0].Stats)
+	assert.Equal(t, []Finding{{Fingerprint: "fp1", Severity: report.SeverityLow}}, records[0].Findings)
+	assert.True(t, records[1].Incomplete)
+}
+
+func TestLoad_SkipsUnreadableRecords(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Append(dir, NewRecord(time.Now(), nil)))
+
+	f, err := os.OpenFile(filepath.Join(dir, FileName), os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("\n{\"version\":99}\n{\"version\":1,\"timest")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	records, skipped, err := Load(dir)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 2, skipped)
+}
+
+func TestLoad_NoHistory(t *testing.T) {
+	_, _, err := Load(t.TempDir())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
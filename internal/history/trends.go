@@ -0,0 +1,288 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+const (
+	week = 7 * 24 * time.Hour
+	// topRulesLimit is the number of rules listed in the trends.
+	topRulesLimit = 10
+)
+
+// TrendsOptions configures the summary of a history.
+type TrendsOptions struct {
+	// Weeks is the number of weeks summarized, up to the current one. The whole history is summarized if it is 0.
+	Weeks int
+	// Now is the time the history is summarized at, the current time if zero.
+	Now time.Time
+}
+
+// Trends summarizes the findings of the runs in a history over time. Runs are compared with the previous run of the
+// same repository, branch and directory, so the first run of each is a baseline whose findings are not new.
+type Trends struct {
+	// From is the start of the first week summarized, and To the time the history was summarized at.
+	From time.Time
+	To   time.Time
+	// Runs counts the runs of the summarized weeks, and Targets the repositories, branches and directories tested.
+	Runs    int
+	Targets int
+	Weeks   []Week
+	// Open counts the findings of the latest runs that are not ignored.
+	Open           int
+	OpenBySeverity map[string]int
+	// Introduced and Fixed count the findings that appeared and disappeared during the summarized weeks.
+	Introduced int
+	Fixed      int
+	// MeanTimeToRemediate is the mean time from the run a fixed finding was first seen in to the run it disappeared
+	// in. It is 0 if no finding was fixed.
+	MeanTimeToRemediate time.Duration
+	// TopRules are the rules with the most open findings, then the most introduced.
+	TopRules []RuleTrend
+}
+
+// Week counts the findings that appeared and disappeared during the week starting on the Monday Start, in UTC.
+type Week struct {
+	Start time.Time
+	New   int
+	Fixed int
+}
+
+// RuleTrend counts the findings of a rule.
+type RuleTrend struct {
+	RuleID     string
+	Open       int
+	Introduced int
+	Fixed      int
+}
+
+// openFinding is a finding that has not been fixed yet, with the time of the run it was first seen in.
+type openFinding struct {
+	Finding
+	since time.Time
+}
+
+// Summarize summarizes the trends of records. Incomplete runs and runs after opts.Now are skipped.
+func Summarize(records []Record, opts TrendsOptions) Trends {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+
+	sorted := slices.Clone(records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	from := weekStart(now)
+	switch {
+	case opts.Weeks > 0:
+		from = from.AddDate(0, 0, -7*(opts.Weeks-1))
+	case len(sorted) > 0 && sorted[0].Timestamp.Before(from):
+		from = weekStart(sorted[0].Timestamp)
+	}
+
+	t := Trends{From: from, To: now, OpenBySeverity: map[string]int{}}
+	for start := from; !start.After(now); start = start.Add(week) {
+		t.Weeks = append(t.Weeks, Week{Start: start})
+	}
+	inWindow := func(ts time.Time) bool { return !ts.Before(from) && !ts.After(now) }
+
+	var targets []string
+	byTarget := map[string][]*Record{}
+	tested := map[string]bool{}
+	for i := range sorted {
+		record := &sorted[i]
+		if record.Timestamp.After(now) {
+			break
+		}
+		target := targetKey(record)
+		if _, ok := byTarget[target]; !ok {
+			targets = append(targets, target)
+		}
+		byTarget[target] = append(byTarget[target], record)
+		if inWindow(record.Timestamp) {
+			t.Runs++
+			tested[target] = true
+		}
+	}
+	t.Targets = len(tested)
+
+	rules := map[string]*RuleTrend{}
+	rule := func(id string) *RuleTrend {
+		if rules[id] == nil {
+			rules[id] = &RuleTrend{RuleID: id}
+		}
+		return rules[id]
+	}
+	var remediation time.Duration
+	for _, target := range targets {
+		open := summarizeTarget(byTarget[target], func(event string, finding *openFinding, ts time.Time) {
+			if !inWindow(ts) {
+				return
+			}
+			w := &t.Weeks[int(ts.Sub(from)/week)]
+			if event == report.StateNew {
+				t.Introduced++
+				w.New++
+				rule(finding.RuleID).Introduced++
+				return
+			}
+			t.Fixed++
+			w.Fixed++
+			rule(finding.RuleID).Fixed++
+			remediation += ts.Sub(finding.since)
+		})
+		for _, finding := range open {
+			t.Open++
+			t.OpenBySeverity[finding.Severity]++
+			rule(finding.RuleID).Open++
+		}
+	}
+	if t.Fixed > 0 {
+		t.MeanTimeToRemediate = remediation / time.Duration(t.Fixed)
+	}
+
+	delete(rules, "")
+	for _, r := range rules {
+		t.TopRules = append(t.TopRules, *r)
+	}
+	sort.Slice(t.TopRules, func(i, j int) bool {
+		a, b := t.TopRules[i], t.TopRules[j]
+		if a.Open != b.Open {
+			return a.Open > b.Open
+		}
+		if a.Introduced != b.Introduced {
+			return a.Introduced > b.Introduced
+		}
+		if a.Fixed != b.Fixed {
+			return a.Fixed > b.Fixed
+		}
+		return a.RuleID < b.RuleID
+	})
+	if len(t.TopRules) > topRulesLimit {
+		t.TopRules = t.TopRules[:topRulesLimit]
+	}
+	return t
+}
+
+// summarizeTarget compares the consecutive complete runs of a target, calling event with report.StateNew or
+// report.StateFixed for every finding that appeared or disappeared, and returns the findings open after the last run.
+// A finding that becomes ignored is no longer open, but not fixed either.
+func summarizeTarget(records []*Record, event func(string, *openFinding, time.Time)) map[string]*openFinding {
+	var open map[string]*openFinding
+	for _, record := range records {
+		if record.Incomplete {
+			continue
+		}
+		baseline := open == nil
+		if baseline {
+			open = map[string]*openFinding{}
+		}
+
+		current := map[string]bool{}
+		for i := range record.Findings {
+			finding := &record.Findings[i]
+			id := finding.id()
+			current[id] = true
+			if finding.Ignored {
+				delete(open, id)
+				continue
+			}
+			if _, ok := open[id]; ok {
+				continue
+			}
+			open[id] = &openFinding{Finding: *finding, since: record.Timestamp}
+			if !baseline {
+				event(report.StateNew, open[id], record.Timestamp)
+			}
+		}
+		for id, finding := range open {
+			if !current[id] {
+				delete(open, id)
+				event(report.StateFixed, finding, record.Timestamp)
+			}
+		}
+	}
+	return open
+}
+
+// targetKey identifies the repository, branch and directory tested by a run.
+func targetKey(record *Record) string {
+	return strings.Join([]string{record.RepoURL, record.Branch, record.RootFolder}, "\x00")
+}
+
+// weekStart returns the start of the week of ts, on Monday in UTC.
+func weekStart(ts time.Time) time.Time {
+	ts = ts.UTC()
+	daysSinceMonday := (int(ts.Weekday()) + 6) % 7
+	return time.Date(ts.Year(), ts.Month(), ts.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// RenderText writes the human readable summary of trends to w.
+func RenderText(w io.Writer, t *Trends) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nSecrets trends from %s to %s\n", t.From.Format(time.DateOnly), t.To.Format(time.DateOnly))
+	fmt.Fprintf(&b, "  %d runs of %d %s\n", t.Runs, t.Targets, plural(t.Targets, "target", "targets"))
+
+	b.WriteString("\n  Week of      New  Fixed\n")
+	for _, w := range t.Weeks {
+		fmt.Fprintf(&b, "  %s %5d %6d\n", w.Start.Format(time.DateOnly), w.New, w.Fixed)
+	}
+
+	fmt.Fprintf(&b, "\nOpen findings: %d", t.Open)
+	var severities []string
+	for _, severity := range report.Severities {
+		if n := t.OpenBySeverity[severity]; n > 0 {
+			severities = append(severities, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	if len(severities) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(severities, ", "))
+	}
+	fmt.Fprintf(&b, "\nIntroduced: %d, fixed: %d\n", t.Introduced, t.Fixed)
+	if t.Fixed > 0 {
+		fmt.Fprintf(&b, "Mean time to remediate: %s\n", formatDuration(t.MeanTimeToRemediate))
+	}
+
+	if len(t.TopRules) > 0 {
+		b.WriteString("\nTop rules\n")
+		width := 0
+		for _, r := range t.TopRules {
+			width = max(width, len(r.RuleID))
+		}
+		for _, r := range t.TopRules {
+			fmt.Fprintf(&b, "  %-*s  %d open, %d introduced, %d fixed\n", width, r.RuleID, r.Open, r.Introduced, r.Fixed)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write trends: %w", err)
+	}
+	return nil
+}
+
+// formatDuration formats d in days and hours, or hours and minutes below a day.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", d/time.Hour, d%time.Hour/time.Minute)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
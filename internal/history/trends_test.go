@@ -0,0 +1,108 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/cli-extension-secrets/internal/report"
+)
+
+func TestSummarize(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	record := func(ts time.Time, branch string, findings ...Finding) Record {
+		return Record{Version: RecordVersion, Timestamp: ts, Branch: branch, Findings: findings}
+	}
+	leaked := Finding{Fingerprint: "fp-leaked", RuleID: "aws-access-token", Severity: report.SeverityCritical}
+	token := Finding{Fingerprint: "fp-token", RuleID: "github-pat", Severity: report.SeverityHigh}
+	keyed := Finding{Key: "k-password", RuleID: "generic-password", Severity: report.SeverityMedium}
+	ignored := keyed
+	ignored.Ignored = true
+
+	records := []Record{
+		// the baseline of main, its findings are not new
+		record(day(5), "main", leaked),
+		// appended out of order
+		record(day(9), "main", leaked, keyed),
+		record(day(7), "main", leaked, token),
+		// findings missing from incomplete runs are not fixed
+		{Version: RecordVersion, Timestamp: day(12), Branch: "main", Incomplete: true},
+		record(day(14), "main", token, ignored),
+		// another branch is compared with its own runs
+		record(day(13), "feature", leaked),
+		// runs after now are skipped
+		record(day(30), "main"),
+	}
+
+	trends := Summarize(records, TrendsOptions{Weeks: 3, Now: day(16)})
+
+	assert.Equal(t, time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC), trends.From)
+	assert.Equal(t, 6, trends.Runs)
+	assert.Equal(t, 2, trends.Targets)
+	assert.Equal(t, []Week{
+		{Start: time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), New: 2, Fixed: 1},
+		{Start: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), New: 1, Fixed: 1},
+	}, trends.Weeks)
+	// the token is new again when it reappears after being fixed
+	assert.Equal(t, 3, trends.Introduced)
+	assert.Equal(t, 2, trends.Fixed)
+	// the token was fixed after 2 days on day 9 and the leaked key after 9 days on day 14
+	assert.Equal(t, 132*time.Hour, trends.MeanTimeToRemediate)
+	// the reappeared token is open on main and the leaked key on feature, the ignored password is not open
+	assert.Equal(t, 2, trends.Open)
+	assert.Equal(t, map[string]int{report.SeverityCritical: 1, report.SeverityHigh: 1}, trends.OpenBySeverity)
+	assert.Equal(t, []RuleTrend{
+		{RuleID: "github-pat", Open: 1, Introduced: 2, Fixed: 1},
+		{RuleID: "aws-access-token", Open: 1, Fixed: 1},
+		{RuleID: "generic-password", Introduced: 1},
+	}, trends.TopRules)
+}
+
+func TestSummarize_WholeHistory(t *testing.T) {
+	records := []Record{
+		{Version: RecordVersion, Timestamp: time.Date(2026, 8, 20, 0, 0, 0, 0, time.UTC)},
+	}
+
+	trends := Summarize(records, TrendsOptions{Now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)})
+
+	assert.Equal(t, time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC), trends.From)
+	assert.Len(t, trends.Weeks, 9)
+	assert.Equal(t, 1, trends.Runs)
+}
+
+func TestRenderText(t *testing.T) {
+	trends := Trends{
+		From:                time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+		To:                  time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		Runs:                4,
+		Targets:             1,
+		Weeks:               []Week{{Start: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), New: 2, Fixed: 1}},
+		Open:                3,
+		OpenBySeverity:      map[string]int{report.SeverityHigh: 1, report.SeverityCritical: 2},
+		Introduced:          2,
+		Fixed:               1,
+		MeanTimeToRemediate: 52 * time.Hour,
+		TopRules:            []RuleTrend{{RuleID: "github-pat", Open: 2, Introduced: 2}},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderText(&b, &trends))
+	out := b.String()
+
+	assert.Contains(t, out, "Secrets trends from 2026-10-05 to 2026-10-16")
+	assert.Contains(t, out, "4 runs of 1 target\n")
+	assert.Contains(t, out, "  2026-10-05     2      1\n")
+	assert.Contains(t, out, "Open findings: 3 (2 critical, 1 high)")
+	assert.Contains(t, out, "Mean time to remediate: 2d 4h")
+	assert.Contains(t, out, "  github-pat  2 open, 2 introduced, 0 fixed")
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "42m", formatDuration(42*time.Minute))
+	assert.Equal(t, "3h 5m", formatDuration(3*time.Hour+5*time.Minute))
+	assert.Equal(t, "1d 0h", formatDuration(24*time.Hour))
+}
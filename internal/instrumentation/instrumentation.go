@@ -2,6 +2,7 @@
 package instrumentation
 
 import (
+	"sync"
 	"time"
 
 	"github.com/snyk/go-application-framework/pkg/analytics"
@@ -13,14 +14,18 @@ const (
 	SecretsFileUploadTimeMs string = "fileUploadMs"
 	SecretsFileFilterTimeMs string = "fileFilterMs"
 	SecretsSizeFiltered     string = "sizeFiltered"
+	SecretsKeywordFiltered  string = "keywordFiltered"
+	SecretsRetryCount       string = "retryCount"
 )
 
 // Instrumentation defines the interface that we expect for instrumentation objects.
 type Instrumentation interface {
 	RecordSizeFiltered(total int)
+	RecordKeywordFiltered(total int)
 	RecordAnalysisTimeMs(startTime time.Time)
 	RecordFileUploadTimeMs(startTime time.Time)
 	RecordFileFilterTimeMs(startTime time.Time)
+	RecordRetryCount(total int)
 
 	RecordTime(key string, startTime time.Time)
 }
@@ -59,3 +64,81 @@ func (i *GAFInstrumentation) RecordFileFilterTimeMs(startTime time.Time) {
 func (i *GAFInstrumentation) RecordSizeFiltered(total int) {
 	i.analytics.AddExtensionIntegerValue(SecretsSizeFiltered, total)
 }
+
+// RecordKeywordFiltered records the number of files excluded by keyword prefiltering.
+func (i *GAFInstrumentation) RecordKeywordFiltered(total int) {
+	i.analytics.AddExtensionIntegerValue(SecretsKeywordFiltered, total)
+}
+
+// RecordRetryCount records the number of API requests that were retried after a transient failure.
+func (i *GAFInstrumentation) RecordRetryCount(total int) {
+	i.analytics.AddExtensionIntegerValue(SecretsRetryCount, total)
+}
+
+// NewRecorder will create a new Recorder forwarding the recorded values to next.
+func NewRecorder(next Instrumentation) *Recorder {
+	return &Recorder{next: next, values: map[string]int{}}
+}
+
+// Recorder keeps the values recorded by a run, so that they can be read back once it completes, and forwards them
+// to the wrapped instrumentation.
+type Recorder struct {
+	next   Instrumentation
+	mu     sync.Mutex
+	values map[string]int
+}
+
+// Value returns the value last recorded for key, or 0 if none was recorded.
+func (r *Recorder) Value(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[key]
+}
+
+func (r *Recorder) set(key string, value int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[key] = value
+}
+
+// RecordTime records the time elapsed since startTime under key.
+func (r *Recorder) RecordTime(key string, startTime time.Time) {
+	r.set(key, int(time.Since(startTime).Milliseconds()))
+	r.next.RecordTime(key, startTime)
+}
+
+// RecordAnalysisTimeMs records the duration of the secrets analysis phase.
+func (r *Recorder) RecordAnalysisTimeMs(startTime time.Time) {
+	r.set(SecretsAnalysisTimeMs, int(time.Since(startTime).Milliseconds()))
+	r.next.RecordAnalysisTimeMs(startTime)
+}
+
+// RecordFileUploadTimeMs records the duration of the file upload phase.
+func (r *Recorder) RecordFileUploadTimeMs(startTime time.Time) {
+	r.set(SecretsFileUploadTimeMs, int(time.Since(startTime).Milliseconds()))
+	r.next.RecordFileUploadTimeMs(startTime)
+}
+
+// RecordFileFilterTimeMs records the duration of the file filtering phase.
+func (r *Recorder) RecordFileFilterTimeMs(startTime time.Time) {
+	r.set(SecretsFileFilterTimeMs, int(time.Since(startTime).Milliseconds()))
+	r.next.RecordFileFilterTimeMs(startTime)
+}
+
+// RecordSizeFiltered records the number of files excluded by size filtering.
+func (r *Recorder) RecordSizeFiltered(total int) {
+	r.set(SecretsSizeFiltered, total)
+	r.next.RecordSizeFiltered(total)
+}
+
+// RecordKeywordFiltered records the number of files excluded by keyword prefiltering.
+func (r *Recorder) RecordKeywordFiltered(total int) {
+	r.set(SecretsKeywordFiltered, total)
+	r.next.RecordKeywordFiltered(total)
+}
+
+// RecordRetryCount records the number of API requests that were retried after a transient failure.
+func (r *Recorder) RecordRetryCount(total int) {
+	r.set(SecretsRetryCount, total)
+	r.next.RecordRetryCount(total)
+}
@@ -0,0 +1,82 @@
+// Package redact blanks out the lines of files that cannot contain a secret, keeping their line numbers.
+package redact
+
+import (
+	"bytes"
+)
+
+// DefaultContextLines is the number of lines kept before and after every candidate line.
+const DefaultContextLines = 3
+
+var (
+	pemBegin = []byte("-----BEGIN ")
+	pemEnd   = []byte("-----END ")
+)
+
+// Result is the redacted content of a file.
+type Result struct {
+	Content []byte
+	// KeptLines is the number of lines of the file kept as they were.
+	KeptLines int
+	// TotalLines is the number of lines of the file.
+	TotalLines int
+}
+
+// Lines keeps the lines of content for which isCandidate is true, contextLines lines around them and
+// every line of the PEM blocks they start, and blanks out all other lines. Line endings are kept, so that
+// the lines and columns of the redacted content are those of content.
+func Lines(content []byte, contextLines int, isCandidate func(line []byte) bool) Result {
+	lines := splitLines(content)
+
+	keep := make([]bool, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if !isCandidate(lines[i].text) {
+			continue
+		}
+		last := i
+		// a private key spans many lines which all make up the secret
+		if bytes.Contains(lines[i].text, pemBegin) {
+			for last < len(lines)-1 && !bytes.Contains(lines[last].text, pemEnd) {
+				last++
+			}
+		}
+		for j := max(0, i-contextLines); j <= min(len(lines)-1, last+contextLines); j++ {
+			keep[j] = true
+		}
+		i = last
+	}
+
+	result := Result{Content: make([]byte, 0, len(content)), TotalLines: len(lines)}
+	for i, line := range lines {
+		if keep[i] {
+			result.Content = append(result.Content, line.text...)
+			result.KeptLines++
+		}
+		result.Content = append(result.Content, line.ending...)
+	}
+	return result
+}
+
+type line struct {
+	text   []byte
+	ending []byte
+}
+
+// splitLines splits content into lines, separating the line endings, \n or \r\n, from the text of each line.
+func splitLines(content []byte) []line {
+	var lines []line
+	for len(content) > 0 {
+		end := bytes.IndexByte(content, '\n')
+		if end < 0 {
+			lines = append(lines, line{text: content})
+			break
+		}
+		text, ending := content[:end], content[end:end+1]
+		if len(text) > 0 && text[len(text)-1] == '\r' {
+			text, ending = text[:len(text)-1], content[end-1:end+1]
+		}
+		lines = append(lines, line{text: text, ending: ending})
+		content = content[end+1:]
+	}
+	return lines
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Annotation formats, the workflow commands of the CI systems showing findings inline on pull requests.
+const (
+	AnnotationsGitHub = "github"
+	AnnotationsAzure  = "azure"
+)
+
+// AnnotationOptions configures the annotations of findings.
+type AnnotationOptions struct {
+	// Format is AnnotationsGitHub or AnnotationsAzure.
+	Format string
+	// PathPrefix is the slash-separated path of the tested directory within the repository, as annotations are
+	// placed relative to the repository root.
+	PathPrefix string
+}
+
+// RenderAnnotations writes a workflow command for every location of findings to w, as an error for critical and
+// high severity findings and as a warning otherwise. Ignored findings are left out.
+func RenderAnnotations(w io.Writer, findings []Finding, opts AnnotationOptions) error {
+	var render func(b *strings.Builder, finding *Finding, loc Location)
+	switch opts.Format {
+	case AnnotationsGitHub:
+		render = writeGitHubAnnotation
+	case AnnotationsAzure:
+		render = writeAzureAnnotation
+	default:
+		return fmt.Errorf("unsupported annotation format %q", opts.Format)
+	}
+
+	var b strings.Builder
+	for i := range findings {
+		if findings[i].Ignored {
+			continue
+		}
+		for _, loc := range findings[i].Locations {
+			if opts.PathPrefix != "" && opts.PathPrefix != "." {
+				loc.FilePath = path.Join(opts.PathPrefix, loc.FilePath)
+			}
+			render(&b, &findings[i], loc)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write annotations: %w", err)
+	}
+	return nil
+}
+
+// annotationLevel is the level of the annotation of a finding, which only fails the review of critical and
+// high severity findings.
+func annotationLevel(severity string) string {
+	if severity == SeverityCritical || severity == SeverityHigh {
+		return "error"
+	}
+	return "warning"
+}
+
+func annotationTitle(finding *Finding) string {
+	return "Snyk Secrets: " + finding.Title
+}
+
+func annotationMessage(finding *Finding) string {
+	message := fmt.Sprintf("%s severity secret", finding.Severity)
+	if finding.RuleID != "" {
+		message += fmt.Sprintf(" found by rule %s", finding.RuleID)
+	}
+	return message
+}
+
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-error-message
+func writeGitHubAnnotation(b *strings.Builder, finding *Finding, loc Location) {
+	properties := []string{"file=" + escapeGitHubProperty(loc.FilePath), fmt.Sprintf("line=%d", loc.FromLine)}
+	if loc.FromColumn > 0 {
+		properties = append(properties, fmt.Sprintf("col=%d", loc.FromColumn))
+	}
+	if loc.ToLine > loc.FromLine {
+		properties = append(properties, fmt.Sprintf("endLine=%d", loc.ToLine))
+	} else if loc.FromColumn > 0 && loc.ToColumn > loc.FromColumn {
+		// the end column of annotations is inclusive
+		properties = append(properties, fmt.Sprintf("endColumn=%d", loc.ToColumn-1))
+	}
+	properties = append(properties, "title="+escapeGitHubProperty(annotationTitle(finding)))
+
+	message := annotationMessage(finding)
+	if finding.Description != "" {
+		message += "\n\n" + finding.Description
+	}
+	fmt.Fprintf(b, "::%s %s::%s\n", annotationLevel(finding.Severity), strings.Join(properties, ","), escapeGitHubData(message))
+}
+
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// https://learn.microsoft.com/en-us/azure/devops/pipelines/scripts/logging-commands#logissue-log-an-error-or-warning
+func writeAzureAnnotation(b *strings.Builder, finding *Finding, loc Location) {
+	properties := []string{
+		"type=" + annotationLevel(finding.Severity),
+		"sourcepath=" + escapeAzure(loc.FilePath),
+		fmt.Sprintf("linenumber=%d", loc.FromLine),
+	}
+	if loc.FromColumn > 0 {
+		properties = append(properties, fmt.Sprintf("columnnumber=%d", loc.FromColumn))
+	}
+	if finding.RuleID != "" {
+		properties = append(properties, "code="+escapeAzure(finding.RuleID))
+	}
+
+	message := annotationTitle(finding) + " (" + annotationMessage(finding) + ")"
+	fmt.Fprintf(b, "##vso[task.logissue %s]%s\n", strings.Join(properties, ";"), escapeAzure(message))
+}
+
+func escapeAzure(s string) string {
+	return strings.NewReplacer("%", "%AZP25", ";", "%3B", "\r", "%0D", "\n", "%0A", "]", "%5D").Replace(s)
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func annotationFindings() []Finding {
+	return []Finding{
+		{
+			RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical, Description: "Keys grant access.\n100% secret",
+			Locations: []Location{{FilePath: "config/gcp.json", FromLine: 7, FromColumn: 20, ToLine: 7, ToColumn: 60}},
+		},
+		{
+			RuleID: "generic-api-key", Title: "Generic API Key", Severity: SeverityLow,
+			Locations: []Location{{FilePath: "a,b.env", FromLine: 2, FromColumn: 1, ToLine: 4, ToColumn: 3}},
+		},
+		{RuleID: "aws-access-token", Title: "AWS Access Token", Severity: SeverityHigh, Ignored: true, Locations: []Location{{FilePath: "ignored.env", FromLine: 1}}},
+	}
+}
+
+func TestRenderAnnotations_GitHub(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderAnnotations(&b, annotationFindings(), AnnotationOptions{Format: AnnotationsGitHub, PathPrefix: "services/api"}))
+
+	assert.Equal(t,
+		"::error file=services/api/config/gcp.json,line=7,col=20,endColumn=59,title=Snyk Secrets%3A Private Key::"+
+			"critical severity secret found by rule private-key%0A%0AKeys grant access.%0A100%25 secret\n"+
+			"::warning file=services/api/a%2Cb.env,line=2,col=1,endLine=4,title=Snyk Secrets%3A Generic API Key::"+
+			"low severity secret found by rule generic-api-key\n",
+		b.String())
+}
+
+func TestRenderAnnotations_Azure(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderAnnotations(&b, annotationFindings(), AnnotationOptions{Format: AnnotationsAzure, PathPrefix: "."}))
+
+	assert.Equal(t,
+		"##vso[task.logissue type=error;sourcepath=config/gcp.json;linenumber=7;columnnumber=20;code=private-key]"+
+			"Snyk Secrets: Private Key (critical severity secret found by rule private-key)\n"+
+			"##vso[task.logissue type=warning;sourcepath=a,b.env;linenumber=2;columnnumber=1;code=generic-api-key]"+
+			"Snyk Secrets: Generic API Key (low severity secret found by rule generic-api-key)\n",
+		b.String())
+}
+
+func TestRenderAnnotations_Escaping(t *testing.T) {
+	assert.Equal(t, "a%AZP25b%3Bc%5D%0A", escapeAzure("a%b;c]\n"))
+	assert.Equal(t, "a%25b%3Ac%2Cd%0D%0A", escapeGitHubProperty("a%b:c,d\r\n"))
+	assert.Equal(t, "a%25b:c,d%0A", escapeGitHubData("a%b:c,d\n"))
+}
+
+func TestRenderAnnotations_UnsupportedFormat(t *testing.T) {
+	var b strings.Builder
+	assert.Error(t, RenderAnnotations(&b, annotationFindings(), AnnotationOptions{Format: "jenkins"}))
+}
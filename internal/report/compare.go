@@ -0,0 +1,181 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// States of findings compared between two tests.
+const (
+	StateNew       = "new"
+	StateFixed     = "fixed"
+	StateMoved     = "moved"
+	StateUnchanged = "unchanged"
+)
+
+// States lists the states of compared findings, in the order they are reported.
+var States = []string{StateNew, StateFixed, StateMoved, StateUnchanged}
+
+// ComparedFinding is a finding of either of two compared tests, with its state.
+type ComparedFinding struct {
+	Finding
+	State string
+	// PreviousLocations are the locations of a moved finding in the old test.
+	PreviousLocations []Location
+}
+
+// Compare matches the findings of a new test against those of an old one. Findings are matched by their
+// fingerprint, and those without a fingerprint or without a match by their key. Matched findings are unchanged,
+// or moved if their locations differ. The findings of the new test without a match are new, those of the old test
+// fixed. Fixed findings are returned as found in the old test, all others as found in the new one.
+func Compare(oldFindings, newFindings []Finding) []ComparedFinding {
+	matched := make([]bool, len(oldFindings))
+	match := func(finding *Finding, identity func(*Finding) string) int {
+		id := identity(finding)
+		if id == "" {
+			return -1
+		}
+		for i := range oldFindings {
+			if !matched[i] && identity(&oldFindings[i]) == id {
+				matched[i] = true
+				return i
+			}
+		}
+		return -1
+	}
+
+	result := make([]ComparedFinding, len(newFindings), len(newFindings)+len(oldFindings))
+	previous := make([]int, len(newFindings))
+	for i := range newFindings {
+		result[i] = ComparedFinding{Finding: newFindings[i], State: StateNew}
+		previous[i] = match(&newFindings[i], func(f *Finding) string { return f.Fingerprint })
+	}
+	for i := range newFindings {
+		if previous[i] < 0 {
+			previous[i] = match(&newFindings[i], func(f *Finding) string { return f.Key })
+		}
+		if previous[i] < 0 {
+			continue
+		}
+		oldLocations := oldFindings[previous[i]].Locations
+		if sameLocations(oldLocations, newFindings[i].Locations) {
+			result[i].State = StateUnchanged
+		} else {
+			result[i].State = StateMoved
+			result[i].PreviousLocations = oldLocations
+		}
+	}
+	for i := range oldFindings {
+		if !matched[i] {
+			result = append(result, ComparedFinding{Finding: oldFindings[i], State: StateFixed})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if rank := slices.Index(States, result[i].State) - slices.Index(States, result[j].State); rank != 0 {
+			return rank < 0
+		}
+		return SeverityRank(result[i].Severity) < SeverityRank(result[j].Severity)
+	})
+	return result
+}
+
+// sameLocations reports whether two findings were found at the same locations, in any order.
+func sameLocations(a, b []Location) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[Location]int{}
+	for _, loc := range a {
+		counts[loc]++
+	}
+	for _, loc := range b {
+		if counts[loc] == 0 {
+			return false
+		}
+		counts[loc]--
+	}
+	return true
+}
+
+// CountStates counts compared findings by state.
+func CountStates(findings []ComparedFinding) map[string]int {
+	counts := map[string]int{}
+	for i := range findings {
+		counts[findings[i].State]++
+	}
+	return counts
+}
+
+// ComparisonTextOptions configures the text report of a comparison.
+type ComparisonTextOptions struct {
+	// OldName and NewName name the compared tests, e.g. by the paths of their results.
+	OldName string
+	NewName string
+	// Color renders severities and highlights with ANSI escape codes.
+	Color bool
+}
+
+var stateTitles = map[string]string{
+	StateNew:       "New",
+	StateFixed:     "Fixed",
+	StateMoved:     "Moved",
+	StateUnchanged: "Unchanged",
+}
+
+// RenderComparisonText writes the human readable report of compared findings to w, listing the new, fixed and
+// moved findings with their locations. Unchanged findings are only counted.
+func RenderComparisonText(w io.Writer, findings []ComparedFinding, opts ComparisonTextOptions) error {
+	r := textRenderer{opts: TextOptions{Color: opts.Color}}
+	byState := map[string][]*ComparedFinding{}
+	for i := range findings {
+		byState[findings[i].State] = append(byState[findings[i].State], &findings[i])
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nComparing %s with %s\n", opts.OldName, opts.NewName)
+	for _, state := range []string{StateNew, StateFixed, StateMoved} {
+		if len(byState[state]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s\n", r.style(ansiBold, fmt.Sprintf("%s (%d)", stateTitles[state], len(byState[state]))))
+		for _, finding := range byState[state] {
+			fmt.Fprintf(&b, " %s %s", r.severity(finding.Severity), r.style(ansiBold, finding.Title))
+			if finding.RuleID != "" {
+				fmt.Fprintf(&b, " %s", r.style(ansiDim, "("+finding.RuleID+")"))
+			}
+			if finding.Ignored {
+				fmt.Fprintf(&b, " %s", r.style(ansiDim, "[ignored]"))
+			}
+			b.WriteString("\n")
+			for i, loc := range finding.Locations {
+				if i < len(finding.PreviousLocations) && finding.PreviousLocations[i] != loc {
+					fmt.Fprintf(&b, "   %s → %s\n", finding.PreviousLocations[i], loc)
+					continue
+				}
+				fmt.Fprintf(&b, "   %s\n", loc)
+			}
+		}
+	}
+
+	counts := CountStates(findings)
+	b.WriteString("\n")
+	b.WriteString(r.style(ansiBold, "Summary"))
+	b.WriteString("\n\n")
+	parts := make([]string, 0, len(States))
+	for _, state := range States {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	fmt.Fprintf(&b, "  %s\n", strings.Join(parts, ", "))
+	if counts[StateNew] == 0 {
+		b.WriteString("  ✔ No new secrets found.\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write comparison report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	at := func(path string, line int) []Location {
+		return []Location{{FilePath: path, FromLine: line, FromColumn: 5, ToLine: line, ToColumn: 25}}
+	}
+	oldFindings := []Finding{
+		{Key: "k-unchanged", Fingerprint: "fp-unchanged", Severity: SeverityLow, Locations: at("a.env", 1)},
+		{Key: "k-moved", Fingerprint: "fp-moved", Severity: SeverityHigh, Locations: at("b.env", 3)},
+		{Key: "k-fixed", Fingerprint: "fp-fixed", Severity: SeverityMedium, Locations: at("c.env", 1)},
+		{Key: "k-by-key", Severity: SeverityLow, Locations: at("d.env", 1)},
+		{Key: "k-twice", Fingerprint: "fp-twice", Severity: SeverityLow, Locations: at("e.env", 1)},
+	}
+	newFindings := []Finding{
+		{Key: "k-unchanged-2", Fingerprint: "fp-unchanged", Severity: SeverityLow, Locations: at("a.env", 1)},
+		{Key: "k-moved", Fingerprint: "fp-moved", Severity: SeverityHigh, Locations: at("b.env", 8)},
+		{Key: "k-new", Fingerprint: "fp-new", Severity: SeverityCritical, Locations: at("f.env", 1)},
+		{Key: "k-by-key", Fingerprint: "fp-by-key", Severity: SeverityLow, Locations: at("d.env", 1)},
+		{Key: "k-twice", Fingerprint: "fp-twice", Severity: SeverityLow, Locations: at("e.env", 1)},
+		{Key: "k-twice-2", Fingerprint: "fp-twice", Severity: SeverityLow, Locations: at("e.env", 1)},
+	}
+
+	compared := Compare(oldFindings, newFindings)
+
+	states := map[string]string{}
+	for i := range compared {
+		states[compared[i].Key] = compared[i].State
+	}
+	assert.Equal(t, map[string]string{
+		"k-unchanged-2": StateUnchanged,
+		"k-moved":       StateMoved,
+		"k-new":         StateNew,
+		"k-fixed":       StateFixed,
+		"k-by-key":      StateUnchanged,
+		"k-twice":       StateUnchanged,
+		"k-twice-2":     StateNew,
+	}, states)
+	assert.Equal(t, map[string]int{StateNew: 2, StateFixed: 1, StateMoved: 1, StateUnchanged: 3}, CountStates(compared))
+
+	require.Len(t, compared, 7)
+	assert.Equal(t, "k-new", compared[0].Key, "new findings come first, the most severe first")
+	assert.Equal(t, StateFixed, compared[2].State)
+	assert.Equal(t, StateMoved, compared[3].State)
+	assert.Equal(t, at("b.env", 3), compared[3].PreviousLocations)
+	assert.Equal(t, at("b.env", 8), compared[3].Locations)
+}
+
+func TestRenderComparisonText(t *testing.T) {
+	compared := []ComparedFinding{
+		{Finding: Finding{RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical, Locations: []Location{{FilePath: "key.pem", FromLine: 1}}}, State: StateNew},
+		{
+			Finding:           Finding{Title: "AWS Access Token", Severity: SeverityHigh, Locations: []Location{{FilePath: "app.env", FromLine: 9, FromColumn: 9}}},
+			State:             StateMoved,
+			PreviousLocations: []Location{{FilePath: "app.env", FromLine: 2, FromColumn: 9}},
+		},
+		{Finding: Finding{Title: "Generic API Key", Severity: SeverityLow}, State: StateUnchanged},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderComparisonText(&b, compared, ComparisonTextOptions{OldName: "v1.json", NewName: "v2.json"}))
+	text := b.String()
+
+	assert.Contains(t, text, "Comparing v1.json with v2.json")
+	assert.Contains(t, text, "New (1)\n [CRITICAL] Private Key (private-key)\n   key.pem:1\n")
+	assert.Contains(t, text, "Moved (1)\n [HIGH] AWS Access Token\n   app.env:2:9 → app.env:9:9\n")
+	assert.NotContains(t, text, "Fixed (")
+	assert.NotContains(t, text, "Generic API Key", "unchanged findings are only counted")
+	assert.Contains(t, text, "1 new, 0 fixed, 1 moved, 1 unchanged")
+	assert.NotContains(t, text, "No new secrets found.")
+}
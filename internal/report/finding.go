@@ -0,0 +1,218 @@
+// Package report turns the findings of a secrets test into the reports rendered for humans and other tools.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+)
+
+// Severities of findings, from the most to the least severe.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// Severities lists the severities of findings, from the most to the least severe.
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+
+// Finding is a secret found by a test, flattened from the test API model.
+type Finding struct {
+	// ID identifies the finding within its test.
+	ID string
+	// Key identifies the finding across tests.
+	Key string
+	// Fingerprint identifies the finding across tests by its secret, see Fingerprint. It is empty unless set by
+	// the caller.
+	Fingerprint string
+	RuleID      string
+	Title       string
+	Description string
+	Severity    string
+	// Ignored is set for findings suppressed by a policy or an ignore.
+	Ignored   bool
+	Locations []Location
+	// Owners are the CODEOWNERS owners of the locations. They are empty unless set by the caller.
+	Owners []string
+	// Blame is the commit that last changed each location. It is empty unless set by the caller.
+	Blame []Blame
+}
+
+// Blame is the commit that last changed a location of a finding.
+type Blame struct {
+	FilePath    string
+	FromLine    int
+	CommitSHA   string
+	AuthorEmail string
+	Timestamp   time.Time
+}
+
+// Location is where a secret was found in a file. Lines and columns start at 1, and ToColumn is exclusive.
+type Location struct {
+	// FilePath is the slash-separated path of the file, relative to the tested directory.
+	FilePath   string
+	FromLine   int
+	FromColumn int
+	ToLine     int
+	ToColumn   int
+}
+
+// String returns the location as path:line:column, as understood by editors and terminals.
+func (l Location) String() string {
+	if l.FromColumn > 0 {
+		return fmt.Sprintf("%s:%d:%d", l.FilePath, l.FromLine, l.FromColumn)
+	}
+	return fmt.Sprintf("%s:%d", l.FilePath, l.FromLine)
+}
+
+// FromTestAPI converts the findings of a test result, skipping locations that are not in source files.
+func FromTestAPI(findings []testapi.FindingData) []Finding {
+	result := make([]Finding, 0, len(findings))
+	for i := range findings {
+		attributes := findings[i].Attributes
+		if attributes == nil {
+			continue
+		}
+
+		finding := Finding{
+			Key:         attributes.Key,
+			RuleID:      ruleID(attributes.Problems),
+			Title:       attributes.Title,
+			Description: attributes.Description,
+			Severity:    string(attributes.Rating.Severity),
+			Ignored:     isIgnored(attributes.Suppression),
+		}
+		if findings[i].Id != nil {
+			finding.ID = findings[i].Id.String()
+		}
+		for _, loc := range attributes.Locations {
+			if sourceLoc, ok := sourceLocation(loc); ok {
+				finding.Locations = append(finding.Locations, sourceLoc)
+			}
+		}
+		result = append(result, finding)
+	}
+	return result
+}
+
+// ruleID returns the ID of the secrets rule among the problems of a finding.
+func ruleID(problems []testapi.Problem) string {
+	for _, problem := range problems {
+		discriminator, err := problem.Discriminator()
+		if err != nil || discriminator != string(testapi.Secret) {
+			continue
+		}
+		rule, err := problem.AsSecretsRuleProblem()
+		if err == nil {
+			return rule.Id
+		}
+	}
+	return ""
+}
+
+func isIgnored(suppression *testapi.Suppression) bool {
+	if suppression == nil {
+		return false
+	}
+	return suppression.Status == testapi.SuppressionStatusIgnored || suppression.Status == testapi.SuppressionStatusPendingIgnoreApproval
+}
+
+func sourceLocation(loc testapi.FindingLocation) (Location, bool) {
+	discriminator, err := loc.Discriminator()
+	if err != nil || discriminator != string(testapi.SourceLocationTypeSource) {
+		return Location{}, false
+	}
+	sourceLoc, err := loc.AsSourceLocation()
+	if err != nil {
+		return Location{}, false
+	}
+
+	location := Location{FilePath: sourceLoc.FilePath, FromLine: sourceLoc.FromLine, ToLine: sourceLoc.FromLine}
+	if sourceLoc.FromColumn != nil {
+		location.FromColumn = *sourceLoc.FromColumn
+	}
+	if sourceLoc.ToLine != nil {
+		location.ToLine = *sourceLoc.ToLine
+	}
+	if sourceLoc.ToColumn != nil {
+		location.ToColumn = *sourceLoc.ToColumn
+	}
+	return location, true
+}
+
+// SeverityRank returns the rank of a severity, 0 being the most severe. Unknown severities rank last.
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(Severities)
+}
+
+// FilterBySeverity returns the findings at threshold or above. All findings are returned if threshold is empty.
+func FilterBySeverity(findings []Finding, threshold string) []Finding {
+	if threshold == "" {
+		return findings
+	}
+
+	var result []Finding
+	for i := range findings {
+		if SeverityRank(findings[i].Severity) <= SeverityRank(threshold) {
+			result = append(result, findings[i])
+		}
+	}
+	return result
+}
+
+// Summary counts findings by severity and rule.
+type Summary struct {
+	Total      int
+	Ignored    int
+	BySeverity map[string]int
+	// ByRule counts the findings of every rule, the rules with most findings first.
+	ByRule []RuleCount
+}
+
+// RuleCount counts the findings of a rule.
+type RuleCount struct {
+	RuleID string
+	Title  string
+	Count  int
+}
+
+// Summarize counts the findings that are not ignored by severity and rule.
+func Summarize(findings []Finding) Summary {
+	summary := Summary{BySeverity: map[string]int{}}
+	rules := map[string]*RuleCount{}
+	for i := range findings {
+		if findings[i].Ignored {
+			summary.Ignored++
+			continue
+		}
+		summary.Total++
+		summary.BySeverity[findings[i].Severity]++
+
+		rule, ok := rules[findings[i].RuleID]
+		if !ok {
+			rule = &RuleCount{RuleID: findings[i].RuleID, Title: findings[i].Title}
+			rules[findings[i].RuleID] = rule
+		}
+		rule.Count++
+	}
+
+	for _, rule := range rules {
+		summary.ByRule = append(summary.ByRule, *rule)
+	}
+	sort.Slice(summary.ByRule, func(i, j int) bool {
+		if summary.ByRule[i].Count != summary.ByRule[j].Count {
+			return summary.ByRule[i].Count > summary.ByRule[j].Count
+		}
+		return summary.ByRule[i].RuleID < summary.ByRule[j].RuleID
+	})
+	return summary
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/snyk/go-application-framework/pkg/apiclients/testapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadFindings(t *testing.T) []testapi.FindingData {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/finding.json")
+	require.NoError(t, err)
+	var findings []testapi.FindingData
+	require.NoError(t, json.Unmarshal(content, &findings))
+	return findings
+}
+
+func TestFromTestAPI(t *testing.T) {
+	findings := FromTestAPI(loadFindings(t))
+
+	require.Len(t, findings, 1)
+	assert.Equal(t, Finding{
+		ID:          "bdaa4c47-9609-435c-80ef-317586c3a97a",
+		Key:         "20e7fb6c-c576-59d2-b0fb-838472e5a9e0",
+		RuleID:      "private-key",
+		Title:       "Private Key",
+		Description: "Do not hardcode passwords or other secrets directly in the source code. Use a secure secret management system instead.",
+		Severity:    SeverityCritical,
+		Locations: []Location{
+			{FilePath: "gcp-credentials.json", FromLine: 7, FromColumn: 20, ToLine: 7, ToColumn: 1749},
+			{FilePath: "gcp-credentials.json", FromLine: 13, FromColumn: 26, ToLine: 13, ToColumn: 1755},
+		},
+	}, findings[0])
+	assert.Equal(t, "gcp-credentials.json:7:20", findings[0].Locations[0].String())
+}
+
+func TestFilterBySeverity(t *testing.T) {
+	findings := []Finding{{Severity: SeverityLow}, {Severity: SeverityHigh}, {Severity: SeverityCritical}, {Severity: SeverityMedium}}
+
+	assert.Len(t, FilterBySeverity(findings, ""), 4)
+	assert.Equal(t, []Finding{{Severity: SeverityHigh}, {Severity: SeverityCritical}}, FilterBySeverity(findings, SeverityHigh))
+	assert.Empty(t, FilterBySeverity([]Finding{{Severity: SeverityLow}}, SeverityCritical))
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]Finding{
+		{RuleID: "aws-access-token", Title: "AWS Access Token", Severity: SeverityHigh},
+		{RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical},
+		{RuleID: "aws-access-token", Title: "AWS Access Token", Severity: SeverityHigh},
+		{RuleID: "generic-api-key", Title: "Generic API Key", Severity: SeverityMedium, Ignored: true},
+	})
+
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, 1, summary.Ignored)
+	assert.Equal(t, map[string]int{SeverityCritical: 1, SeverityHigh: 2}, summary.BySeverity)
+	assert.Equal(t, []RuleCount{
+		{RuleID: "aws-access-token", Title: "AWS Access Token", Count: 2},
+		{RuleID: "private-key", Title: "Private Key", Count: 1},
+	}, summary.ByRule)
+}
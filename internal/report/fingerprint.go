@@ -0,0 +1,108 @@
+package report
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FingerprintKey is the key of the fingerprints of findings among the partialFingerprints of SARIF results.
+// Its version changes with the way fingerprints are computed.
+const FingerprintKey = "snykSecretsFingerprint/v1"
+
+// FingerprintOptions configures the fingerprints of findings.
+type FingerprintOptions struct {
+	// SourceDir is the directory the paths of findings are relative to. The secrets are read from its files, if set.
+	SourceDir string
+	// RootFolder is the slash-separated path of the tested directory within the repository, so that fingerprints
+	// do not depend on the directory tested.
+	RootFolder string
+	// Namespace keys the hash of the secrets, so that fingerprints only match within it and cannot be matched against
+	// precomputed hashes of known secrets. It is not secret, so fingerprints are only pseudonymous: whoever knows the
+	// namespace can check guesses of a secret, e.g. a weak password, against a fingerprint.
+	Namespace string
+}
+
+// Fingerprint returns a fingerprint identifying a finding across tests. It is derived from the rule of the
+// finding and the normalized path and keyed hash of the secret of every location, but not from lines or
+// columns, so that it is kept when a secret moves within its file. A finding found at several locations has a
+// single fingerprint. Secrets that cannot be read, e.g. as the file changed since the test, are represented by
+// the key of the finding.
+func Fingerprint(finding *Finding, opts FingerprintOptions) string {
+	parts := map[string]bool{}
+	for _, loc := range finding.Locations {
+		secret, err := readSecret(opts.SourceDir, loc)
+		if err != nil || secret == "" {
+			secret = "key:" + finding.Key
+		}
+		mac := hmac.New(sha256.New, []byte(opts.Namespace))
+		mac.Write([]byte(secret))
+		parts[normalizePath(repoPath(opts.RootFolder, loc.FilePath))+"\x00"+hex.EncodeToString(mac.Sum(nil))] = true
+	}
+	if len(parts) == 0 {
+		mac := hmac.New(sha256.New, []byte(opts.Namespace))
+		mac.Write([]byte("key:" + finding.Key))
+		parts[hex.EncodeToString(mac.Sum(nil))] = true
+	}
+
+	sorted := make([]string, 0, len(parts))
+	for part := range parts {
+		sorted = append(sorted, part)
+	}
+	sort.Strings(sorted)
+
+	sum := sha256.Sum256([]byte(finding.RuleID + "\x00" + strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizePath returns a clean slash-separated path without a leading ./ or /.
+func normalizePath(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	return strings.TrimPrefix(strings.TrimPrefix(p, "./"), "/")
+}
+
+// readSecret reads the secret at loc from the file under sourceDir.
+func readSecret(sourceDir string, loc Location) (string, error) {
+	if sourceDir == "" {
+		return "", fmt.Errorf("no source directory")
+	}
+	filePath := filepath.Join(sourceDir, filepath.FromSlash(loc.FilePath))
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer f.Close()
+
+	toLine := max(loc.ToLine, loc.FromLine)
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for number := 1; number <= toLine && scanner.Scan(); number++ {
+		if number < loc.FromLine {
+			continue
+		}
+		runes := []rune(strings.TrimRight(scanner.Text(), "\r"))
+		start, end := 0, len(runes)
+		if number == loc.FromLine && loc.FromColumn > 0 {
+			start = min(loc.FromColumn-1, len(runes))
+		}
+		if number == toLine && loc.ToColumn > 0 {
+			end = min(max(loc.ToColumn-1, start), len(runes))
+		}
+		lines = append(lines, string(runes[start:end]))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	if len(lines) != toLine-loc.FromLine+1 {
+		return "", fmt.Errorf("%s has no line %d", filePath, toLine)
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,214 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// GitLabSchemaVersion is the version of the GitLab secret detection report schema the report conforms to.
+	GitLabSchemaVersion = "15.2.1"
+	// GitLabSchemaURL is the location of the schema the report conforms to.
+	GitLabSchemaURL = "https://gitlab.com/gitlab-org/security-products/security-report-schemas/-/raw/v" +
+		GitLabSchemaVersion + "/dist/secret-detection-report-format.json"
+
+	// GitLabNoCommit is the commit of findings outside of a git repository, as GitLab's own analyzer reports it.
+	GitLabNoCommit = "0000000"
+
+	gitLabScannerID      = "snyk_secrets"
+	gitLabScannerName    = "Snyk Secrets"
+	gitLabVendorName     = "Snyk"
+	gitLabScannerURL     = "https://snyk.io"
+	gitLabIdentifierType = "snyk_secrets_rule_id"
+	gitLabTimeLayout     = "2006-01-02T15:04:05"
+	// gitLabMaxNameLength leaves room for the ellipsis of truncated names within the 255 characters of the schema
+	gitLabMaxNameLength = 254
+)
+
+// gitLabIDNamespace scopes the name-based UUIDs identifying the vulnerabilities of GitLab reports.
+var gitLabIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://snyk.io/secrets/gitlab-report"))
+
+// GitLabOptions configures the GitLab report.
+type GitLabOptions struct {
+	// ScannerVersion is the version of the CLI running the test.
+	ScannerVersion string
+	// CommitSHA is the commit the tested files were checked out at, GitLabNoCommit if unknown.
+	CommitSHA string
+	// PathPrefix is the slash-separated path of the tested directory within the repository, as GitLab links the
+	// files of vulnerabilities relative to the repository root.
+	PathPrefix string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+type gitLabReport struct {
+	Version         string                `json:"version"`
+	Schema          string                `json:"schema"`
+	Scan            gitLabScan            `json:"scan"`
+	Vulnerabilities []gitLabVulnerability `json:"vulnerabilities"`
+}
+
+type gitLabScan struct { //nolint:tagliatelle // the GitLab report schema uses snake_case
+	Analyzer           gitLabTool         `json:"analyzer"`
+	Scanner            gitLabTool         `json:"scanner"`
+	PrimaryIdentifiers []gitLabIdentifier `json:"primary_identifiers"`
+	Type               string             `json:"type"`
+	StartTime          string             `json:"start_time"`
+	EndTime            string             `json:"end_time"`
+	Status             string             `json:"status"`
+}
+
+type gitLabTool struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	URL     string       `json:"url"`
+	Version string       `json:"version"`
+	Vendor  gitLabVendor `json:"vendor"`
+}
+
+type gitLabVendor struct {
+	Name string `json:"name"`
+}
+
+type gitLabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type gitLabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Identifiers []gitLabIdentifier `json:"identifiers"`
+	Location    gitLabLocation     `json:"location"`
+}
+
+type gitLabLocation struct { //nolint:tagliatelle // the GitLab report schema uses snake_case
+	File      string       `json:"file"`
+	Commit    gitLabCommit `json:"commit"`
+	StartLine int          `json:"start_line"`
+	EndLine   int          `json:"end_line"`
+}
+
+type gitLabCommit struct {
+	SHA string `json:"sha"`
+}
+
+// RenderGitLab writes findings to w as a GitLab secret detection report, with a vulnerability for every location
+// of a finding. Ignored findings are left out.
+func RenderGitLab(w io.Writer, findings []Finding, opts GitLabOptions) error {
+	tool := gitLabTool{
+		ID:      gitLabScannerID,
+		Name:    gitLabScannerName,
+		URL:     gitLabScannerURL,
+		Version: opts.ScannerVersion,
+		Vendor:  gitLabVendor{Name: gitLabVendorName},
+	}
+	commit := gitLabCommit{SHA: opts.CommitSHA}
+	if commit.SHA == "" {
+		commit.SHA = GitLabNoCommit
+	}
+
+	r := gitLabReport{
+		Version: GitLabSchemaVersion,
+		Schema:  GitLabSchemaURL,
+		Scan: gitLabScan{
+			Analyzer:           tool,
+			Scanner:            tool,
+			PrimaryIdentifiers: []gitLabIdentifier{},
+			Type:               "secret_detection",
+			StartTime:          opts.StartTime.UTC().Format(gitLabTimeLayout),
+			EndTime:            opts.EndTime.UTC().Format(gitLabTimeLayout),
+			Status:             "success",
+		},
+		Vulnerabilities: []gitLabVulnerability{},
+	}
+
+	seenIdentifiers := map[string]bool{}
+	for i := range findings {
+		finding := &findings[i]
+		if finding.Ignored {
+			continue
+		}
+
+		identifier := gitLabRuleIdentifier(finding)
+		if !seenIdentifiers[identifier.Value] {
+			seenIdentifiers[identifier.Value] = true
+			r.Scan.PrimaryIdentifiers = append(r.Scan.PrimaryIdentifiers, identifier)
+		}
+
+		for _, loc := range finding.Locations {
+			if opts.PathPrefix != "" && opts.PathPrefix != "." {
+				loc.FilePath = path.Join(opts.PathPrefix, loc.FilePath)
+			}
+			r.Vulnerabilities = append(r.Vulnerabilities, gitLabVulnerability{
+				ID:          gitLabVulnerabilityID(finding, loc),
+				Name:        truncate(finding.Title, gitLabMaxNameLength),
+				Description: finding.Description,
+				Severity:    gitLabSeverity(finding.Severity),
+				Identifiers: []gitLabIdentifier{identifier},
+				Location: gitLabLocation{
+					File:      loc.FilePath,
+					Commit:    commit,
+					StartLine: loc.FromLine,
+					EndLine:   max(loc.ToLine, loc.FromLine),
+				},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to write GitLab report: %w", err)
+	}
+	return nil
+}
+
+// gitLabRuleIdentifier is the primary identifier of a finding, its rule, which GitLab groups vulnerabilities by.
+func gitLabRuleIdentifier(finding *Finding) gitLabIdentifier {
+	value := finding.RuleID
+	if value == "" {
+		value = finding.Title
+	}
+	return gitLabIdentifier{
+		Type:  gitLabIdentifierType,
+		Name:  truncate(fmt.Sprintf("%s rule %s", gitLabScannerName, value), gitLabMaxNameLength),
+		Value: value,
+	}
+}
+
+// gitLabVulnerabilityID derives the ID of a vulnerability from the key of its finding and its location, so that
+// GitLab recognizes the vulnerability in the reports of later pipelines.
+func gitLabVulnerabilityID(finding *Finding, loc Location) string {
+	key := finding.Key
+	if key == "" {
+		key = finding.RuleID
+	}
+	name := key + "\x00" + loc.FilePath + "\x00" + strconv.Itoa(loc.FromLine) + "\x00" + strconv.Itoa(loc.FromColumn)
+	return uuid.NewSHA1(gitLabIDNamespace, []byte(name)).String()
+}
+
+// gitLabSeverity maps a severity to the capitalized severities of GitLab.
+func gitLabSeverity(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "Critical"
+	case SeverityHigh:
+		return "High"
+	case SeverityMedium:
+		return "Medium"
+	case SeverityLow:
+		return "Low"
+	default:
+		return "Unknown"
+	}
+}
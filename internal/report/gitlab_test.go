@@ -0,0 +1,131 @@
+package report
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// requireValidGitLabReport validates a report against the vendored GitLab secret detection report schema.
+func requireValidGitLabReport(t *testing.T, report string) {
+	t.Helper()
+
+	schemaPath, err := filepath.Abs("testdata/secret-detection-report-format.json")
+	require.NoError(t, err)
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewReferenceLoader("file://"+filepath.ToSlash(schemaPath)),
+		gojsonschema.NewStringLoader(report),
+	)
+	require.NoError(t, err)
+	for _, e := range result.Errors() {
+		t.Errorf("invalid GitLab report: %s", e)
+	}
+}
+
+func TestRenderGitLab(t *testing.T) {
+	findings := []Finding{
+		{
+			Key: "key-1", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical, Description: "Do not hardcode keys.",
+			Locations: []Location{
+				{FilePath: "config/gcp.json", FromLine: 7, FromColumn: 20, ToLine: 9, ToColumn: 4},
+				{FilePath: "main.go", FromLine: 3},
+			},
+		},
+		{Key: "key-2", RuleID: "aws-access-token", Title: strings.Repeat("A", 300), Severity: "unknown", Locations: []Location{{FilePath: "aws.env", FromLine: 1}}},
+		{Key: "key-3", RuleID: "private-key", Title: "Private Key", Severity: SeverityLow, Ignored: true, Locations: []Location{{FilePath: "ignored.pem", FromLine: 1}}},
+	}
+	opts := GitLabOptions{
+		ScannerVersion: "1.1300.0",
+		CommitSHA:      "4f2a9c1",
+		StartTime:      time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2026, 3, 1, 10, 2, 30, 0, time.UTC),
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderGitLab(&b, findings, opts))
+	requireValidGitLabReport(t, b.String())
+
+	var report gitLabReport
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &report))
+	assert.Equal(t, GitLabSchemaVersion, report.Version)
+	assert.Equal(t, "secret_detection", report.Scan.Type)
+	assert.Equal(t, "2026-03-01T10:00:00", report.Scan.StartTime)
+	assert.Equal(t, "2026-03-01T10:02:30", report.Scan.EndTime)
+	assert.Equal(t, "1.1300.0", report.Scan.Scanner.Version)
+	assert.Len(t, report.Scan.PrimaryIdentifiers, 2)
+
+	require.Len(t, report.Vulnerabilities, 3, "one vulnerability per location, ignored findings are left out")
+	assert.Equal(t, gitLabVulnerability{
+		ID:          report.Vulnerabilities[0].ID,
+		Name:        "Private Key",
+		Description: "Do not hardcode keys.",
+		Severity:    "Critical",
+		Identifiers: []gitLabIdentifier{{Type: gitLabIdentifierType, Name: "Snyk Secrets rule private-key", Value: "private-key"}},
+		Location:    gitLabLocation{File: "config/gcp.json", Commit: gitLabCommit{SHA: "4f2a9c1"}, StartLine: 7, EndLine: 9},
+	}, report.Vulnerabilities[0])
+	assert.Equal(t, gitLabLocation{File: "main.go", Commit: gitLabCommit{SHA: "4f2a9c1"}, StartLine: 3, EndLine: 3}, report.Vulnerabilities[1].Location)
+	assert.NotEqual(t, report.Vulnerabilities[0].ID, report.Vulnerabilities[1].ID)
+	assert.Equal(t, "Unknown", report.Vulnerabilities[2].Severity)
+	assert.Len(t, []rune(report.Vulnerabilities[2].Name), 255)
+}
+
+func TestRenderGitLab_StableIDs(t *testing.T) {
+	findings := []Finding{{Key: "key-1", RuleID: "private-key", Title: "Private Key", Locations: []Location{{FilePath: "a.pem", FromLine: 1}}}}
+
+	var first, second strings.Builder
+	require.NoError(t, RenderGitLab(&first, findings, GitLabOptions{ScannerVersion: "1", StartTime: time.Now()}))
+	require.NoError(t, RenderGitLab(&second, findings, GitLabOptions{ScannerVersion: "2", StartTime: time.Now().Add(time.Hour)}))
+
+	var a, b gitLabReport
+	require.NoError(t, json.Unmarshal([]byte(first.String()), &a))
+	require.NoError(t, json.Unmarshal([]byte(second.String()), &b))
+	assert.Equal(t, a.Vulnerabilities[0].ID, b.Vulnerabilities[0].ID)
+	assert.Equal(t, GitLabNoCommit, a.Vulnerabilities[0].Location.Commit.SHA, "files outside of a git repository have no commit")
+}
+
+func TestRenderGitLab_PathPrefix(t *testing.T) {
+	findings := []Finding{{Key: "key-1", RuleID: "private-key", Title: "Private Key", Locations: []Location{{FilePath: "config/a.pem", FromLine: 1}}}}
+
+	var inRepo, inSubdir strings.Builder
+	require.NoError(t, RenderGitLab(&inRepo, findings, GitLabOptions{PathPrefix: "."}))
+	require.NoError(t, RenderGitLab(&inSubdir, findings, GitLabOptions{PathPrefix: "services/api"}))
+
+	var a, b gitLabReport
+	require.NoError(t, json.Unmarshal([]byte(inRepo.String()), &a))
+	require.NoError(t, json.Unmarshal([]byte(inSubdir.String()), &b))
+	assert.Equal(t, "config/a.pem", a.Vulnerabilities[0].Location.File)
+	assert.Equal(t, "services/api/config/a.pem", b.Vulnerabilities[0].Location.File)
+	assert.NotEqual(t, a.Vulnerabilities[0].ID, b.Vulnerabilities[0].ID, "IDs are derived from the path within the repository")
+}
+
+func TestRenderGitLab_NoFindings(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderGitLab(&b, nil, GitLabOptions{ScannerVersion: "1.1300.0"}))
+	requireValidGitLabReport(t, b.String())
+
+	var report gitLabReport
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &report))
+	assert.Empty(t, report.Vulnerabilities)
+	assert.Contains(t, b.String(), `"vulnerabilities": []`)
+}
+
+func TestGitLabSchema_RejectsInvalidReport(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderGitLab(&b, []Finding{{RuleID: "private-key", Locations: []Location{{FilePath: "a.pem", FromLine: 1}}}}, GitLabOptions{ScannerVersion: "1"}))
+	invalid := strings.Replace(b.String(), `"severity": "Unknown"`, `"severity": "unknown"`, 1)
+
+	schemaPath, err := filepath.Abs("testdata/secret-detection-report-format.json")
+	require.NoError(t, err)
+	result, err := gojsonschema.Validate(
+		gojsonschema.NewReferenceLoader("file://"+filepath.ToSlash(schemaPath)),
+		gojsonschema.NewStringLoader(invalid),
+	)
+	require.NoError(t, err)
+	assert.False(t, result.Valid(), "the schema only accepts the capitalized severities of GitLab")
+}
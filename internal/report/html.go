@@ -0,0 +1,110 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed html
+var htmlAssets embed.FS
+
+// htmlTemplate is the page of the HTML report, with its stylesheet and script inlined so that it fetches nothing.
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"repopath": repoPath,
+}).ParseFS(htmlAssets, "html/report.html.tmpl"))
+
+// HTMLOptions configures the HTML report.
+type HTMLOptions struct {
+	// SourceDir is the directory the paths of findings are relative to. Snippets are only shown if it is set.
+	SourceDir string
+	// ContextLines is the number of lines shown before and after the lines of a secret.
+	ContextLines int
+	SCM          SCMContext
+	// ReportURL is the project page the results were shared to, if any.
+	ReportURL   string
+	GeneratedAt time.Time
+}
+
+type htmlPage struct {
+	SCM         SCMContext
+	ReportURL   string
+	GeneratedAt string
+	Summary     Summary
+	Severities  []htmlSeverityCount
+	Rows        []htmlRow
+	CSS         template.CSS
+	JS          template.JS
+}
+
+type htmlSeverityCount struct {
+	Severity string
+	Count    int
+}
+
+// htmlRow is a location of a finding, the unit the findings table lists.
+type htmlRow struct {
+	Finding  *Finding
+	Location Location
+	Rank     int
+	Snippet  []SnippetLine
+}
+
+// RenderHTML writes findings to w as a self-contained HTML page with a severity summary and a table of their
+// locations, which can be filtered and sorted in the browser. Ignored findings are listed, but hidden by default.
+func RenderHTML(w io.Writer, findings []Finding, opts HTMLOptions) error {
+	css, err := htmlAssets.ReadFile("html/report.css")
+	if err != nil {
+		return fmt.Errorf("failed to read HTML report stylesheet: %w", err)
+	}
+	js, err := htmlAssets.ReadFile("html/report.js")
+	if err != nil {
+		return fmt.Errorf("failed to read HTML report script: %w", err)
+	}
+
+	page := htmlPage{
+		SCM:         opts.SCM,
+		ReportURL:   opts.ReportURL,
+		GeneratedAt: opts.GeneratedAt.UTC().Format(time.RFC3339),
+		Summary:     Summarize(findings),
+		CSS:         template.CSS(css), //nolint:gosec // embedded in the binary
+		JS:          template.JS(js),   //nolint:gosec // embedded in the binary
+	}
+	for _, severity := range Severities {
+		page.Severities = append(page.Severities, htmlSeverityCount{Severity: severity, Count: page.Summary.BySeverity[severity]})
+	}
+
+	for i := range findings {
+		for _, loc := range findings[i].Locations {
+			row := htmlRow{Finding: &findings[i], Location: loc, Rank: SeverityRank(findings[i].Severity)}
+			if opts.SourceDir != "" {
+				// a file changed or removed since the test is listed without its snippet
+				row.Snippet, _ = Snippet(opts.SourceDir, loc, opts.ContextLines)
+			}
+			page.Rows = append(page.Rows, row)
+		}
+	}
+	sort.SliceStable(page.Rows, func(i, j int) bool {
+		a, b := page.Rows[i], page.Rows[j]
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		if a.Location.FilePath != b.Location.FilePath {
+			return a.Location.FilePath < b.Location.FilePath
+		}
+		return a.Location.FromLine < b.Location.FromLine
+	})
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, page); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
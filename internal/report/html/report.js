@@ -0,0 +1,71 @@
+(function () {
+  "use strict";
+
+  var table = document.getElementById("findings");
+  if (!table) {
+    return;
+  }
+  var rows = Array.prototype.slice.call(table.tBodies);
+  var severityFilters = Array.prototype.slice.call(document.querySelectorAll(".severity-filter"));
+  var showIgnored = document.getElementById("show-ignored");
+  var search = document.getElementById("search");
+  var noMatches = document.getElementById("no-matches");
+
+  function filter() {
+    var severities = {};
+    severityFilters.forEach(function (input) {
+      severities[input.value] = input.checked;
+    });
+    var query = search.value.trim().toLowerCase();
+
+    var visible = 0;
+    rows.forEach(function (row) {
+      var d = row.dataset;
+      var show = severities[d.severity] !== false &&
+        (showIgnored.checked || !row.classList.contains("ignored")) &&
+        (query === "" || [d.path, d.rule, d.title].join("\n").toLowerCase().indexOf(query) !== -1);
+      row.hidden = !show;
+      if (show) {
+        visible++;
+      }
+    });
+    noMatches.hidden = visible !== 0;
+  }
+
+  function compare(key, a, b) {
+    if (key === "rank") {
+      return Number(a.dataset.rank) - Number(b.dataset.rank);
+    }
+    return a.dataset[key].localeCompare(b.dataset[key]);
+  }
+
+  function sortBy(header) {
+    var key = header.dataset.sort;
+    var direction = header.getAttribute("aria-sort") === "ascending" ? -1 : 1;
+    table.querySelectorAll("th").forEach(function (th) {
+      th.removeAttribute("aria-sort");
+    });
+    header.setAttribute("aria-sort", direction === 1 ? "ascending" : "descending");
+
+    rows.sort(function (a, b) {
+      return direction * compare(key, a, b) ||
+        a.dataset.path.localeCompare(b.dataset.path) ||
+        Number(a.dataset.line) - Number(b.dataset.line);
+    });
+    rows.forEach(function (row) {
+      table.appendChild(row);
+    });
+  }
+
+  severityFilters.forEach(function (input) {
+    input.addEventListener("change", filter);
+  });
+  showIgnored.addEventListener("change", filter);
+  search.addEventListener("input", filter);
+  table.querySelectorAll("th[data-sort]").forEach(function (th) {
+    th.addEventListener("click", function () {
+      sortBy(th);
+    });
+  });
+  filter();
+})();
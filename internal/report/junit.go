@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// JUnitSuiteName is the name of the test suite of JUnit reports.
+const JUnitSuiteName = "Snyk Secrets"
+
+// JUnitOptions configures the JUnit report.
+type JUnitOptions struct {
+	// PassingFiles are the slash-separated paths of the tested files. Those without findings are reported
+	// as passing test cases.
+	PassingFiles []string
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	File      string         `xml:"file,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// RenderJUnit writes findings to w as a JUnit XML report, in which every file with findings is a test case and
+// every finding in it a failure. Ignored findings are left out.
+func RenderJUnit(w io.Writer, findings []Finding, opts JUnitOptions) error {
+	failures := map[string][]junitFailure{}
+	for i := range findings {
+		if findings[i].Ignored {
+			continue
+		}
+		for _, path := range findingFiles(&findings[i]) {
+			failures[path] = append(failures[path], junitFindingFailure(&findings[i], path))
+		}
+	}
+
+	paths := make([]string, 0, len(failures)+len(opts.PassingFiles))
+	for path := range failures {
+		paths = append(paths, path)
+	}
+	for _, path := range opts.PassingFiles {
+		if _, ok := failures[path]; !ok {
+			paths = append(paths, path)
+			failures[path] = nil
+		}
+	}
+	sort.Strings(paths)
+
+	suite := junitTestSuite{Name: JUnitSuiteName, TestCases: make([]junitTestCase, 0, len(paths))}
+	for _, path := range paths {
+		suite.TestCases = append(suite.TestCases, junitTestCase{ClassName: junitClassName(path), Name: path, File: path, Failures: failures[path]})
+		suite.Tests++
+		suite.Failures += len(failures[path])
+	}
+	suites := junitTestSuites{Name: JUnitSuiteName, Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// findingFiles returns the files a finding has locations in, without duplicates.
+func findingFiles(finding *Finding) []string {
+	var paths []string
+	for _, loc := range finding.Locations {
+		if !slices.Contains(paths, loc.FilePath) {
+			paths = append(paths, loc.FilePath)
+		}
+	}
+	return paths
+}
+
+// junitFindingFailure describes a finding in the file at path, listing its locations in the file.
+func junitFindingFailure(finding *Finding, path string) junitFailure {
+	var locations []string
+	for _, loc := range finding.Locations {
+		if loc.FilePath == path {
+			locations = append(locations, loc.String())
+		}
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Rule: %s\n", finding.RuleID)
+	fmt.Fprintf(&text, "Severity: %s\n", finding.Severity)
+	fmt.Fprintf(&text, "Location: %s\n", strings.Join(locations, ", "))
+	if finding.Description != "" {
+		fmt.Fprintf(&text, "\n%s\n", finding.Description)
+	}
+
+	return junitFailure{
+		Message: fmt.Sprintf("[%s] %s at %s", finding.Severity, finding.Title, locations[0]),
+		Type:    finding.RuleID,
+		Text:    text.String(),
+	}
+}
+
+// junitClassName returns the dotted directory of path, which CI dashboards group test cases by.
+func junitClassName(path string) string {
+	dir := path[:max(strings.LastIndex(path, "/"), 0)]
+	if dir == "" {
+		return "secrets"
+	}
+	return "secrets." + strings.ReplaceAll(dir, "/", ".")
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderJUnit(t *testing.T) {
+	findings := []Finding{
+		{
+			RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical, Description: "Do not hardcode keys.",
+			Locations: []Location{
+				{FilePath: "config/gcp.json", FromLine: 7, FromColumn: 20},
+				{FilePath: "config/gcp.json", FromLine: 13, FromColumn: 26},
+			},
+		},
+		{
+			RuleID: "aws-access-token", Title: "AWS Access Token", Severity: SeverityHigh,
+			Locations: []Location{{FilePath: "config/gcp.json", FromLine: 2}, {FilePath: "main.go", FromLine: 4, FromColumn: 9}},
+		},
+		{RuleID: "generic-api-key", Title: "Generic API Key", Severity: SeverityLow, Ignored: true, Locations: []Location{{FilePath: "ignored.go", FromLine: 1}}},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderJUnit(&b, findings, JUnitOptions{PassingFiles: []string{"README.md", "main.go", "config/gcp.json"}}))
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="Snyk Secrets" tests="3" failures="3">
+  <testsuite name="Snyk Secrets" tests="3" failures="3" errors="0" skipped="0">
+    <testcase classname="secrets" name="README.md" file="README.md"></testcase>
+    <testcase classname="secrets.config" name="config/gcp.json" file="config/gcp.json">
+      <failure message="[critical] Private Key at config/gcp.json:7:20" type="private-key">Rule: private-key&#xA;Severity: critical&#xA;Location: config/gcp.json:7:20, config/gcp.json:13:26&#xA;&#xA;Do not hardcode keys.&#xA;</failure>
+      <failure message="[high] AWS Access Token at config/gcp.json:2" type="aws-access-token">Rule: aws-access-token&#xA;Severity: high&#xA;Location: config/gcp.json:2&#xA;</failure>
+    </testcase>
+    <testcase classname="secrets" name="main.go" file="main.go">
+      <failure message="[high] AWS Access Token at main.go:4:9" type="aws-access-token">Rule: aws-access-token&#xA;Severity: high&#xA;Location: main.go:4:9&#xA;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, want, b.String())
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(b.String()), &parsed))
+	assert.Equal(t, "Rule: private-key\nSeverity: critical\nLocation: config/gcp.json:7:20, config/gcp.json:13:26\n\nDo not hardcode keys.\n",
+		parsed.Suites[0].TestCases[1].Failures[0].Text)
+}
+
+func TestRenderJUnit_NoFindings(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderJUnit(&b, nil, JUnitOptions{}))
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(b.String()), &parsed))
+	assert.Equal(t, 0, parsed.Tests)
+	require.Len(t, parsed.Suites, 1)
+	assert.Empty(t, parsed.Suites[0].TestCases)
+}
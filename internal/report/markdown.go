@@ -0,0 +1,92 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// MaxSummaryRows is the number of finding locations listed in the markdown summary, which CI systems limit in size.
+const MaxSummaryRows = 100
+
+// RenderMarkdownSummary writes the markdown summary of findings to w, as shown on the summary page of a CI run:
+// the number of findings by severity and a table of their locations. Ignored findings are only counted.
+func RenderMarkdownSummary(w io.Writer, findings []Finding) error {
+	summary := Summarize(findings)
+
+	var b strings.Builder
+	b.WriteString("## Snyk Secrets\n\n")
+	if summary.Total == 0 {
+		b.WriteString("No secrets found.\n")
+	} else {
+		var severities []string
+		for _, severity := range Severities {
+			if count := summary.BySeverity[severity]; count > 0 {
+				severities = append(severities, fmt.Sprintf("%d %s", count, severity))
+			}
+		}
+		fmt.Fprintf(&b, "**%d %s found:** %s\n\n", summary.Total, plural(summary.Total, "secret", "secrets"), strings.Join(severities, ", "))
+		writeMarkdownTable(&b, findings)
+	}
+	if summary.Ignored > 0 {
+		fmt.Fprintf(&b, "\n%d ignored %s not shown.\n", summary.Ignored, plural(summary.Ignored, "finding", "findings"))
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write markdown summary: %w", err)
+	}
+	return nil
+}
+
+func writeMarkdownTable(b *strings.Builder, findings []Finding) {
+	var entries []textEntry
+	for i := range findings {
+		if findings[i].Ignored {
+			continue
+		}
+		for _, loc := range findings[i].Locations {
+			entries = append(entries, textEntry{finding: &findings[i], location: loc})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		ri, rj := SeverityRank(entries[i].finding.Severity), SeverityRank(entries[j].finding.Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		li, lj := entries[i].location, entries[j].location
+		if li.FilePath != lj.FilePath {
+			return li.FilePath < lj.FilePath
+		}
+		if li.FromLine != lj.FromLine {
+			return li.FromLine < lj.FromLine
+		}
+		return li.FromColumn < lj.FromColumn
+	})
+
+	b.WriteString("| Severity | Secret | Rule | Location |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for i, entry := range entries {
+		if i == MaxSummaryRows {
+			fmt.Fprintf(b, "\nand %d more.\n", len(entries)-MaxSummaryRows)
+			break
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n",
+			escapeMarkdownCell(entry.finding.Severity),
+			escapeMarkdownCell(entry.finding.Title),
+			markdownCode(entry.finding.RuleID),
+			markdownCode(entry.location.String()),
+		)
+	}
+}
+
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(escapeMarkdownCell(text), "`", "'") + "`"
+}
+
+func escapeMarkdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ").Replace(text)
+}
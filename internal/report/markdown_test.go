@@ -0,0 +1,42 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdownSummary(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderMarkdownSummary(&b, annotationFindings()))
+
+	assert.Equal(t, "## Snyk Secrets\n\n"+
+		"**2 secrets found:** 1 critical, 1 low\n\n"+
+		"| Severity | Secret | Rule | Location |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| critical | Private Key | `private-key` | `config/gcp.json:7:20` |\n"+
+		"| low | Generic API Key | `generic-api-key` | `a,b.env:2:1` |\n"+
+		"\n1 ignored finding not shown.\n",
+		b.String())
+}
+
+func TestRenderMarkdownSummary_NoFindings(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, RenderMarkdownSummary(&b, nil))
+	assert.Equal(t, "## Snyk Secrets\n\nNo secrets found.\n", b.String())
+}
+
+func TestRenderMarkdownSummary_LimitsRows(t *testing.T) {
+	finding := Finding{RuleID: "generic-api-key", Title: "Generic | API Key", Severity: SeverityMedium}
+	for i := range MaxSummaryRows + 5 {
+		finding.Locations = append(finding.Locations, Location{FilePath: fmt.Sprintf("file%03d.env", i), FromLine: 1})
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderMarkdownSummary(&b, []Finding{finding}))
+	assert.Equal(t, MaxSummaryRows, strings.Count(b.String(), "| medium | Generic \\| API Key |"))
+	assert.Contains(t, b.String(), "\nand 5 more.\n")
+}
@@ -0,0 +1,267 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+const (
+	sarifSchemaURL  = "https://docs.oasis-open.org/sarif/sarif/v2.1.0/errata01/os/schemas/sarif-schema-2.1.0.json"
+	sarifVersion    = "2.1.0"
+	sarifDriverName = "Snyk Secrets"
+	sarifInfoURI    = "https://docs.snyk.io/"
+)
+
+// securitySeverities are the scores code scanning tools rank SARIF results by.
+var securitySeverities = map[string]string{
+	SeverityCritical: "9.0",
+	SeverityHigh:     "7.0",
+	SeverityMedium:   "5.0",
+	SeverityLow:      "3.0",
+}
+
+// SARIFOptions configures the SARIF report.
+type SARIFOptions struct {
+	// ToolVersion is the version of the CLI running the test.
+	ToolVersion string
+	// Incomplete marks the run as unsuccessful, as not all findings could be retrieved.
+	Incomplete bool
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name            string      `json:"name"`
+	SemanticVersion string      `json:"semanticVersion,omitempty"`
+	Version         string      `json:"version,omitempty"`
+	InformationURI  string      `json:"informationUri"`
+	Rules           []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	FullDescription      *sarifMessage       `json:"fullDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool `json:"executionSuccessful"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                 `json:"ruleId"`
+	Level               string                 `json:"level"`
+	Message             sarifMessage           `json:"message"`
+	Locations           []sarifLocation        `json:"locations"`
+	Fingerprints        map[string]string      `json:"fingerprints,omitempty"`
+	PartialFingerprints map[string]string      `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression     `json:"suppressions,omitempty"`
+	BaselineState       string                 `json:"baselineState,omitempty"`
+	Properties          *sarifResultProperties `json:"properties,omitempty"`
+}
+
+// sarifResultProperties carry the owners and blame of a finding, which SARIF has no dedicated fields for.
+type sarifResultProperties struct {
+	Owners []string     `json:"owners,omitempty"`
+	Blame  []sarifBlame `json:"blame,omitempty"`
+}
+
+type sarifBlame struct {
+	FilePath    string    `json:"filePath"`
+	FromLine    int       `json:"startLine"`
+	CommitSHA   string    `json:"commitSha"`
+	AuthorEmail string    `json:"authorEmail"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+}
+
+// RenderSARIF writes findings to w as a SARIF 2.1.0 log with a result for every finding. The fingerprint of a
+// finding is its partial fingerprint under FingerprintKey, which code scanning tools match results across runs by.
+// The owners and blame of a finding are the properties of its result.
+func RenderSARIF(w io.Writer, findings []Finding, opts SARIFOptions) error {
+	run := newSARIFRun(opts)
+	for i := range findings {
+		run.addResult(&findings[i])
+	}
+	return writeSARIF(w, run)
+}
+
+// sarifBaselineStates maps the states of compared findings to the baseline states of SARIF results.
+var sarifBaselineStates = map[string]string{
+	StateNew:       "new",
+	StateFixed:     "absent",
+	StateMoved:     "updated",
+	StateUnchanged: "unchanged",
+}
+
+// RenderComparisonSARIF writes compared findings to w as a SARIF 2.1.0 log like RenderSARIF, with the state of
+// every finding as the baselineState of its result.
+func RenderComparisonSARIF(w io.Writer, findings []ComparedFinding, opts SARIFOptions) error {
+	run := newSARIFRun(opts)
+	for i := range findings {
+		run.addResult(&findings[i].Finding).BaselineState = sarifBaselineStates[findings[i].State]
+	}
+	return writeSARIF(w, run)
+}
+
+func newSARIFRun(opts SARIFOptions) *sarifRun {
+	return &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:            sarifDriverName,
+			SemanticVersion: opts.ToolVersion,
+			Version:         opts.ToolVersion,
+			InformationURI:  sarifInfoURI,
+			Rules:           []sarifRule{},
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: !opts.Incomplete}},
+		Results:     []sarifResult{},
+	}
+}
+
+// addResult adds the result of a finding to the run, along with its rule if not listed yet.
+func (run *sarifRun) addResult(finding *Finding) *sarifResult {
+	rule := sarifRuleFor(finding)
+	if !slices.ContainsFunc(run.Tool.Driver.Rules, func(r sarifRule) bool { return r.ID == rule.ID }) {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+
+	result := sarifResult{
+		RuleID:    rule.ID,
+		Level:     sarifLevel(finding.Severity),
+		Message:   sarifMessage{Text: fmt.Sprintf("%s found, a %s severity secret.", finding.Title, finding.Severity)},
+		Locations: []sarifLocation{},
+	}
+	for _, loc := range finding.Locations {
+		result.Locations = append(result.Locations, sarifLocationOf(loc))
+	}
+	if finding.Key != "" {
+		result.Fingerprints = map[string]string{"identity": finding.Key}
+	}
+	if finding.Fingerprint != "" {
+		result.PartialFingerprints = map[string]string{FingerprintKey: finding.Fingerprint}
+	}
+	if len(finding.Owners) > 0 || len(finding.Blame) > 0 {
+		result.Properties = &sarifResultProperties{Owners: finding.Owners}
+		for _, b := range finding.Blame {
+			result.Properties.Blame = append(result.Properties.Blame, sarifBlame(b))
+		}
+	}
+	if finding.Ignored {
+		result.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted"}}
+	}
+	run.Results = append(run.Results, result)
+	return &run.Results[len(run.Results)-1]
+}
+
+func writeSARIF(w io.Writer, run *sarifRun) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{Schema: sarifSchemaURL, Version: sarifVersion, Runs: []sarifRun{*run}}); err != nil {
+		return fmt.Errorf("failed to write SARIF report: %w", err)
+	}
+	return nil
+}
+
+func sarifRuleFor(finding *Finding) sarifRule {
+	id := finding.RuleID
+	if id == "" {
+		id = finding.Title
+	}
+	rule := sarifRule{
+		ID:                   id,
+		Name:                 finding.Title,
+		ShortDescription:     sarifMessage{Text: finding.Title},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)},
+		Properties: sarifRuleProperties{
+			Tags:             []string{"security", "secret"},
+			SecuritySeverity: securitySeverities[finding.Severity],
+		},
+	}
+	if finding.Description != "" {
+		rule.FullDescription = &sarifMessage{Text: finding.Description}
+	}
+	return rule
+}
+
+func sarifLocationOf(loc Location) sarifLocation {
+	region := sarifRegion{StartLine: loc.FromLine, StartColumn: loc.FromColumn}
+	if loc.ToLine > 0 {
+		region.EndLine = loc.ToLine
+	}
+	if loc.ToColumn > 0 {
+		region.EndColumn = loc.ToColumn
+	}
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: loc.FilePath},
+		Region:           region,
+	}}
+}
+
+// sarifLevel maps a severity to the level of SARIF results, as the CLI does.
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityCritical, SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
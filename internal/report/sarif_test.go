@@ -0,0 +1,87 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSARIF(t *testing.T) {
+	findings := []Finding{
+		{
+			Key: "key-1", Fingerprint: "fp-1", RuleID: "private-key", Title: "Private Key", Description: "A private key.",
+			Severity: SeverityCritical,
+			Locations: []Location{
+				{FilePath: "gcp-credentials.json", FromLine: 7, FromColumn: 20, ToLine: 7, ToColumn: 50},
+				{FilePath: "gcp-credentials.json", FromLine: 13, FromColumn: 26},
+			},
+			Owners: []string{"@security"},
+			Blame: []Blame{
+				{FilePath: "gcp-credentials.json", FromLine: 7, CommitSHA: "4f2a9c1e", AuthorEmail: "dev@example.com", Timestamp: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)},
+			},
+		},
+		{Key: "key-2", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical, Locations: []Location{{FilePath: "a.pem", FromLine: 1}}},
+		{Key: "key-3", RuleID: "generic-api-key", Title: "Generic API Key", Severity: SeverityMedium, Ignored: true},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderSARIF(&b, findings, SARIFOptions{ToolVersion: "1.1300.0", Incomplete: true}))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	assert.Equal(t, "1.1300.0", run.Tool.Driver.Version)
+	require.Len(t, run.Tool.Driver.Rules, 2, "rules are listed once")
+	assert.Equal(t, "private-key", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "9.0", run.Tool.Driver.Rules[0].Properties.SecuritySeverity)
+	assert.Equal(t, []sarifInvocation{{ExecutionSuccessful: false}}, run.Invocations)
+
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, map[string]string{FingerprintKey: "fp-1"}, run.Results[0].PartialFingerprints)
+	assert.Equal(t, map[string]string{"identity": "key-1"}, run.Results[0].Fingerprints)
+	require.Len(t, run.Results[0].Locations, 2)
+	assert.Equal(t, sarifRegion{StartLine: 7, StartColumn: 20, EndLine: 7, EndColumn: 50}, run.Results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, "gcp-credentials.json", run.Results[0].Locations[1].PhysicalLocation.ArtifactLocation.URI)
+
+	require.NotNil(t, run.Results[0].Properties)
+	assert.Equal(t, []string{"@security"}, run.Results[0].Properties.Owners)
+	assert.Equal(t, []sarifBlame{
+		{FilePath: "gcp-credentials.json", FromLine: 7, CommitSHA: "4f2a9c1e", AuthorEmail: "dev@example.com", Timestamp: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)},
+	}, run.Results[0].Properties.Blame)
+
+	assert.Nil(t, run.Results[1].PartialFingerprints, "findings without a fingerprint have no partial fingerprints")
+	assert.Nil(t, run.Results[1].Properties, "findings without owners or blame have no properties")
+	assert.Equal(t, "warning", run.Results[2].Level)
+	assert.Equal(t, []sarifSuppression{{Kind: "external", Status: "accepted"}}, run.Results[2].Suppressions)
+	assert.Empty(t, run.Results[2].Locations)
+}
+
+func TestRenderComparisonSARIF(t *testing.T) {
+	compared := []ComparedFinding{
+		{Finding: Finding{Key: "key-1", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical}, State: StateNew},
+		{Finding: Finding{Key: "key-2", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical}, State: StateFixed},
+		{Finding: Finding{Key: "key-3", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical}, State: StateMoved},
+		{Finding: Finding{Key: "key-4", RuleID: "private-key", Title: "Private Key", Severity: SeverityCritical}, State: StateUnchanged},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderComparisonSARIF(&b, compared, SARIFOptions{}))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(b.String()), &log))
+	require.Len(t, log.Runs, 1)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, 1)
+	states := make([]string, 0, len(log.Runs[0].Results))
+	for _, result := range log.Runs[0].Results {
+		states = append(states, result.BaselineState)
+	}
+	assert.Equal(t, []string{"new", "absent", "updated", "unchanged"}, states)
+}
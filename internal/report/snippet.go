@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultContextLines is the number of lines shown before and after the lines of a secret.
+	DefaultContextLines = 2
+	// MaxSnippetLineLength is the number of characters of a line shown in a snippet.
+	MaxSnippetLineLength = 160
+
+	maskRunes       = "****"
+	maxVisibleRunes = 4
+)
+
+// SnippetLine is a line of code around a secret, with the secret masked.
+type SnippetLine struct {
+	Number int
+	Text   string
+	// Secret marks the lines holding the secret.
+	Secret bool
+}
+
+// Snippet reads the lines of the secret at loc and contextLines lines around it from the file under sourceDir,
+// masking the secret.
+func Snippet(sourceDir string, loc Location, contextLines int) ([]SnippetLine, error) {
+	path := filepath.Join(sourceDir, filepath.FromSlash(loc.FilePath))
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	toLine := max(loc.ToLine, loc.FromLine)
+	first, last := max(1, loc.FromLine-contextLines), toLine+contextLines
+
+	var lines []SnippetLine
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for number := 1; number <= last && scanner.Scan(); number++ {
+		if number < first {
+			continue
+		}
+		line := SnippetLine{Number: number, Text: strings.TrimRight(scanner.Text(), "\r")}
+		if number >= loc.FromLine && number <= toLine {
+			line.Secret = true
+			line.Text = maskLine(line.Text, number, loc)
+		}
+		line.Text = truncate(line.Text, MaxSnippetLineLength)
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s has no line %d", path, loc.FromLine)
+	}
+	return lines, nil
+}
+
+// maskLine masks the part of the secret at loc on the given line. The first and last few characters of the
+// secret are kept on its first and last line, so that it can be recognized without being disclosed.
+func maskLine(text string, number int, loc Location) string {
+	runes := []rune(text)
+	toLine := max(loc.ToLine, loc.FromLine)
+
+	start, end := 0, len(runes)
+	if number == loc.FromLine && loc.FromColumn > 0 {
+		start = min(loc.FromColumn-1, len(runes))
+	}
+	if number == toLine && loc.ToColumn > 0 {
+		end = min(max(loc.ToColumn-1, start), len(runes))
+	}
+
+	secret := string(runes[start:end])
+	masked := maskPart(secret, number == loc.FromLine, number == toLine)
+	return string(runes[:start]) + masked + string(runes[end:])
+}
+
+// Mask masks a secret down to its first and last few characters. Short secrets are masked entirely.
+func Mask(secret string) string {
+	return maskPart(secret, true, true)
+}
+
+// maskPart masks a part of a secret, keeping its first characters if it starts the secret and its last characters
+// if it ends the secret. The mask has a fixed length, so that it does not disclose the length of the secret.
+func maskPart(part string, keepPrefix, keepSuffix bool) string {
+	runes := []rune(part)
+	if len(runes) == 0 {
+		return ""
+	}
+
+	visible := min(len(runes)/4, maxVisibleRunes)
+	var b strings.Builder
+	if keepPrefix {
+		b.WriteString(string(runes[:visible]))
+	}
+	b.WriteString(maskRunes)
+	if keepSuffix {
+		b.WriteString(string(runes[len(runes)-visible:]))
+	}
+	return b.String()
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length]) + "…"
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// BuiltinTemplates are the names of the output templates shipped with the CLI.
+var BuiltinTemplates = []string{"csv", "markdown"}
+
+// TemplateData is the data output templates are executed with.
+type TemplateData struct {
+	// Findings are the findings at the severity threshold or above, including ignored ones.
+	Findings []Finding
+	// Summary counts the findings that are not ignored.
+	Summary Summary
+	SCM     SCMContext
+	// ReportURL is the project page the results were shared to with --report, if any.
+	ReportURL string
+}
+
+// SCMContext describes the repository of the tested files. Its fields are empty outside of a git repository.
+type SCMContext struct {
+	RepoURL string
+	Branch  string
+	Commit  string
+	// RootFolder is the slash-separated path of the tested directory within the repository.
+	RootFolder string
+}
+
+// LoadTemplate parses the built-in template of the given name, or else the template file at the given path.
+func LoadTemplate(nameOrPath string) (*template.Template, error) {
+	var content []byte
+	var err error
+	if slices.Contains(BuiltinTemplates, nameOrPath) {
+		content, err = builtinTemplates.ReadFile("templates/" + nameOrPath + ".tmpl")
+	} else {
+		content, err = os.ReadFile(nameOrPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", nameOrPath, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(nameOrPath)).Funcs(TemplateFuncs()).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", nameOrPath, err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate executes tmpl with data and writes the result to w.
+func RenderTemplate(w io.Writer, tmpl *template.Template, data TemplateData) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("failed to execute template %s: %w", tmpl.Name(), err)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write template output: %w", err)
+	}
+	return nil
+}
+
+// TemplateFuncs returns the helper functions available to output templates, in addition to the predefined
+// functions of text/template.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// relpath returns the slash-separated path of target relative to base
+		"relpath": relPath,
+		// repopath returns the path of a finding's file within the repository, given SCM.RootFolder
+		"repopath": repoPath,
+		// bySeverity returns findings ordered from the most to the least severe
+		"bySeverity": bySeverity,
+		// severityRank returns the rank of a severity, 0 being the most severe
+		"severityRank": SeverityRank,
+		// open returns the findings that are not ignored
+		"open":     openFindings,
+		"upper":    strings.ToUpper,
+		"lower":    strings.ToLower,
+		"join":     strings.Join,
+		"csv":      csvField,
+		"markdown": escapeMarkdownCell,
+		"json":     toJSON,
+	}
+}
+
+func relPath(base, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(base), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+func repoPath(rootFolder, filePath string) string {
+	if rootFolder == "" || rootFolder == "." {
+		return filePath
+	}
+	return path.Join(rootFolder, filePath)
+}
+
+func bySeverity(findings []Finding) []Finding {
+	sorted := slices.Clone(findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return SeverityRank(sorted[i].Severity) < SeverityRank(sorted[j].Severity)
+	})
+	return sorted
+}
+
+func openFindings(findings []Finding) []Finding {
+	var open []Finding
+	for i := range findings {
+		if !findings[i].Ignored {
+			open = append(open, findings[i])
+		}
+	}
+	return open
+}
+
+// csvField quotes a value as a CSV field, if needed.
+func csvField(value any) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write([]string{fmt.Sprint(value)}); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), w.Error()
+}
+
+func toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateData() TemplateData {
+	findings := annotationFindings()
+	findings[1].Title = `Generic "API" Key`
+	return TemplateData{
+		Findings:  findings,
+		Summary:   Summarize(findings),
+		SCM:       SCMContext{RepoURL: "https://github.com/acme/api", Branch: "main", Commit: "4f2a9c1", RootFolder: "services/api"},
+		ReportURL: "https://app.snyk.io/org/acme/project/1",
+	}
+}
+
+func renderTemplate(t *testing.T, nameOrPath string, data TemplateData) string {
+	t.Helper()
+
+	tmpl, err := LoadTemplate(nameOrPath)
+	require.NoError(t, err)
+	var b strings.Builder
+	require.NoError(t, RenderTemplate(&b, tmpl, data))
+	return b.String()
+}
+
+func TestRenderTemplate_CSV(t *testing.T) {
+	assert.Equal(t, "severity,rule_id,title,file,line,column,ignored,key\n"+
+		"critical,private-key,Private Key,config/gcp.json,7,20,false,\n"+
+		"high,aws-access-token,AWS Access Token,ignored.env,1,0,true,\n"+
+		`low,generic-api-key,"Generic ""API"" Key","a,b.env",2,1,false,`+"\n",
+		renderTemplate(t, "csv", templateData()))
+}
+
+func TestRenderTemplate_Markdown(t *testing.T) {
+	assert.Equal(t, "## Snyk Secrets\n\n"+
+		"**Repository:** https://github.com/acme/api on `main` at `4f2a9c1`\n\n"+
+		"**2 secrets found**\n\n"+
+		"| Severity | Secret | Rule | Location |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| critical | Private Key | `private-key` | `services/api/config/gcp.json:7` |\n"+
+		"| low | Generic \"API\" Key | `generic-api-key` | `services/api/a,b.env:2` |\n"+
+		"\n[View the results in Snyk](https://app.snyk.io/org/acme/project/1)\n",
+		renderTemplate(t, "markdown", templateData()))
+}
+
+func TestRenderTemplate_MarkdownNoFindings(t *testing.T) {
+	assert.Equal(t, "## Snyk Secrets\n\nNo secrets found.\n", renderTemplate(t, "markdown", TemplateData{}))
+}
+
+func TestRenderTemplate_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slack.tmpl")
+	content := `{{ range open .Findings }}{{ upper .Severity }} {{ .Title }} ({{ severityRank .Severity }}){{ range .Locations }} {{ relpath "config" .FilePath }}{{ end }}
+{{ end }}{{ json .SCM.Branch }}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	assert.Equal(t, "CRITICAL Private Key (0) gcp.json\nLOW Generic \"API\" Key (3) ../a,b.env\n\"main\"",
+		renderTemplate(t, path, templateData()))
+}
+
+func TestLoadTemplate_Errors(t *testing.T) {
+	_, err := LoadTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to read template")
+
+	path := filepath.Join(t.TempDir(), "broken.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{ range .Findings }}"), 0o600))
+	_, err = LoadTemplate(path)
+	assert.ErrorContains(t, err, "failed to parse template")
+
+	require.NoError(t, os.WriteFile(path, []byte("{{ .Unknown }}"), 0o600))
+	tmpl, err := LoadTemplate(path)
+	require.NoError(t, err)
+	assert.ErrorContains(t, RenderTemplate(&strings.Builder{}, tmpl, TemplateData{}), "failed to execute template")
+}
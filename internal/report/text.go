@@ -0,0 +1,174 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ANSI escape codes of the text report.
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiYellow  = "\033[33m"
+	ansiBlue    = "\033[34m"
+	ansiMagenta = "\033[35m"
+)
+
+var severityColors = map[string]string{
+	SeverityCritical: ansiMagenta,
+	SeverityHigh:     ansiRed,
+	SeverityMedium:   ansiYellow,
+	SeverityLow:      ansiBlue,
+}
+
+// TextOptions configures the text report.
+type TextOptions struct {
+	// SourceDir is the directory the paths of findings are relative to. Snippets are only shown if it is set.
+	SourceDir string
+	// ContextLines is the number of lines shown before and after the lines of a secret.
+	ContextLines int
+	// Color renders severities and highlights with ANSI escape codes.
+	Color bool
+	// ReportURL is the project page the results were shared to, if any.
+	ReportURL string
+}
+
+// textEntry is a location of a finding, the unit the text report lists under each file.
+type textEntry struct {
+	finding  *Finding
+	location Location
+}
+
+// RenderText writes the human readable report of findings to w, grouping their locations by file.
+// Ignored findings are only counted.
+func RenderText(w io.Writer, findings []Finding, opts TextOptions) error {
+	r := textRenderer{opts: opts}
+
+	files := map[string][]textEntry{}
+	for i := range findings {
+		if findings[i].Ignored {
+			continue
+		}
+		for _, loc := range findings[i].Locations {
+			files[loc.FilePath] = append(files[loc.FilePath], textEntry{finding: &findings[i], location: loc})
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		entries := files[path]
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].location.FromLine != entries[j].location.FromLine {
+				return entries[i].location.FromLine < entries[j].location.FromLine
+			}
+			return SeverityRank(entries[i].finding.Severity) < SeverityRank(entries[j].finding.Severity)
+		})
+
+		fmt.Fprintf(&b, "\n%s\n", r.style(ansiBold, path))
+		for _, entry := range entries {
+			r.writeEntry(&b, entry)
+		}
+	}
+
+	r.writeSummary(&b, Summarize(findings))
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write text report: %w", err)
+	}
+	return nil
+}
+
+type textRenderer struct {
+	opts TextOptions
+}
+
+func (r textRenderer) style(code, text string) string {
+	if !r.opts.Color || code == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+func (r textRenderer) severity(severity string) string {
+	return r.style(severityColors[severity], fmt.Sprintf("[%s]", strings.ToUpper(severity)))
+}
+
+func (r textRenderer) writeEntry(b *strings.Builder, entry textEntry) {
+	finding := entry.finding
+	fmt.Fprintf(b, " ✗ %s %s", r.severity(finding.Severity), r.style(ansiBold, finding.Title))
+	if finding.RuleID != "" {
+		fmt.Fprintf(b, " %s", r.style(ansiDim, "("+finding.RuleID+")"))
+	}
+	fmt.Fprintf(b, "\n   %s\n", entry.location)
+
+	if r.opts.SourceDir == "" {
+		return
+	}
+	lines, err := Snippet(r.opts.SourceDir, entry.location, r.opts.ContextLines)
+	if err != nil {
+		// the file may have changed or be missing locally, the location is enough to find the secret
+		return
+	}
+
+	width := len(fmt.Sprint(lines[len(lines)-1].Number))
+	for _, line := range lines {
+		marker := " "
+		text := line.Text
+		if line.Secret {
+			marker = ">"
+			text = r.style(ansiBold, text)
+		}
+		fmt.Fprintf(b, "   %s %*d | %s\n", marker, width, line.Number, text)
+	}
+}
+
+func (r textRenderer) writeSummary(b *strings.Builder, summary Summary) {
+	b.WriteString("\n")
+	b.WriteString(r.style(ansiBold, "Summary"))
+	b.WriteString("\n\n")
+
+	if summary.Total == 0 {
+		b.WriteString("  ✔ No secrets found.\n")
+	} else {
+		var severities []string
+		for _, severity := range Severities {
+			if count := summary.BySeverity[severity]; count > 0 {
+				severities = append(severities, r.style(severityColors[severity], fmt.Sprintf("%d %s", count, severity)))
+			}
+		}
+		fmt.Fprintf(b, "  %d %s found: %s\n", summary.Total, plural(summary.Total, "secret", "secrets"), strings.Join(severities, ", "))
+
+		b.WriteString("\n  By rule:\n")
+		for _, rule := range summary.ByRule {
+			name := rule.Title
+			if rule.RuleID != "" {
+				name = fmt.Sprintf("%s (%s)", rule.Title, rule.RuleID)
+			}
+			fmt.Fprintf(b, "    %s: %d\n", name, rule.Count)
+		}
+	}
+
+	if summary.Ignored > 0 {
+		fmt.Fprintf(b, "\n  %d ignored %s not shown.\n", summary.Ignored, plural(summary.Ignored, "finding", "findings"))
+	}
+	if r.opts.ReportURL != "" {
+		fmt.Fprintf(b, "\n  Results shared to %s\n", r.opts.ReportURL)
+	}
+}
+
+func plural(count int, singular, pluralForm string) string {
+	if count == 1 {
+		return singular
+	}
+	return pluralForm
+}
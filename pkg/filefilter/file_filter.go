@@ -3,6 +3,7 @@ package filefilter
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"sync"
 	"time"
@@ -26,6 +27,29 @@ type Analytics interface {
 	RecordFileFilterTimeMs(startTime time.Time)
 }
 
+// KeywordAnalytics is implemented by Analytics recording the files excluded by keyword prefiltering.
+type KeywordAnalytics interface {
+	RecordKeywordFiltered(total int)
+}
+
+// Progress is notified of every file the pipeline checks against its filters.
+// It is called concurrently from the pipeline workers.
+type Progress interface {
+	FileChecked(kept bool)
+}
+
+// Decisions is notified of the decision taken on every file the pipeline checks against its filters.
+// droppedBy is the name of the filter that dropped the file, or empty if the file was kept.
+// It is called concurrently from the pipeline workers.
+type Decisions interface {
+	FileDecided(path, droppedBy string)
+}
+
+// NamedFilter is implemented by filters that name themselves in the decisions of the pipeline.
+type NamedFilter interface {
+	Name() string
+}
+
 // Pipeline holds the configuration for the filtering process.
 type Pipeline struct {
 	logger             *zerolog.Logger
@@ -33,6 +57,8 @@ type Pipeline struct {
 	filters            []FileFilter
 	customGlobPatterns []string
 	analytics          Analytics
+	progress           Progress
+	decisions          Decisions
 }
 
 // Option defines the functional option type.
@@ -83,6 +109,20 @@ func WithAnalytics(analytics Analytics) Option {
 	}
 }
 
+// WithProgress sets the observer notified of every checked file.
+func WithProgress(progress Progress) Option {
+	return func(p *Pipeline) {
+		p.progress = progress
+	}
+}
+
+// WithDecisions sets the observer notified of the decision taken on every checked file.
+func WithDecisions(decisions Decisions) Option {
+	return func(p *Pipeline) {
+		p.decisions = decisions
+	}
+}
+
 // WithExcludeGlobs adds user-defined patterns to the pipeline's exclude list.
 func WithExcludeGlobs(userPatterns []string) Option {
 	return func(p *Pipeline) {
@@ -114,15 +154,24 @@ func (p *Pipeline) Filter(ctx context.Context, inputPaths []string) chan string
 			// Iterate over incoming paths
 			for path := range files {
 				keep := true
+				droppedBy := ""
 
 				// Apply all configured filters
 				for _, filter := range p.filters {
 					if filter.FilterOut(path) {
 						keep = false
+						droppedBy = filterName(filter)
 						break
 					}
 				}
 
+				if p.progress != nil {
+					p.progress.FileChecked(keep)
+				}
+				if p.decisions != nil {
+					p.decisions.FileDecided(path, droppedBy)
+				}
+
 				if keep {
 					select {
 					case filteredFiles <- path:
@@ -148,3 +197,11 @@ func (p *Pipeline) Filter(ctx context.Context, inputPaths []string) chan string
 	}()
 	return filteredFiles
 }
+
+// filterName returns the name of filter, or its type if it does not name itself.
+func filterName(filter FileFilter) string {
+	if named, ok := filter.(NamedFilter); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("%T", filter)
+}
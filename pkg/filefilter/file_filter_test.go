@@ -8,6 +8,8 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -29,20 +31,38 @@ func (m *mockFilter) FilterOut(path string) bool {
 
 func (m *mockFilter) RecordMetrics(_ Analytics) {}
 
-// mockAnalytics implements Analytics for testing purposes.
+// mockAnalytics implements Analytics and KeywordAnalytics for testing purposes.
 type mockAnalytics struct {
-	sizeFilteredCount int
-	filterTimeCalled  bool
+	sizeFilteredCount    int
+	keywordFilteredCount int
+	filterTimeCalled     bool
 }
 
 func (m *mockAnalytics) RecordSizeFiltered(total int) {
 	m.sizeFilteredCount += total
 }
 
+func (m *mockAnalytics) RecordKeywordFiltered(total int) {
+	m.keywordFilteredCount += total
+}
+
 func (m *mockAnalytics) RecordFileFilterTimeMs(_ time.Time) {
 	m.filterTimeCalled = true
 }
 
+// mockProgress implements Progress for testing purposes.
+type mockProgress struct {
+	checked atomic.Int64
+	kept    atomic.Int64
+}
+
+func (m *mockProgress) FileChecked(kept bool) {
+	m.checked.Add(1)
+	if kept {
+		m.kept.Add(1)
+	}
+}
+
 // chanToSlice collects all items from a channel into a slice.
 func chanToSlice(ch chan string) []string {
 	var results []string
@@ -295,6 +315,69 @@ func TestFilter_ConcurrencyStress(t *testing.T) {
 	}
 }
 
+func TestFilter_Progress(t *testing.T) {
+	inputFiles := map[string]string{}
+	for i := 0; i < 100; i++ {
+		inputFiles[fmt.Sprintf("file-%d", i)] = "test content"
+	}
+	dirPath := setupTempDir(t, inputFiles)
+
+	// A filter that drops every file with an even name.
+	evenFilter := &mockFilter{
+		fn: func(path string) bool {
+			var n int
+			_, _ = fmt.Sscanf(filepath.Base(path), "file-%d", &n)
+			return n%2 == 0
+		},
+	}
+	progress := &mockProgress{}
+	logger := newTestLogger()
+
+	pipeline := NewPipeline(
+		WithConcurrency(4),
+		WithFilters(evenFilter),
+		WithLogger(&logger),
+		WithProgress(progress),
+	)
+	results := chanToSlice(pipeline.Filter(t.Context(), []string{dirPath}))
+
+	assert.Len(t, results, 50)
+	assert.Equal(t, int64(100), progress.checked.Load())
+	assert.Equal(t, int64(50), progress.kept.Load())
+}
+
+// mockDecisions implements Decisions for testing purposes.
+type mockDecisions struct {
+	mu        sync.Mutex
+	droppedBy map[string]string
+}
+
+func (m *mockDecisions) FileDecided(path, droppedBy string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.droppedBy[filepath.Base(path)] = droppedBy
+}
+
+func TestFilter_Decisions(t *testing.T) {
+	logger := newTestLogger()
+	dirPath := setupTempDir(t, map[string]string{
+		"empty.txt": "",
+		"binary":    "bin\x00ary",
+		"kept.txt":  "test content",
+	})
+
+	decisions := &mockDecisions{droppedBy: map[string]string{}}
+	pipeline := NewPipeline(
+		WithFilters(FileSizeFilter(&logger), TextFileOnlyFilter(&logger)),
+		WithLogger(&logger),
+		WithDecisions(decisions),
+	)
+	results := chanToSlice(pipeline.Filter(t.Context(), []string{dirPath}))
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, map[string]string{"empty.txt": "file-size", "binary": "text-file-only", "kept.txt": ""}, decisions.droppedBy)
+}
+
 func newTestLogger() zerolog.Logger {
 	return zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.Kitchen}).
 		Level(zerolog.DebugLevel).
@@ -42,6 +42,10 @@ func (f *fileSizeFilter) FilterOut(path string) bool {
 	return false
 }
 
+func (f *fileSizeFilter) Name() string {
+	return "file-size"
+}
+
 func (f *fileSizeFilter) RecordMetrics(analytics Analytics) {
 	if analytics == nil {
 		return
@@ -0,0 +1,287 @@
+package filefilter
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// _KeywordChunkSize is the number of bytes read from a file at a time while matching keywords.
+	_KeywordChunkSize = 32 * 1024
+	// _MinEntropyTokenLength is the minimum length of a token to be checked for high entropy.
+	_MinEntropyTokenLength = 20
+	// _MaxEntropyTokenLength is the number of characters of a token its entropy is measured on.
+	_MaxEntropyTokenLength = 256
+	// _MinTokenEntropy is the Shannon entropy, in bits per character, from which a token is a candidate secret.
+	// Random base64 tokens of 20 characters or more score above 4, identifiers and words below.
+	_MinTokenEntropy = 3.5
+)
+
+// secretKeywords are the keywords, matched case-insensitively, of which a file must contain at least one,
+// or a high-entropy token, to be able to contain a secret.
+var secretKeywords = []string{
+	// generic assignments
+	"password", "passwd", "pwd", "secret", "token", "credential", "auth", "bearer",
+	"api_key", "apikey", "api-key", "access_key", "accesskey", "private_key", "client_id",
+	// short assignment stems, such as DB_PASS= or STRIPE_KEY:, which alone would match words like passing or monkey
+	"_pass", "-pass", ".pass", "pass=", "pass:", "pass =", "_key", "-key", ".key", "key=", "key:", "key =",
+	// private keys and certificates
+	"begin private key", "-----begin",
+	// connection strings
+	"mongodb+srv://", "mongodb://", "postgres://", "postgresql://", "mysql://", "redis://", "amqp://", "jdbc:",
+	// provider prefixes
+	"akia", "asia", "aiza", "ya29.", "ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_", "glpat-",
+	"xoxa-", "xoxb-", "xoxp-", "xoxr-", "xoxs-", "xapp-", "hooks.slack.com", "sk_live_", "rk_live_", "sk_test_",
+	"sk-", "npm_", "pypi-", "shpat_", "shpss_", "dop_v1_", "sg.", "eyj", "hf_", "lin_api_", "dapi",
+}
+
+type keywordFilter struct {
+	logger        *zerolog.Logger
+	matcher       *keywordMatcher
+	buffers       sync.Pool
+	filteredFiles atomic.Int64
+}
+
+// KeywordFilter returns a filter that drops files containing none of the keywords secrets are found next to,
+// such as provider prefixes, password or BEGIN PRIVATE KEY, and no high-entropy token.
+// It streams the content of files, so that it only reads a file up to the first candidate.
+//
+//nolint:ireturn // Returns interface because implementation is private
+func KeywordFilter(logger *zerolog.Logger) FileFilter {
+	return newKeywordFilter(logger, secretKeywords)
+}
+
+func newKeywordFilter(logger *zerolog.Logger, keywords []string) *keywordFilter {
+	return &keywordFilter{
+		logger:  logger,
+		matcher: newKeywordMatcher(keywords),
+		buffers: sync.Pool{New: func() any {
+			buf := make([]byte, _KeywordChunkSize)
+			return &buf
+		}},
+	}
+}
+
+func (f *keywordFilter) FilterOut(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		// this filter only narrows the upload down, a file that cannot be read is left to the other filters
+		f.logger.Error().Msgf("failed to open file for keyword matching: %v", err)
+		return false
+	}
+	defer file.Close()
+
+	found, err := f.containsCandidate(file)
+	if err != nil {
+		f.logger.Error().Msgf("failed to read file for keyword matching: %v", err)
+		return false
+	}
+	if !found {
+		f.filteredFiles.Add(1)
+	}
+	return !found
+}
+
+// containsCandidate reports whether r contains a keyword or a high-entropy token.
+func (f *keywordFilter) containsCandidate(r io.Reader) (bool, error) {
+	buf, ok := f.buffers.Get().(*[]byte)
+	if !ok {
+		b := make([]byte, _KeywordChunkSize)
+		buf = &b
+	}
+	defer f.buffers.Put(buf)
+
+	scan := f.matcher.newScan()
+	for {
+		n, err := r.Read(*buf)
+		if scan.write((*buf)[:n]) {
+			return true, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return scan.endToken(), nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// CandidateMatcher reports whether content contains a keyword secrets are found next to or a high-entropy token,
+// like KeywordFilter does for whole files. It is safe for concurrent use.
+type CandidateMatcher struct {
+	matcher *keywordMatcher
+}
+
+// NewCandidateMatcher returns a CandidateMatcher matching the keywords of KeywordFilter.
+func NewCandidateMatcher() *CandidateMatcher {
+	return &CandidateMatcher{matcher: newKeywordMatcher(secretKeywords)}
+}
+
+// Match reports whether content, such as a single line of a file, contains a candidate secret.
+func (m *CandidateMatcher) Match(content []byte) bool {
+	scan := m.matcher.newScan()
+	return scan.write(content) || scan.endToken()
+}
+
+func (f *keywordFilter) Name() string {
+	return "keyword"
+}
+
+func (f *keywordFilter) RecordMetrics(analytics Analytics) {
+	keywordAnalytics, ok := analytics.(KeywordAnalytics)
+	if !ok {
+		return
+	}
+	keywordAnalytics.RecordKeywordFiltered(int(f.filteredFiles.Load()))
+}
+
+// keywordMatcher is an Aho-Corasick automaton matching keywords case-insensitively.
+// It is immutable once built, so that any number of scans can use it concurrently.
+type keywordMatcher struct {
+	// next is the state reached from a state on a lowercase byte, a row of 256 states per state.
+	next []int32
+	// output marks the states at which a keyword ends.
+	output []bool
+}
+
+func newKeywordMatcher(keywords []string) *keywordMatcher {
+	// build the trie of the keywords, state 0 being the root
+	next := make([]int32, 256)
+	output := []bool{false}
+	for _, keyword := range keywords {
+		state := int32(0)
+		for i := 0; i < len(keyword); i++ {
+			c := toLower(keyword[i])
+			if next[int(state)*256+int(c)] == 0 {
+				next = append(next, make([]int32, 256)...)
+				output = append(output, false)
+				next[int(state)*256+int(c)] = int32(len(output) - 1)
+			}
+			state = next[int(state)*256+int(c)]
+		}
+		output[state] = true
+	}
+
+	// turn the trie into a DFA, resolving the transitions missing from a state through its failure link, breadth-first
+	fail := make([]int32, len(output))
+	var queue []int32
+	for c := 0; c < 256; c++ {
+		if child := next[c]; child != 0 {
+			queue = append(queue, child)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		output[state] = output[state] || output[fail[state]]
+		for c := 0; c < 256; c++ {
+			child := next[int(state)*256+c]
+			if child == 0 {
+				next[int(state)*256+c] = next[int(fail[state])*256+c]
+				continue
+			}
+			fail[child] = next[int(fail[state])*256+c]
+			queue = append(queue, child)
+		}
+	}
+
+	return &keywordMatcher{next: next, output: output}
+}
+
+// keywordScan is the state of matching the content of one file, written in chunks.
+type keywordScan struct {
+	matcher *keywordMatcher
+	state   int32
+
+	// the start of the token of base64 or hex characters currently read, to measure its entropy
+	token       []byte
+	tokenLength int
+}
+
+func (m *keywordMatcher) newScan() *keywordScan {
+	return &keywordScan{matcher: m, token: make([]byte, 0, _MaxEntropyTokenLength)}
+}
+
+// write matches the next chunk of content and reports whether a keyword or high-entropy token was found.
+func (s *keywordScan) write(chunk []byte) bool {
+	next, output := s.matcher.next, s.matcher.output
+	state := s.state
+	for _, b := range chunk {
+		state = next[int(state)*256+int(toLower(b))]
+		if output[state] {
+			return true
+		}
+
+		if isTokenByte(b) {
+			if len(s.token) < cap(s.token) {
+				s.token = append(s.token, b)
+			}
+			s.tokenLength++
+			continue
+		}
+		if s.endToken() {
+			return true
+		}
+	}
+	s.state = state
+	return false
+}
+
+// endToken ends the token currently read and reports whether it looks like a secret: long enough,
+// mixing letters and digits, and with a high entropy.
+func (s *keywordScan) endToken() bool {
+	token := s.token
+	long := s.tokenLength >= _MinEntropyTokenLength
+	s.token = s.token[:0]
+	s.tokenLength = 0
+	return long && hasLetterAndDigit(token) && entropy(token) >= _MinTokenEntropy
+}
+
+// entropy returns the Shannon entropy of token, in bits per character.
+func entropy(token []byte) float64 {
+	var counts [256]int
+	for _, b := range token {
+		counts[b]++
+	}
+
+	length := float64(len(token))
+	e := 0.0
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / length
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+func hasLetterAndDigit(token []byte) bool {
+	letter, digit := false, false
+	for _, b := range token {
+		switch {
+		case b >= '0' && b <= '9':
+			digit = true
+		case b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z':
+			letter = true
+		}
+	}
+	return letter && digit
+}
+
+func isTokenByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '+' || b == '/' || b == '=' || b == '_' || b == '-'
+}
+
+func toLower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
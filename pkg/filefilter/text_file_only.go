@@ -52,6 +52,10 @@ func (f *textFileOnly) FilterOut(path string) bool {
 	return !IsTextContent(header)
 }
 
+func (f *textFileOnly) Name() string {
+	return "text-file-only"
+}
+
 // RecordMetrics No metrics to record for text file.
 func (f *textFileOnly) RecordMetrics(_ Analytics) {}
 
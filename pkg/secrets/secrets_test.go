@@ -23,6 +23,10 @@ func TestInit(t *testing.T) {
 	assert.NoError(t, err)
 
 	assertWorkflowExists(t, e, secretstest.WorkflowID)
+	assertWorkflowExists(t, e, secretstest.ResultWorkflowID)
+	assertWorkflowExists(t, e, secretstest.UploadBundleWorkflowID)
+	assertWorkflowExists(t, e, secretstest.CompareWorkflowID)
+	assertWorkflowExists(t, e, secretstest.TrendsWorkflowID)
 }
 
 func assertWorkflowExists(t *testing.T, e workflow.Engine, id *url.URL) {